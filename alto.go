@@ -0,0 +1,161 @@
+package ocr
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+const (
+	altoNamespace      = "http://www.loc.gov/standards/alto/ns-v4#"
+	altoSchemaLocation = altoNamespace + " http://www.loc.gov/alto/v4/alto-4-4.xsd"
+)
+
+type altoDocument struct {
+	XMLName        xml.Name `xml:"alto"`
+	Xmlns          string   `xml:"xmlns,attr"`
+	XSI            string   `xml:"xmlns:xsi,attr"`
+	SchemaLocation string   `xml:"xsi:schemaLocation,attr"`
+	Description    struct {
+		MeasurementUnit string `xml:"MeasurementUnit"`
+	} `xml:"Description"`
+	Layout struct {
+		Pages []altoPage `xml:"Page"`
+	} `xml:"Layout"`
+}
+
+type altoPage struct {
+	ID             string         `xml:"ID,attr"`
+	PhysicalImgNr  int            `xml:"PHYSICAL_IMG_NR,attr"`
+	Width          int            `xml:"WIDTH,attr"`
+	Height         int            `xml:"HEIGHT,attr"`
+	PageConfidence string         `xml:"PC,attr,omitempty"`
+	PrintSpace     altoPrintSpace `xml:"PrintSpace"`
+}
+
+type altoPrintSpace struct {
+	altoBox
+	Blocks []altoBlock
+}
+
+// altoBox holds the position attributes shared by ALTO elements
+type altoBox struct {
+	HPos   int `xml:"HPOS,attr"`
+	VPos   int `xml:"VPOS,attr"`
+	Width  int `xml:"WIDTH,attr"`
+	Height int `xml:"HEIGHT,attr"`
+}
+
+// altoBlock is a TextBlock or an Illustration
+type altoBlock struct {
+	XMLName xml.Name
+	ID      string `xml:"ID,attr"`
+	altoBox
+	Lines []altoLine `xml:"TextLine"`
+}
+
+type altoLine struct {
+	ID string `xml:"ID,attr"`
+	altoBox
+	// Inline holds altoString elements separated by altoSpace elements
+	Inline []any
+}
+
+type altoString struct {
+	XMLName xml.Name `xml:"String"`
+	ID      string   `xml:"ID,attr"`
+	Content string   `xml:"CONTENT,attr"`
+	altoBox
+	WordConfidence string `xml:"WC,attr,omitempty"`
+}
+
+type altoSpace struct {
+	XMLName xml.Name `xml:"SP"`
+}
+
+// WriteALTO writes result as an ALTO v4 XML document measured in pixels. Like
+// WriteHOCR it needs page dimensions and bounding boxes; blocks become
+// TextBlock elements, figures without text Illustration elements, and lines
+// and words are located by dividing their block evenly. The page confidence is
+// reported as the PC of the page and the WC of its words.
+func WriteALTO(w io.Writer, result *OCRResult) error {
+	if err := checkLayout(result); err != nil {
+		return err
+	}
+
+	doc := altoDocument{
+		Xmlns:          altoNamespace,
+		XSI:            "http://www.w3.org/2001/XMLSchema-instance",
+		SchemaLocation: altoSchemaLocation,
+	}
+	doc.Description.MeasurementUnit = "pixel"
+	for i, page := range result.Pages {
+		doc.Layout.Pages = append(doc.Layout.Pages, altoPageOf(i, page))
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func altoPageOf(index int, page PageResult) altoPage {
+	n := page.PageNumber
+	p := altoPage{
+		ID:            fmt.Sprintf("page_%d", n),
+		PhysicalImgNr: index + 1,
+		Width:         page.Width,
+		Height:        page.Height,
+		PrintSpace:    altoPrintSpace{altoBox: altoBox{Width: page.Width, Height: page.Height}},
+	}
+	confidence := ""
+	if page.Confidence != nil {
+		confidence = strconv.FormatFloat(math.Round(*page.Confidence*100)/100, 'f', -1, 64)
+		p.PageConfidence = confidence
+	}
+
+	for b, block := range page.Blocks {
+		id := fmt.Sprintf("%d_%d", n, b+1)
+		lines := blockLines(block)
+		if len(lines) == 0 {
+			if block.Type == "figure" {
+				p.PrintSpace.Blocks = append(p.PrintSpace.Blocks, altoBlock{
+					XMLName: xml.Name{Local: "Illustration"}, ID: "block_" + id, altoBox: altoBoxOf(block.Bounds),
+				})
+			}
+			continue
+		}
+
+		textBlock := altoBlock{XMLName: xml.Name{Local: "TextBlock"}, ID: "block_" + id, altoBox: altoBoxOf(block.Bounds)}
+		for l, line := range lines {
+			lineID := fmt.Sprintf("%s_%d", id, l+1)
+			textLine := altoLine{ID: "line_" + lineID, altoBox: altoBoxOf(line.Bounds)}
+			for wi, word := range line.Words {
+				if wi > 0 {
+					textLine.Inline = append(textLine.Inline, altoSpace{})
+				}
+				textLine.Inline = append(textLine.Inline, altoString{
+					ID:             fmt.Sprintf("string_%s_%d", lineID, wi+1),
+					Content:        word.Text,
+					altoBox:        altoBoxOf(word.Bounds),
+					WordConfidence: confidence,
+				})
+			}
+			textBlock.Lines = append(textBlock.Lines, textLine)
+		}
+		p.PrintSpace.Blocks = append(p.PrintSpace.Blocks, textBlock)
+	}
+	return p
+}
+
+func altoBoxOf(b BoundingBox) altoBox {
+	return altoBox{HPos: b.X, VPos: b.Y, Width: b.Width, Height: b.Height}
+}
@@ -0,0 +1,484 @@
+package ocr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// BatchItem is a single document of a batch.
+// Exactly one of Path, Reader or URL must be set; other items fail with a
+// validation error without being submitted.
+type BatchItem struct {
+	// Key identifies the item in results; defaults to the path, URL or filename
+	Key string
+	// Path is a local file to upload
+	Path string
+	// FS, when set, is used to open Path instead of the local file system
+	FS fs.FS
+	// Reader provides the file content to upload; Filename is required with it
+	Reader   io.Reader
+	Filename string
+	// URL is a remote file processed with ProcessURL
+	URL string
+	// Options are applied after BatchOptions.Options
+	Options []ProcessingOption
+}
+
+// FileItem returns a batch item for a local file
+func FileItem(path string, opts ...ProcessingOption) BatchItem {
+	return BatchItem{Path: path, Options: opts}
+}
+
+// ReaderItem returns a batch item reading the file content from r
+func ReaderItem(filename string, r io.Reader, opts ...ProcessingOption) BatchItem {
+	return BatchItem{Reader: r, Filename: filename, Options: opts}
+}
+
+// URLItem returns a batch item for a remote file
+func URLItem(fileURL string, opts ...ProcessingOption) BatchItem {
+	return BatchItem{URL: fileURL, Options: opts}
+}
+
+// key returns the item's identifier
+func (item BatchItem) key() string {
+	switch {
+	case item.Key != "":
+		return item.Key
+	case item.Path != "":
+		return item.Path
+	case item.URL != "":
+		return item.URL
+	default:
+		return item.Filename
+	}
+}
+
+// validate checks that the item has exactly one source
+func (item BatchItem) validate() error {
+	sources := 0
+	for _, set := range []bool{item.Path != "", item.Reader != nil, item.URL != ""} {
+		if set {
+			sources++
+		}
+	}
+	switch {
+	case sources == 0:
+		return NewSDKError(ErrorTypeValidationError, "batch item must have a path, reader or URL", nil)
+	case sources > 1:
+		return NewSDKError(ErrorTypeValidationError, "batch item must have only one of a path, reader or URL", nil)
+	case item.Reader != nil && item.Filename == "":
+		return NewSDKError(ErrorTypeValidationError, "batch item with a reader requires a filename", nil)
+	}
+	return nil
+}
+
+// BatchOptions configures a batch run
+type BatchOptions struct {
+	// SubmitConcurrency is the number of concurrent uploads and submissions (default: 4)
+	SubmitConcurrency int
+	// WaitConcurrency is the number of jobs waited on concurrently (default: 16)
+	WaitConcurrency int
+	// Options are applied to every item before the item's own options
+	Options []ProcessingOption
+	// WaitOptions configures polling when Waiter is nil
+	WaitOptions WaitOptions
+	// Waiter waits for submitted jobs (default: polling with WaitOptions)
+	Waiter JobWaiter
+	// Journal records item progress so that a restarted batch resumes where it
+	// stopped. Item keys must be unique and stable across runs when it is set.
+	Journal BatchJournal
+	// RetryFailed resubmits items the journal recorded as failed instead of skipping them
+	RetryFailed bool
+}
+
+// BatchResult is the outcome of a single batch item
+type BatchResult struct {
+	// Index is the position of the item in the submitted slice
+	Index    int
+	Key      string
+	Item     BatchItem
+	JobID    string
+	Result   *OCRResult
+	Err      error
+	Duration time.Duration
+	// Skipped is set for items finished in a previous run according to the journal.
+	// The result of a completed item is fetched again by its job ID; Err is set
+	// when it can no longer be fetched.
+	Skipped bool
+}
+
+// BatchSummary aggregates the outcome of a batch run
+type BatchSummary struct {
+	Total     int
+	Succeeded int
+	Failed    int
+	// Skipped counts items finished in a previous run; they are not included in Succeeded or Failed
+	Skipped  int
+	Credits  int
+	Duration time.Duration
+}
+
+// Batch is a running batch started with SDK.Batch
+type Batch struct {
+	results chan BatchResult
+	done    chan struct{}
+
+	mu         sync.Mutex
+	summary    BatchSummary
+	journalErr error
+}
+
+// Results streams results as items finish. The channel is closed once every
+// item has produced exactly one result.
+func (b *Batch) Results() <-chan BatchResult {
+	return b.results
+}
+
+// Wait blocks until every item has finished and returns the summary.
+// Results not yet received remain available on Results.
+func (b *Batch) Wait() BatchSummary {
+	<-b.done
+	return b.Summary()
+}
+
+// Summary returns the summary of the items finished so far
+func (b *Batch) Summary() BatchSummary {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.summary
+}
+
+// Err returns the first error encountered while writing to the journal, if any.
+// Items keep being processed after a journal error, but a restarted batch may
+// then submit some of them again.
+func (b *Batch) Err() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.journalErr
+}
+
+// submittedJob is an item whose job has been created and is awaiting completion
+type submittedJob struct {
+	index   int
+	jobID   string
+	started time.Time
+	// cacheKey is set when the result should be stored in the ResultCache
+	cacheKey string
+	// resumed is set for jobs completed in a previous run, whose result is only fetched
+	resumed bool
+}
+
+// Batch processes items concurrently: files are uploaded and jobs submitted by
+// SubmitConcurrency workers, and submitted jobs are awaited by WaitConcurrency
+// workers. Results are streamed on Batch.Results as they finish.
+func (s *SDK) Batch(ctx context.Context, items []BatchItem, opts BatchOptions) *Batch {
+	opts = applyBatchDefaults(opts)
+
+	b := &Batch{
+		results: make(chan BatchResult, len(items)),
+		done:    make(chan struct{}),
+		summary: BatchSummary{Total: len(items)},
+	}
+	run := &batchRun{sdk: s, batch: b, items: items, opts: opts, started: time.Now()}
+
+	go run.execute(ctx)
+
+	return b
+}
+
+// batchRun holds the state of a single Batch call
+type batchRun struct {
+	sdk     *SDK
+	batch   *Batch
+	items   []BatchItem
+	opts    BatchOptions
+	started time.Time
+}
+
+func (r *batchRun) execute(ctx context.Context) {
+	indexes := make(chan int)
+	submitted := make(chan submittedJob)
+
+	var submitters sync.WaitGroup
+	for range r.opts.SubmitConcurrency {
+		submitters.Add(1)
+		go func() {
+			defer submitters.Done()
+			for index := range indexes {
+				r.submit(ctx, index, submitted)
+			}
+		}()
+	}
+
+	var waiters sync.WaitGroup
+	for range r.opts.WaitConcurrency {
+		waiters.Add(1)
+		go func() {
+			defer waiters.Done()
+			for job := range submitted {
+				r.wait(ctx, job)
+			}
+		}()
+	}
+
+	entries, err := r.loadJournal(ctx)
+	keys := make(map[string]bool, len(r.items))
+	for index := range r.items {
+		if err != nil {
+			r.finish(index, "", nil, err, time.Now())
+			continue
+		}
+
+		if err := r.items[index].validate(); err != nil {
+			r.finish(index, "", nil, err, time.Now())
+			continue
+		}
+		// Journal entries are found by key, so items sharing one would skip each other
+		key := r.items[index].key()
+		if r.opts.Journal != nil && keys[key] {
+			r.finish(index, "", nil, NewSDKError(ErrorTypeValidationError, fmt.Sprintf("duplicate batch item key %q", key), nil), time.Now())
+			continue
+		}
+		keys[key] = true
+
+		entry, ok := entries[key]
+		switch {
+		case !ok:
+			indexes <- index
+		case entry.State == JournalCompleted && entry.JobID != "":
+			submitted <- submittedJob{index: index, jobID: entry.JobID, started: time.Now(), resumed: true}
+		case entry.State == JournalCompleted:
+			r.skip(index, entry.JobID, nil, nil)
+		case entry.State == JournalFailed && !r.opts.RetryFailed:
+			r.skip(index, entry.JobID, nil, NewSDKError(ErrorTypeJobError, "failed in a previous run: "+entry.Error, nil))
+		case entry.State == JournalUploaded && entry.JobID != "":
+			// Re-attach to the job submitted by the previous run instead of paying for it twice
+			submitted <- submittedJob{index: index, jobID: entry.JobID, started: time.Now()}
+		default:
+			indexes <- index
+		}
+	}
+	close(indexes)
+
+	submitters.Wait()
+	close(submitted)
+	waiters.Wait()
+
+	r.batch.mu.Lock()
+	r.batch.summary.Duration = time.Since(r.started)
+	r.batch.mu.Unlock()
+
+	close(r.batch.results)
+	close(r.batch.done)
+}
+
+// submit starts processing of a single item and hands the job to the waiters
+func (r *batchRun) submit(ctx context.Context, index int, submitted chan<- submittedJob) {
+	started := time.Now()
+
+	if err := ctx.Err(); err != nil {
+		r.finish(index, "", nil, NewSDKError(ErrorTypeTimeout, "batch canceled before item was submitted", err), started)
+		return
+	}
+
+	key := r.items[index].key()
+	if err := r.record(ctx, JournalEntry{Key: key, State: JournalQueued}); err != nil {
+		r.finish(index, "", nil, err, started)
+		return
+	}
+
+	job, cached, cacheKey, err := r.sdk.processBatchItem(ctx, r.items[index], r.opts.Options)
+	if err != nil {
+		// Canceled submissions stay queued so that they are retried on restart
+		if ctx.Err() == nil {
+			r.recordBestEffort(ctx, JournalEntry{Key: key, State: JournalFailed, Error: err.Error()})
+		}
+		r.finish(index, "", nil, err, started)
+		return
+	}
+
+	if cached != nil {
+		r.recordBestEffort(ctx, JournalEntry{Key: key, State: JournalCompleted, JobID: cached.JobID})
+		r.finish(index, cached.JobID, cached, nil, started)
+		return
+	}
+
+	r.recordBestEffort(ctx, JournalEntry{Key: key, State: JournalUploaded, JobID: job.ID})
+
+	submitted <- submittedJob{index: index, jobID: job.ID, started: started, cacheKey: cacheKey}
+}
+
+// wait waits for a submitted job and records its result
+func (r *batchRun) wait(ctx context.Context, job submittedJob) {
+	if job.resumed {
+		result, err := r.sdk.GetJobResult(ctx, job.jobID)
+		r.skip(job.index, job.jobID, result, err)
+		return
+	}
+
+	var result *OCRResult
+	var err error
+	if r.opts.Waiter != nil {
+		result, err = r.opts.Waiter.WaitUntilDone(ctx, job.jobID)
+	} else {
+		result, err = r.sdk.WaitUntilDoneWithOptions(ctx, job.jobID, r.opts.WaitOptions)
+	}
+
+	key := r.items[job.index].key()
+	var sdkErr *SDKError
+	switch {
+	case err == nil:
+		credits := 0
+		if result != nil {
+			credits = result.Credits
+		}
+		r.recordBestEffort(ctx, JournalEntry{Key: key, State: JournalCompleted, JobID: job.jobID, Credits: credits})
+		r.sdk.storeResult(ctx, job.cacheKey, result)
+	case errors.As(err, &sdkErr) && sdkErr.Type == ErrorTypeJobError:
+		r.recordBestEffort(ctx, JournalEntry{Key: key, State: JournalFailed, JobID: job.jobID, Error: err.Error()})
+	default:
+		// The job may still finish: leave it uploaded so a restart re-attaches to it
+	}
+
+	r.finish(job.index, job.jobID, result, err, job.started)
+}
+
+// loadJournal returns the entries of the configured journal, if any
+func (r *batchRun) loadJournal(ctx context.Context) (map[string]JournalEntry, error) {
+	if r.opts.Journal == nil {
+		return nil, nil
+	}
+	entries, err := r.opts.Journal.Load(ctx)
+	if err != nil {
+		return nil, NewSDKError(ErrorTypeUnknown, "failed to load batch journal", err)
+	}
+	return entries, nil
+}
+
+// record writes an entry to the configured journal, if any
+func (r *batchRun) record(ctx context.Context, entry JournalEntry) error {
+	if r.opts.Journal == nil {
+		return nil
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+	// Journal writes must land even when the batch context is being canceled
+	if err := r.opts.Journal.Record(context.WithoutCancel(ctx), entry); err != nil {
+		return NewSDKError(ErrorTypeUnknown, "failed to record batch journal entry", err)
+	}
+	return nil
+}
+
+// recordBestEffort writes an entry and remembers the first failure in Batch.Err
+// instead of failing the item, whose job already exists on the server
+func (r *batchRun) recordBestEffort(ctx context.Context, entry JournalEntry) {
+	if err := r.record(ctx, entry); err != nil {
+		r.batch.mu.Lock()
+		if r.batch.journalErr == nil {
+			r.batch.journalErr = err
+		}
+		r.batch.mu.Unlock()
+	}
+}
+
+// skip publishes the result of an item finished in a previous run
+func (r *batchRun) skip(index int, jobID string, result *OCRResult, err error) {
+	item := r.items[index]
+
+	r.batch.mu.Lock()
+	r.batch.summary.Skipped++
+	r.batch.mu.Unlock()
+
+	r.batch.results <- BatchResult{
+		Index:   index,
+		Key:     item.key(),
+		Item:    item,
+		JobID:   jobID,
+		Result:  result,
+		Err:     err,
+		Skipped: true,
+	}
+}
+
+// finish records the outcome of an item and publishes its result
+func (r *batchRun) finish(index int, jobID string, result *OCRResult, err error, started time.Time) {
+	item := r.items[index]
+
+	r.batch.mu.Lock()
+	if err != nil {
+		r.batch.summary.Failed++
+	} else {
+		r.batch.summary.Succeeded++
+		// Cached results were paid for by an earlier job
+		if result != nil && !result.Cached {
+			r.batch.summary.Credits += result.Credits
+		}
+	}
+	r.batch.mu.Unlock()
+
+	r.batch.results <- BatchResult{
+		Index:    index,
+		Key:      item.key(),
+		Item:     item,
+		JobID:    jobID,
+		Result:   result,
+		Err:      err,
+		Duration: time.Since(started),
+	}
+}
+
+// processBatchItem submits a single batch item with the shared options applied
+// first. File items found in the ResultCache return the cached result instead.
+func (s *SDK) processBatchItem(ctx context.Context, item BatchItem, shared []ProcessingOption) (*Job, *OCRResult, string, error) {
+	opts := make([]ProcessingOption, 0, len(shared)+len(item.Options))
+	opts = append(opts, shared...)
+	opts = append(opts, item.Options...)
+
+	switch {
+	case item.URL != "":
+		job, err := s.ProcessURL(ctx, item.URL, opts...)
+		return job, nil, "", err
+	case item.Reader != nil:
+		return s.processFileCached(ctx, item.Reader, item.Filename, opts)
+	case item.Path != "":
+		file, err := openBatchItem(item)
+		if err != nil {
+			return nil, nil, "", NewSDKError(ErrorTypeValidationError, "failed to open file", err)
+		}
+		defer func() { _ = file.Close() }() //nolint:errcheck
+
+		filename := item.Filename
+		if filename == "" {
+			filename = filepath.Base(item.Path)
+		}
+		return s.processFileCached(ctx, file, filename, opts)
+	default:
+		return nil, nil, "", item.validate()
+	}
+}
+
+// openBatchItem opens the item's path in its FS or on the local file system
+func openBatchItem(item BatchItem) (io.ReadCloser, error) {
+	if item.FS != nil {
+		return item.FS.Open(item.Path)
+	}
+	return os.Open(item.Path) // #nosec G304 - path is provided by the caller
+}
+
+func applyBatchDefaults(opts BatchOptions) BatchOptions {
+	if opts.SubmitConcurrency <= 0 {
+		opts.SubmitConcurrency = 4
+	}
+	if opts.WaitConcurrency <= 0 {
+		opts.WaitConcurrency = 16
+	}
+	return opts
+}
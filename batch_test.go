@@ -0,0 +1,188 @@
+package ocr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBatch(t *testing.T) {
+	var submitted atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /ocr/uploads/url", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		url, _ := body["url"].(string)
+		if strings.Contains(url, "broken") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		n := submitted.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"job_id":"job_%d"}`, n)
+	})
+	mux.HandleFunc("GET /ocr/status/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"job_id":%q,"status":"completed"}`, r.PathValue("id"))
+	})
+	mux.HandleFunc("GET /ocr/result/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"job_id":%q,"status":"completed","credits_used":2}`, r.PathValue("id"))
+	})
+
+	sdk := newTestTeamSDK(t, mux)
+	items := []BatchItem{
+		URLItem("https://example.com/a.pdf"),
+		URLItem("https://example.com/b.pdf"),
+		URLItem("https://example.com/broken.pdf"),
+		{Key: "empty"},
+		{Key: "ambiguous", Path: "a.pdf", URL: "https://example.com/a.pdf"},
+		{Key: "unnamed", Reader: strings.NewReader("%PDF")},
+	}
+
+	batch := sdk.Batch(context.Background(), items, BatchOptions{
+		SubmitConcurrency: 2,
+		Options:           []ProcessingOption{WithFormat(FormatMarkdown)},
+		WaitOptions:       WaitOptions{InitialDelay: time.Millisecond, MaxJitter: time.Millisecond},
+	})
+
+	seen := make(map[string]BatchResult)
+	for result := range batch.Results() {
+		seen[result.Key] = result
+	}
+
+	summary := batch.Wait()
+	if summary.Total != 6 || summary.Succeeded != 2 || summary.Failed != 4 || summary.Credits != 4 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+	if len(seen) != 6 {
+		t.Fatalf("expected one result per item, got %d", len(seen))
+	}
+	if r := seen["https://example.com/a.pdf"]; r.Err != nil || r.Result == nil || r.JobID == "" {
+		t.Errorf("expected success for a.pdf, got %+v", r)
+	}
+	if r := seen["https://example.com/broken.pdf"]; r.Err == nil {
+		t.Error("expected failure for broken.pdf")
+	}
+	if r := seen["empty"]; r.Err == nil || r.Index != 3 {
+		t.Errorf("expected validation failure for empty item, got %+v", r)
+	}
+	for _, key := range []string{"ambiguous", "unnamed"} {
+		if r := seen[key]; errorTypeOf(r.Err) != ErrorTypeValidationError {
+			t.Errorf("expected validation failure for %s item, got %+v", key, r)
+		}
+	}
+	if submitted.Load() != 2 {
+		t.Errorf("expected invalid items not to be submitted, got %d submissions", submitted.Load())
+	}
+}
+
+func TestBatchJournalResume(t *testing.T) {
+	var submitted atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /ocr/uploads/url", func(w http.ResponseWriter, r *http.Request) {
+		n := submitted.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"job_id":"new_%d"}`, n)
+	})
+	mux.HandleFunc("GET /ocr/status/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"job_id":%q,"status":"completed"}`, r.PathValue("id"))
+	})
+	mux.HandleFunc("GET /ocr/result/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"job_id":%q,"status":"completed","credits_used":1}`, r.PathValue("id"))
+	})
+	sdk := newTestTeamSDK(t, mux)
+
+	path := filepath.Join(t.TempDir(), "batch.journal")
+	journal, err := OpenFileJournal(path)
+	if err != nil {
+		t.Fatalf("OpenFileJournal failed: %v", err)
+	}
+	ctx := context.Background()
+	for _, entry := range []JournalEntry{
+		{Key: "done", State: JournalQueued},
+		{Key: "done", State: JournalCompleted, JobID: "old_1"},
+		{Key: "inflight", State: JournalUploaded, JobID: "old_2"},
+		{Key: "broken", State: JournalFailed, Error: "bad scan"},
+		{Key: "pending", State: JournalQueued},
+	} {
+		if err := journal.Record(ctx, entry); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+	// Simulate a crash in the middle of a write
+	if f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600); err == nil {
+		_, _ = f.WriteString(`{"key":"pend`)
+		_ = f.Close()
+	}
+	if err := journal.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	journal, err = OpenFileJournal(path)
+	if err != nil {
+		t.Fatalf("reopening journal failed: %v", err)
+	}
+	defer journal.Close()
+
+	items := []BatchItem{
+		{Key: "done", URL: "https://example.com/done.pdf"},
+		{Key: "inflight", URL: "https://example.com/inflight.pdf"},
+		{Key: "broken", URL: "https://example.com/broken.pdf"},
+		{Key: "pending", URL: "https://example.com/pending.pdf"},
+		{Key: "pending", URL: "https://example.com/pending-copy.pdf"},
+	}
+	batch := sdk.Batch(ctx, items, BatchOptions{
+		Options:     []ProcessingOption{WithFormat(FormatMarkdown)},
+		WaitOptions: WaitOptions{InitialDelay: time.Millisecond, MaxJitter: time.Millisecond},
+		Journal:     journal,
+	})
+
+	results := make(map[string]BatchResult)
+	for result := range batch.Results() {
+		if result.Index == 4 {
+			if errorTypeOf(result.Err) != ErrorTypeValidationError {
+				t.Errorf("expected a validation error for the duplicate key, got %+v", result)
+			}
+			continue
+		}
+		results[result.Key] = result
+	}
+	summary := batch.Wait()
+
+	if err := batch.Err(); err != nil {
+		t.Fatalf("unexpected journal error: %v", err)
+	}
+	if submitted.Load() != 1 {
+		t.Errorf("expected only the pending item to be submitted, got %d submissions", submitted.Load())
+	}
+	if r := results["inflight"]; r.Err != nil || r.JobID != "old_2" || r.Skipped {
+		t.Errorf("expected in-flight job to be re-attached, got %+v", r)
+	}
+	if r := results["done"]; !r.Skipped || r.Err != nil || r.Result == nil || r.Result.JobID != "old_1" {
+		t.Errorf("expected completed item to be skipped with its result fetched again, got %+v", r)
+	}
+	if r := results["broken"]; !r.Skipped || r.Err == nil {
+		t.Errorf("expected failed item to be skipped with its error, got %+v", r)
+	}
+	if summary.Skipped != 2 || summary.Succeeded != 2 || summary.Failed != 1 || summary.Credits != 2 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+
+	entries, err := journal.Load(ctx)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if entries["inflight"].State != JournalCompleted || entries["pending"].State != JournalCompleted {
+		t.Errorf("expected resumed items to be recorded as completed, got %+v", entries)
+	}
+}
@@ -0,0 +1,254 @@
+package ocr
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ResultCache stores OCR results by content key so that byte-identical files
+// processed with the same configuration are not submitted again.
+// Implementations must be safe for concurrent use.
+type ResultCache interface {
+	// Get returns the cached result for key; ok is false on a miss or when the entry expired
+	Get(ctx context.Context, key string) (result *OCRResult, ok bool, err error)
+	// Set stores the result for key
+	Set(ctx context.Context, key string, result *OCRResult) error
+}
+
+// DefaultMemoryCacheSize is the number of entries kept by a MemoryCache when no size is given
+const DefaultMemoryCacheSize = 1024
+
+// cacheEntry is a cached result together with the time it was stored
+type cacheEntry struct {
+	StoredAt time.Time       `json:"stored_at"`
+	Result   json.RawMessage `json:"result"`
+}
+
+// MemoryCache is an in-memory least-recently-used ResultCache
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	order      *list.List
+	entries    map[string]*list.Element
+	now        func() time.Time
+}
+
+// memoryCacheItem is the value of an element of MemoryCache.order
+type memoryCacheItem struct {
+	key   string
+	entry cacheEntry
+}
+
+// NewMemoryCache creates an LRU cache holding up to maxEntries results (default: DefaultMemoryCacheSize).
+// Entries older than ttl are treated as misses; a zero ttl keeps entries until they are evicted.
+func NewMemoryCache(maxEntries int, ttl time.Duration) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMemoryCacheSize
+	}
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+// Get implements ResultCache
+func (c *MemoryCache) Get(_ context.Context, key string) (*OCRResult, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	item := elem.Value.(*memoryCacheItem)
+	if expired(item.entry, c.ttl, c.now()) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil, false, nil
+	}
+
+	c.order.MoveToFront(elem)
+	return decodeCachedResult(item.entry)
+}
+
+// Set implements ResultCache
+func (c *MemoryCache) Set(_ context.Context, key string, result *OCRResult) error {
+	entry, err := newCacheEntry(result, c.now())
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*memoryCacheItem).entry = entry
+		c.order.MoveToFront(elem)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(&memoryCacheItem{key: key, entry: entry})
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryCacheItem).key)
+	}
+	return nil
+}
+
+// Len returns the number of cached entries, including expired ones not yet evicted
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// DirCache is a ResultCache storing one JSON file per entry in a directory.
+// It survives restarts and can be shared by processes on the same machine.
+type DirCache struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewDirCache creates a cache in dir, creating the directory if needed.
+// Entries older than ttl are treated as misses and removed; a zero ttl keeps entries forever.
+func NewDirCache(dir string, ttl time.Duration) (*DirCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, NewSDKError(ErrorTypeInvalidConfig, "failed to create cache directory", err)
+	}
+	return &DirCache{dir: dir, ttl: ttl, now: time.Now}, nil
+}
+
+// Get implements ResultCache
+func (c *DirCache) Get(_ context.Context, key string) (*OCRResult, bool, error) {
+	path := c.path(key)
+	data, err := os.ReadFile(path) // #nosec G304 - key is a hex digest
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, NewSDKError(ErrorTypeUnknown, "failed to read cache entry", err)
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false, NewSDKError(ErrorTypeUnknown, "corrupt cache entry", err)
+	}
+	if expired(entry, c.ttl, c.now()) {
+		_ = os.Remove(path) //nolint:errcheck
+		return nil, false, nil
+	}
+
+	return decodeCachedResult(entry)
+}
+
+// Set implements ResultCache by writing the entry to a temporary file and renaming it into place
+func (c *DirCache) Set(_ context.Context, key string, result *OCRResult) error {
+	entry, err := newCacheEntry(result, c.now())
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return NewSDKError(ErrorTypeUnknown, "failed to encode cache entry", err)
+	}
+
+	if err := writeFileAtomic(c.path(key), data, 0o600); err != nil {
+		return NewSDKError(ErrorTypeUnknown, "failed to write cache entry", err)
+	}
+	return nil
+}
+
+func (c *DirCache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+func newCacheEntry(result *OCRResult, now time.Time) (cacheEntry, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return cacheEntry{}, NewSDKError(ErrorTypeUnknown, "failed to encode cached result", err)
+	}
+	return cacheEntry{StoredAt: now, Result: data}, nil
+}
+
+// decodeCachedResult returns a fresh copy of the cached result so callers cannot modify the cache
+func decodeCachedResult(entry cacheEntry) (*OCRResult, bool, error) {
+	var result OCRResult
+	if err := json.Unmarshal(entry.Result, &result); err != nil {
+		return nil, false, NewSDKError(ErrorTypeUnknown, "corrupt cached result", err)
+	}
+	result.Cached = true
+	return &result, true, nil
+}
+
+func expired(entry cacheEntry, ttl time.Duration, now time.Time) bool {
+	return ttl > 0 && now.Sub(entry.StoredAt) > ttl
+}
+
+// resultCacheKey returns the cache key of a file: the SHA-256 of its content
+// combined with the settings that affect the result
+func resultCacheKey(content []byte, config *processingConfig) (string, error) {
+	settings := struct {
+		Format       Format         `json:"format,omitempty"`
+		Model        string         `json:"model,omitempty"`
+		Schema       map[string]any `json:"schema,omitempty"`
+		Instructions string         `json:"instructions,omitempty"`
+		TemplateSlug string         `json:"template_slug,omitempty"`
+	}{
+		Model:        config.model,
+		Schema:       config.schema,
+		Instructions: config.instructions,
+		TemplateSlug: config.templateSlug,
+	}
+	// The format is not sent with a template, which defines its own
+	if !config.templateSlugSet {
+		settings.Format = config.format
+	}
+
+	// encoding/json sorts map keys, so equal schemas encode identically
+	encoded, err := json.Marshal(settings)
+	if err != nil {
+		return "", NewSDKError(ErrorTypeValidationError, "failed to encode processing configuration", err)
+	}
+
+	contentSum := sha256.Sum256(content)
+	h := sha256.New()
+	h.Write(contentSum[:])
+	h.Write(encoded)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// cachedResult looks key up in the configured cache; lookup failures are treated as misses
+func (s *SDK) cachedResult(ctx context.Context, key string) *OCRResult {
+	if s.config.ResultCache == nil || key == "" {
+		return nil
+	}
+	result, ok, err := s.config.ResultCache.Get(ctx, key)
+	if err != nil || !ok {
+		return nil
+	}
+	return result
+}
+
+// storeResult saves a result in the configured cache. Failures are ignored:
+// the result is valid and a later identical file is simply processed again.
+func (s *SDK) storeResult(ctx context.Context, key string, result *OCRResult) {
+	if s.config.ResultCache == nil || key == "" || result == nil {
+		return
+	}
+	_ = s.config.ResultCache.Set(ctx, key, result) //nolint:errcheck
+}
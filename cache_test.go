@@ -0,0 +1,163 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newFileProcessingMux serves the direct upload flow and completes every job
+// immediately with a single markdown page, counting initiated uploads
+func newFileProcessingMux(uploads *atomic.Int32) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /ocr/uploads/direct", func(w http.ResponseWriter, r *http.Request) {
+		n := uploads.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"job_id":"job_%d","parts":[{"part_number":1,"start_byte":0,"end_byte":1023,"upload_url":"http://%s/upload?X-Amz-Credential=AKIA123&X-Amz-Signature=deadbeef"}]}`, n, r.Host)
+	})
+	mux.HandleFunc("PUT /upload", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("ETag", `"etag"`)
+	})
+	mux.HandleFunc("POST /ocr/uploads/{id}/complete", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{}`)
+	})
+	mux.HandleFunc("GET /ocr/status/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"job_id":%q,"status":"completed"}`, r.PathValue("id"))
+	})
+	mux.HandleFunc("GET /ocr/result/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"job_id":%q,"status":"completed","credits_used":2,"pages":[{"page_number":1,"result":"# %s"}]}`, r.PathValue("id"), r.PathValue("id"))
+	})
+	return mux
+}
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	cache := NewMemoryCache(2, time.Hour)
+	cache.now = func() time.Time { return now }
+
+	for _, key := range []string{"a", "b"} {
+		if err := cache.Set(ctx, key, &OCRResult{JobID: key, Data: map[string]any{"k": "v"}}); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+
+	result, ok, err := cache.Get(ctx, "a")
+	if err != nil || !ok || result.JobID != "a" || !result.Cached {
+		t.Fatalf("expected cached result for a, got %+v, %v, %v", result, ok, err)
+	}
+
+	// Cached results are copies
+	result.Data["k"] = "changed"
+	if again, _, _ := cache.Get(ctx, "a"); again.Data["k"] != "v" {
+		t.Error("modifying a returned result changed the cache")
+	}
+
+	// "b" is now the least recently used entry
+	_ = cache.Set(ctx, "c", &OCRResult{JobID: "c"})
+	if _, ok, _ := cache.Get(ctx, "b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	if _, ok, _ := cache.Get(ctx, "a"); !ok {
+		t.Error("expected a to be kept")
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, ok, _ := cache.Get(ctx, "c"); ok {
+		t.Error("expected c to be expired")
+	}
+	if cache.Len() != 1 {
+		t.Errorf("expected expired entry to be removed, got %d entries", cache.Len())
+	}
+}
+
+func TestDirCache(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	now := time.Now()
+
+	cache, err := NewDirCache(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("NewDirCache failed: %v", err)
+	}
+	cache.now = func() time.Time { return now }
+
+	if _, ok, err := cache.Get(ctx, "missing"); ok || err != nil {
+		t.Fatalf("expected clean miss, got %v, %v", ok, err)
+	}
+	if err := cache.Set(ctx, "key", &OCRResult{JobID: "job_1", Text: "hello", Credits: 3}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	// A second instance sees entries written by the first
+	reopened, err := NewDirCache(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("NewDirCache failed: %v", err)
+	}
+	reopened.now = cache.now
+	result, ok, err := reopened.Get(ctx, "key")
+	if err != nil || !ok || result.Text != "hello" || result.Credits != 3 || !result.Cached {
+		t.Fatalf("expected cached result, got %+v, %v, %v", result, ok, err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, ok, _ := reopened.Get(ctx, "key"); ok {
+		t.Error("expected entry to be expired")
+	}
+}
+
+func TestProcessFileAndWaitCache(t *testing.T) {
+	var uploads atomic.Int32
+	mux := newFileProcessingMux(&uploads)
+
+	sdk := newTestTeamSDK(t, mux)
+	sdk.config.ResultCache = NewMemoryCache(0, 0)
+	ctx := context.Background()
+	content := []byte("%PDF-1.4 same bytes")
+
+	tests := []struct {
+		name        string
+		content     []byte
+		opts        []ProcessingOption
+		wantCached  bool
+		wantUploads int32
+	}{
+		{"first submission", content, nil, false, 1},
+		{"identical file", content, nil, true, 1},
+		{"different settings", content, []ProcessingOption{WithModel(ModelProV2)}, false, 2},
+		{"explicit defaults", content, []ProcessingOption{WithModel(ModelStandardV2)}, true, 2},
+		{"different bytes", []byte("%PDF-1.4 other bytes"), nil, false, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]ProcessingOption{WithFormat(FormatMarkdown)}, tt.opts...)
+			result, err := sdk.ProcessFileAndWait(ctx, bytes.NewReader(tt.content), "doc.pdf", opts...)
+			if err != nil {
+				t.Fatalf("ProcessFileAndWait failed: %v", err)
+			}
+			if result.Cached != tt.wantCached {
+				t.Errorf("Cached = %v, want %v", result.Cached, tt.wantCached)
+			}
+			if got := uploads.Load(); got != tt.wantUploads {
+				t.Errorf("uploads = %d, want %d", got, tt.wantUploads)
+			}
+		})
+	}
+
+	// Batch reuses the same cache and does not count cached credits twice
+	batch := sdk.Batch(ctx, []BatchItem{ReaderItem("doc.pdf", bytes.NewReader(content))}, BatchOptions{
+		Options: []ProcessingOption{WithFormat(FormatMarkdown)},
+	})
+	summary := batch.Wait()
+	if summary.Succeeded != 1 || summary.Credits != 0 || uploads.Load() != 3 {
+		t.Errorf("expected batch item to be served from the cache, got %+v", summary)
+	}
+}
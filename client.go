@@ -0,0 +1,93 @@
+package ocr
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"iter"
+)
+
+// Client is the API of the SDK. *SDK implements it; code that depends on
+// Client, or on one of the smaller interfaces it embeds, rather than *SDK can
+// be tested with ocrtest.Client.
+type Client interface {
+	JobWaiter
+	DirectoryProcessor
+	TemplateClient
+	WebhookClient
+
+	ProcessURL(ctx context.Context, fileURL string, opts ...ProcessingOption) (*Job, error)
+	ProcessFile(ctx context.Context, file io.Reader, filename string, opts ...ProcessingOption) (*Job, error)
+	ProcessFileAndWait(ctx context.Context, file io.Reader, filename string, opts ...ProcessingOption) (*OCRResult, error)
+	WaitUntilDoneWithOptions(ctx context.Context, jobID string, opts WaitOptions) (*OCRResult, error)
+	GetJobStatus(ctx context.Context, jobID string) (*JobStatusInfo, error)
+	GetJobResult(ctx context.Context, jobID string) (*OCRResult, error)
+	DeleteJob(ctx context.Context, jobID string) error
+	ListModels(ctx context.Context) ([]ModelInfo, error)
+}
+
+// DirectoryProcessor processes the files of directories
+type DirectoryProcessor interface {
+	ProcessDirectory(ctx context.Context, dir string, opts DirOptions) (*DirSummary, error)
+	ProcessDir(ctx context.Context, fsys fs.FS, opts DirOptions) (*DirSummary, error)
+}
+
+// TemplateClient manages the templates of a team
+type TemplateClient interface {
+	ListTemplates(ctx context.Context, opts TemplateListOptions) (*TemplateList, error)
+	Templates(ctx context.Context, opts TemplateListOptions) iter.Seq2[Template, error]
+	GetTemplate(ctx context.Context, templateID string) (*Template, error)
+	CreateTemplate(ctx context.Context, params TemplateParams) (*Template, error)
+	UpdateTemplate(ctx context.Context, templateID string, params TemplateParams) (*Template, error)
+	DeleteTemplate(ctx context.Context, templateID string) error
+}
+
+// WebhookClient manages the webhook subscriptions of a team and browses their delivery log
+type WebhookClient interface {
+	GetWebhookEventTypes(ctx context.Context) ([]string, error)
+	CreateWebhook(ctx context.Context, params WebhookParams) (*WebhookSubscription, error)
+	GetWebhook(ctx context.Context, webhookID string) (*WebhookSubscription, error)
+	UpdateWebhook(ctx context.Context, webhookID string, params WebhookParams) (*WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, webhookID string) error
+	ListWebhooks(ctx context.Context, opts WebhookListOptions) (*WebhookSubscriptionList, error)
+	TestWebhook(ctx context.Context, webhookID string) (map[string]any, error)
+	CheckWebhookHealth(ctx context.Context, webhookID string) (map[string]any, error)
+	RotateWebhookSecret(ctx context.Context, webhookID string) (*WebhookSecretRotation, error)
+	ListWebhookEvents(ctx context.Context, filter WebhookEventFilter, cursor string) (*WebhookEventList, error)
+	WebhookEvents(ctx context.Context, filter WebhookEventFilter) iter.Seq2[WebhookEvent, error]
+	GetWebhookEventPayload(ctx context.Context, id string) (*WebhookEventPayload, error)
+}
+
+var _ Client = (*SDK)(nil)
+
+// ProcessingSettings are the settings resulting from a list of processing
+// options, for implementations of Client other than *SDK
+type ProcessingSettings struct {
+	// Format and Model are empty when a template is used
+	Format       Format
+	Model        string
+	Schema       map[string]any
+	Instructions string
+	TemplateSlug string
+}
+
+// ResolveProcessingOptions applies opts to the default settings and validates
+// the result like the SDK does before submitting a job
+func ResolveProcessingOptions(opts ...ProcessingOption) (ProcessingSettings, error) {
+	config := applyProcessingOptions(opts)
+	if err := ValidateProcessingConfig(config); err != nil {
+		return ProcessingSettings{}, NewSDKError(ErrorTypeValidationError, "invalid processing configuration", err)
+	}
+
+	settings := ProcessingSettings{
+		Format:       config.format,
+		Model:        config.model,
+		Schema:       config.schema,
+		Instructions: config.instructions,
+		TemplateSlug: config.templateSlug,
+	}
+	if config.templateSlugSet {
+		settings.Format, settings.Model = "", ""
+	}
+	return settings, nil
+}
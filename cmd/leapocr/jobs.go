@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	ocr "github.com/leapocr/leapocr-go"
+)
+
+func runProcess(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("process", "[flags] <file|url>")
+	format := fs.String("format", "", `output format, "markdown" or "structured" (default: markdown unless -schema or -template is set)`)
+	model := fs.String("model", "", "OCR model, see \"leapocr models\"")
+	schemaFile := fs.String("schema", "", "JSON file with the extraction schema for structured output")
+	instructions := fs.String("instructions", "", "extraction instructions for structured output")
+	template := fs.String("template", "", "process with a saved template")
+	wait := fs.Bool("wait", true, "wait for the job to finish and print its result")
+	timeout := fs.Duration("timeout", 0, "give up waiting after this duration (default: no limit)")
+	output := fs.String("o", "", "write the result to this file instead of stdout")
+	positional, err := c.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	input := positional[0]
+
+	config, err := c.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	var opts []ocr.ProcessingOption
+	switch {
+	case *format != "":
+		opts = append(opts, ocr.WithFormat(ocr.Format(*format)))
+	case *schemaFile != "" || *instructions != "":
+		opts = append(opts, ocr.WithFormat(ocr.FormatStructured))
+	case *template == "" && len(config.DefaultOptions) == 0:
+		opts = append(opts, ocr.WithFormat(ocr.FormatMarkdown))
+	}
+	if *model != "" {
+		opts = append(opts, ocr.WithModelString(*model))
+	}
+	if *schemaFile != "" {
+		schema, err := readSchema(*schemaFile)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, ocr.WithSchema(schema))
+	}
+	if *instructions != "" {
+		opts = append(opts, ocr.WithInstructions(*instructions))
+	}
+	if *template != "" {
+		opts = append(opts, ocr.WithTemplateSlug(*template))
+	}
+
+	sdk, err := ocr.NewSDK(config)
+	if err != nil {
+		return err
+	}
+
+	var job *ocr.Job
+	if strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://") {
+		job, err = sdk.ProcessURL(ctx, input, opts...)
+	} else {
+		job, err = processLocalFile(ctx, sdk, input, opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !*wait {
+		if c.json {
+			return writeJSON(c.stdout, map[string]string{"job_id": job.ID, "status": job.Status})
+		}
+		_, err := fmt.Fprintln(c.stdout, job.ID)
+		return err
+	}
+
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	result, err := sdk.WaitUntilDone(ctx, job.ID)
+	if err != nil {
+		return err
+	}
+	return c.writeResult(result, *output)
+}
+
+// processLocalFile submits the file at path
+func processLocalFile(ctx context.Context, sdk *ocr.SDK, path string, opts []ocr.ProcessingOption) (*ocr.Job, error) {
+	file, err := os.Open(path) // #nosec G304 - path is given on the command line
+	if err != nil {
+		return nil, ocr.NewSDKError(ocr.ErrorTypeValidationError, "failed to open file", err)
+	}
+	defer func() { _ = file.Close() }() //nolint:errcheck
+
+	return sdk.ProcessFile(ctx, file, filepath.Base(path), opts...)
+}
+
+// readSchema reads an extraction schema from a JSON file
+func readSchema(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - path is given on the command line
+	if err != nil {
+		return nil, ocr.NewSDKError(ocr.ErrorTypeValidationError, "failed to read schema file", err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, ocr.NewSDKError(ocr.ErrorTypeValidationError, "schema file is not a JSON object", err)
+	}
+	return schema, nil
+}
+
+func runStatus(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("status", "[flags] <job-id>")
+	positional, err := c.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	sdk, err := c.sdk()
+	if err != nil {
+		return err
+	}
+
+	status, err := sdk.GetJobStatus(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return writeJSON(c.stdout, status)
+	}
+
+	fmt.Fprintf(c.stdout, "%s\t%s\t%.0f%%\n", status.ID, status.Status, status.Progress)
+	if status.Error != "" {
+		fmt.Fprintf(c.stdout, "error: %s\n", status.Error)
+	}
+	return nil
+}
+
+func runResult(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("result", "[flags] <job-id>")
+	output := fs.String("o", "", "write the result to this file instead of stdout")
+	positional, err := c.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	sdk, err := c.sdk()
+	if err != nil {
+		return err
+	}
+
+	result, err := sdk.GetJobResult(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	return c.writeResult(result, *output)
+}
+
+func runDelete(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("delete", "[flags] <job-id>")
+	positional, err := c.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	sdk, err := c.sdk()
+	if err != nil {
+		return err
+	}
+
+	if err := sdk.DeleteJob(ctx, positional[0]); err != nil {
+		return err
+	}
+	if c.json {
+		return writeJSON(c.stdout, map[string]any{"job_id": positional[0], "deleted": true})
+	}
+	_, err = fmt.Fprintf(c.stdout, "deleted %s\n", positional[0])
+	return err
+}
+
+func runModels(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("models", "[flags]")
+	if _, err := c.parse(fs, args, 0); err != nil {
+		return err
+	}
+	sdk, err := c.sdk()
+	if err != nil {
+		return err
+	}
+
+	models, err := sdk.ListModels(ctx)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return writeJSON(c.stdout, models)
+	}
+
+	tw := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tDISPLAY NAME\tCREDITS/PAGE\tDESCRIPTION")
+	for _, m := range models {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", m.Name, m.DisplayName, m.CreditsPerPage, m.Description)
+	}
+	return tw.Flush()
+}
+
+// writeResult writes a job result to path, or stdout when path is empty. In
+// text mode markdown results are written as is and structured results as JSON
+// data; JSON mode writes the whole result.
+func (c *cli) writeResult(result *ocr.OCRResult, path string) error {
+	var data []byte
+	switch {
+	case c.json:
+		out, err := json.MarshalIndent(struct {
+			*ocr.OCRResult
+			Duration string `json:"duration,omitempty"`
+		}{OCRResult: result, Duration: formatDuration(result.Duration)}, "", "  ")
+		if err != nil {
+			return err
+		}
+		data = append(out, '\n')
+	case result.Data != nil && strings.TrimSpace(result.Text) == "":
+		out, err := json.MarshalIndent(result.Data, "", "  ")
+		if err != nil {
+			return err
+		}
+		data = append(out, '\n')
+	default:
+		data = []byte(result.Text)
+	}
+
+	if path == "" {
+		_, err := c.stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write result: %w", err)
+	}
+	if !c.json {
+		fmt.Fprintf(c.stderr, "wrote %s (%d pages, %d credits)\n", path, len(result.Pages), result.Credits)
+	}
+	return nil
+}
+
+// formatDuration renders non-zero durations in Go notation
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
@@ -0,0 +1,281 @@
+// Command leapocr processes documents with LeapOCR from the shell.
+//
+// Usage:
+//
+//	leapocr <command> [flags] [arguments]
+//
+// The API key and other settings are read from LEAPOCR_* environment
+// variables, or from a configuration file passed with -config. Run
+// "leapocr help" for the list of commands.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+
+	ocr "github.com/leapocr/leapocr-go"
+)
+
+// Exit codes; errors returned by the SDK map to a code by their ErrorType,
+// except for canceled commands, which exit like a shell interrupted by SIGINT
+const (
+	exitOK            = 0
+	exitUnknown       = 1
+	exitUsage         = 2
+	exitInvalidConfig = 3
+	exitValidation    = 4
+	exitAPI           = 5
+	exitTimeout       = 6
+	exitUpload        = 7
+	exitJob           = 8
+	exitInterrupted   = 130
+)
+
+// command is a subcommand of the CLI, or a group of subcommands
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(ctx context.Context, c *cli, args []string) error
+	// subcommands are the commands of a group, which has no run function
+	subcommands []command
+}
+
+// commands lists the subcommands in the order they are shown in the usage
+var commands = []command{
+	{name: "process", args: "[flags] <file|url>", summary: "process a local file or a URL", run: runProcess},
+	{name: "status", args: "[flags] <job-id>", summary: "show the status of a job", run: runStatus},
+	{name: "result", args: "[flags] <job-id>", summary: "fetch the result of a completed job", run: runResult},
+	{name: "delete", args: "[flags] <job-id>", summary: "delete a job and its content", run: runDelete},
+	{name: "models", args: "[flags]", summary: "list the available OCR models", run: runModels},
+	{name: "templates", args: "<command>", summary: "manage the team's templates", subcommands: templateCommands},
+	{name: "webhooks", args: "<command>", summary: "manage the team's webhook subscriptions", subcommands: webhookCommands},
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// cli holds the output streams and the options shared by all subcommands
+type cli struct {
+	stdout io.Writer
+	stderr io.Writer
+
+	json    bool
+	config  string
+	profile string
+	baseURL string
+}
+
+// usageError reports invalid command-line usage
+type usageError struct {
+	message string
+}
+
+func (e *usageError) Error() string {
+	return e.message
+}
+
+func usagef(format string, args ...any) error {
+	return &usageError{message: fmt.Sprintf(format, args...)}
+}
+
+// errUsageShown is returned after the usage was printed for a missing or unknown command
+var errUsageShown = &usageError{message: "no command"}
+
+// run executes the command line args and returns the process exit code
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	c := &cli{stdout: stdout, stderr: stderr}
+
+	err := c.dispatch(ctx, "leapocr", commands, args)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if err != nil && err != errUsageShown {
+		c.printError(err)
+	}
+	return exitCode(err)
+}
+
+// dispatch runs the command of cmds named by args[0]; prefix is the command
+// line leading to cmds, used in messages
+func (c *cli) dispatch(ctx context.Context, prefix string, cmds []command, args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		c.usage(prefix, cmds)
+		if len(args) == 0 {
+			return errUsageShown
+		}
+		return flag.ErrHelp
+	}
+
+	i := slices.IndexFunc(cmds, func(cmd command) bool { return cmd.name == args[0] })
+	if i < 0 {
+		fmt.Fprintf(c.stderr, "%s: unknown command %q\n\n", prefix, args[0])
+		c.usage(prefix, cmds)
+		return errUsageShown
+	}
+
+	if cmds[i].subcommands != nil {
+		return c.dispatch(ctx, prefix+" "+cmds[i].name, cmds[i].subcommands, args[1:])
+	}
+	return cmds[i].run(ctx, c, args[1:])
+}
+
+func (c *cli) usage(prefix string, cmds []command) {
+	fmt.Fprintf(c.stderr, "Usage: %s <command> [flags] [arguments]\n", prefix)
+	fmt.Fprintln(c.stderr, "\nCommands:")
+	for _, cmd := range cmds {
+		fmt.Fprintf(c.stderr, "  %-14s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(c.stderr, "\nRun \"%s <command> -h\" for the flags of a command.\n", prefix)
+}
+
+// flagSet returns a flag set for a subcommand with the shared flags registered
+func (c *cli) flagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: leapocr %s %s\n\nFlags:\n", name, args)
+		fs.PrintDefaults()
+	}
+	fs.BoolVar(&c.json, "json", false, "write machine-readable JSON output")
+	fs.StringVar(&c.config, "config", "", "read settings from a YAML or JSON configuration file")
+	fs.StringVar(&c.profile, "profile", "", "profile of the configuration file to use")
+	fs.StringVar(&c.baseURL, "base-url", "", "override the API base URL")
+	return fs
+}
+
+// parse parses the flags of a subcommand, checks the number of positional arguments
+// and returns them. Flags may follow the positional arguments.
+func (c *cli) parse(fs *flag.FlagSet, args []string, positional int) ([]string, error) {
+	var rest []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, &usageError{message: err.Error()}
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		rest = append(rest, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(rest) != positional {
+		fs.Usage()
+		return nil, usagef("%s: expected %d argument(s), got %d", fs.Name(), positional, len(rest))
+	}
+	return rest, nil
+}
+
+// loadConfig builds the SDK configuration from the configuration file or the environment
+func (c *cli) loadConfig() (*ocr.Config, error) {
+	var config *ocr.Config
+	var err error
+	if c.config != "" {
+		profile := c.profile
+		if profile == "" {
+			profile = os.Getenv(ocr.EnvProfile)
+		}
+		config, err = ocr.LoadConfigProfile(c.config, profile)
+	} else {
+		if c.profile != "" {
+			return nil, usagef("-profile requires -config")
+		}
+		config, err = ocr.ConfigFromEnv()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if c.baseURL != "" {
+		config.BaseURL = strings.TrimRight(c.baseURL, "/")
+	}
+	config.UserAgent = "leapocr-cli/" + ocr.Version
+	return config, nil
+}
+
+// sdk creates the SDK client from loadConfig
+func (c *cli) sdk() (*ocr.SDK, error) {
+	config, err := c.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return ocr.NewSDK(config)
+}
+
+// writeJSON writes v as indented JSON
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// printError reports err on stderr, as a JSON object in JSON mode
+func (c *cli) printError(err error) {
+	if !c.json {
+		fmt.Fprintf(c.stderr, "leapocr: %v\n", err)
+		return
+	}
+
+	out := struct {
+		Type       string `json:"type"`
+		Message    string `json:"message"`
+		StatusCode int    `json:"status_code,omitempty"`
+	}{Type: "usage", Message: err.Error()}
+	var sdkErr *ocr.SDKError
+	if errors.As(err, &sdkErr) {
+		out.Type = string(sdkErr.Type)
+		out.StatusCode = sdkErr.StatusCode
+	} else if !errors.As(err, new(*usageError)) {
+		out.Type = string(ocr.ErrorTypeUnknown)
+	}
+	_ = writeJSON(c.stderr, map[string]any{"error": out}) //nolint:errcheck
+}
+
+// exitCode maps err to the process exit code
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	if errors.As(err, new(*usageError)) {
+		return exitUsage
+	}
+	// The SDK reports canceled waits as timeouts
+	if errors.Is(err, context.Canceled) {
+		return exitInterrupted
+	}
+
+	var sdkErr *ocr.SDKError
+	if !errors.As(err, &sdkErr) {
+		return exitUnknown
+	}
+	switch sdkErr.Type {
+	case ocr.ErrorTypeInvalidConfig:
+		return exitInvalidConfig
+	case ocr.ErrorTypeValidationError:
+		return exitValidation
+	case ocr.ErrorTypeAPIError, ocr.ErrorTypeHTTPError:
+		return exitAPI
+	case ocr.ErrorTypeTimeout:
+		return exitTimeout
+	case ocr.ErrorTypeUploadError:
+		return exitUpload
+	case ocr.ErrorTypeJobError:
+		return exitJob
+	default:
+		return exitUnknown
+	}
+}
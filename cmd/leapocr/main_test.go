@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestServer serves the API endpoints used by the job commands
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /ocr/uploads/direct", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"job_id":"job_1","parts":[{"part_number":1,"start_byte":0,"end_byte":1023,"upload_url":"http://%s/upload"}]}`, r.Host)
+	})
+	mux.HandleFunc("PUT /upload", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("ETag", `"etag"`)
+	})
+	mux.HandleFunc("POST /ocr/uploads/{id}/complete", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{}`)
+	})
+	mux.HandleFunc("GET /ocr/status/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") != "job_1" {
+			http.Error(w, `{"error":"job not found"}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"job_id":"job_1","status":"completed","processed_pages":2,"total_pages":2}`)
+	})
+	mux.HandleFunc("GET /ocr/result/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"job_id":"job_1","status":"completed","credits_used":2,"pages":[{"page_number":1,"result":"# Invoice"}]}`)
+	})
+	mux.HandleFunc("DELETE /ocr/delete/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /ocr/models", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"count":1,"models":[{"name":"pro-v2","display_name":"Pro","credits_per_page":3,"description":"Highest quality"}]}`)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCommands(t *testing.T) {
+	server := newTestServer(t)
+	t.Setenv("LEAPOCR_API_KEY", "test-key")
+	t.Setenv("LEAPOCR_BASE_URL", server.URL)
+
+	dir := t.TempDir()
+	doc := filepath.Join(dir, "doc.pdf")
+	if err := os.WriteFile(doc, []byte("%PDF"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	notes := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(notes, []byte("text"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantOut  string
+	}{
+		{name: "process", args: []string{"process", doc}, wantCode: exitOK, wantOut: "# Invoice"},
+		{name: "process no wait", args: []string{"process", doc, "-wait=false"}, wantCode: exitOK, wantOut: "job_1\n"},
+		{name: "status", args: []string{"status", "job_1"}, wantCode: exitOK, wantOut: "job_1\tcompleted\t100%\n"},
+		{name: "status json", args: []string{"status", "-json", "job_1"}, wantCode: exitOK, wantOut: `"status": "completed"`},
+		{name: "result json", args: []string{"result", "-json", "job_1"}, wantCode: exitOK, wantOut: `"credits": 2`},
+		{name: "delete", args: []string{"delete", "job_1"}, wantCode: exitOK, wantOut: "deleted job_1\n"},
+		{name: "models", args: []string{"models"}, wantCode: exitOK, wantOut: "pro-v2"},
+		{name: "unknown command", args: []string{"frobnicate"}, wantCode: exitUsage},
+		{name: "missing argument", args: []string{"status"}, wantCode: exitUsage},
+		{name: "unknown job", args: []string{"status", "job_2"}, wantCode: exitAPI},
+		{name: "unsupported file", args: []string{"process", notes}, wantCode: exitValidation},
+		{name: "invalid format", args: []string{"process", "-format", "html", doc}, wantCode: exitValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(context.Background(), tt.args, &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Fatalf("expected exit code %d, got %d (stderr: %s)", tt.wantCode, code, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.wantOut) {
+				t.Errorf("expected output to contain %q, got %q", tt.wantOut, stdout.String())
+			}
+		})
+	}
+}
+
+func TestProcessOutputFile(t *testing.T) {
+	server := newTestServer(t)
+	t.Setenv("LEAPOCR_API_KEY", "test-key")
+
+	dir := t.TempDir()
+	doc := filepath.Join(dir, "doc.pdf")
+	if err := os.WriteFile(doc, []byte("%PDF"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "doc.md")
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{"process", "-base-url", server.URL, "-o", out, doc}, &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("expected success, got %d: %s", code, stderr.String())
+	}
+	if data, err := os.ReadFile(out); err != nil || string(data) != "# Invoice\n" {
+		t.Errorf("unexpected output file: %q, %v", data, err)
+	}
+}
+
+func TestInterruptedWait(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /ocr/uploads/direct", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"job_id":"job_1","parts":[{"part_number":1,"start_byte":0,"end_byte":1023,"upload_url":"http://%s/upload"}]}`, r.Host)
+	})
+	mux.HandleFunc("PUT /upload", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("ETag", `"etag"`)
+	})
+	mux.HandleFunc("POST /ocr/uploads/{id}/complete", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{}`)
+	})
+	mux.HandleFunc("GET /ocr/status/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"job_id":"job_1","status":"processing","processed_pages":1,"total_pages":2}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	t.Setenv("LEAPOCR_API_KEY", "test-key")
+	t.Setenv("LEAPOCR_BASE_URL", server.URL)
+
+	doc := filepath.Join(t.TempDir(), "doc.pdf")
+	if err := os.WriteFile(doc, []byte("%PDF"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// An interrupt cancels the context; only an exceeded deadline is a timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	var stdout, stderr bytes.Buffer
+	if code := run(ctx, []string{"process", doc}, &stdout, &stderr); code != exitInterrupted {
+		t.Errorf("expected exit code %d when interrupted, got %d (stderr: %s)", exitInterrupted, code, stderr.String())
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if code := run(ctx, []string{"process", doc}, &stdout, &stderr); code != exitTimeout {
+		t.Errorf("expected exit code %d on deadline, got %d (stderr: %s)", exitTimeout, code, stderr.String())
+	}
+}
+
+func TestErrorOutput(t *testing.T) {
+	t.Setenv("LEAPOCR_API_KEY", "")
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{"models", "-json"}, &stdout, &stderr)
+	if code != exitInvalidConfig {
+		t.Fatalf("expected exit code %d without API key, got %d", exitInvalidConfig, code)
+	}
+
+	var out struct {
+		Error struct {
+			Type string `json:"type"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(stderr.Bytes(), &out); err != nil || out.Error.Type != "invalid_config" {
+		t.Errorf("expected JSON error on stderr, got %q (%v)", stderr.String(), err)
+	}
+}
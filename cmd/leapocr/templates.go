@@ -0,0 +1,509 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+
+	ocr "github.com/leapocr/leapocr-go"
+)
+
+// templateCommands are the subcommands of "leapocr templates"
+var templateCommands = []command{
+	{name: "list", args: "[flags]", summary: "list templates", run: runTemplatesList},
+	{name: "get", args: "[flags] <template-id>", summary: "show a template", run: runTemplatesGet},
+	{name: "create", args: "[flags]", summary: "create a template from flags or a definition file", run: runTemplatesCreate},
+	{name: "update", args: "[flags] <template-id>", summary: "change a template", run: runTemplatesUpdate},
+	{name: "delete", args: "[flags] <template-id>", summary: "delete a template", run: runTemplatesDelete},
+	{name: "sync", args: "[flags] <file|dir>", summary: "create or update templates from definition files", run: runTemplatesSync},
+}
+
+// templateDefinition is a template as written in a definition file. Slug,
+// when set, identifies the existing template to update during sync; otherwise
+// templates are matched by name.
+type templateDefinition struct {
+	Slug                 string         `yaml:"slug" json:"slug,omitempty"`
+	Name                 string         `yaml:"name" json:"name"`
+	Description          string         `yaml:"description" json:"description,omitempty"`
+	Format               ocr.Format     `yaml:"format" json:"format"`
+	Model                string         `yaml:"model" json:"model,omitempty"`
+	Instructions         string         `yaml:"instructions" json:"instructions,omitempty"`
+	Schema               map[string]any `yaml:"schema" json:"schema,omitempty"`
+	Tags                 []string       `yaml:"tags" json:"tags,omitempty"`
+	Enabled              *bool          `yaml:"enabled" json:"enabled,omitempty"`
+	ExtractBoundingBoxes *bool          `yaml:"extract_bounding_boxes" json:"extract_bounding_boxes,omitempty"`
+}
+
+func (d *templateDefinition) params() ocr.TemplateParams {
+	return ocr.TemplateParams{
+		Name:                 d.Name,
+		Description:          d.Description,
+		Format:               d.Format,
+		Model:                d.Model,
+		Instructions:         d.Instructions,
+		Schema:               d.Schema,
+		Tags:                 d.Tags,
+		Enabled:              d.Enabled,
+		ExtractBoundingBoxes: d.ExtractBoundingBoxes,
+	}
+}
+
+// templateFlags are the flags setting the fields of a template
+type templateFlags struct {
+	fs           *flag.FlagSet
+	file         *string
+	name         *string
+	description  *string
+	format       *string
+	model        *string
+	instructions *string
+	schema       *string
+	tags         *string
+	enabled      *bool
+	boundingBox  *bool
+}
+
+func addTemplateFlags(fs *flag.FlagSet, create bool) *templateFlags {
+	f := &templateFlags{fs: fs}
+	f.file = fs.String("f", "", "read the template from a YAML or JSON definition file")
+	f.name = fs.String("name", "", "template name")
+	f.description = fs.String("description", "", "template description")
+	f.format = fs.String("format", "", `output format, "markdown" or "structured"`)
+	if create {
+		f.model = fs.String("model", "", "OCR model (default: standard-v2)")
+	}
+	f.instructions = fs.String("instructions", "", "extraction instructions")
+	f.schema = fs.String("schema", "", "JSON file with the extraction schema")
+	f.tags = fs.String("tags", "", "comma-separated tags")
+	f.enabled = fs.Bool("enabled", true, "enable the template")
+	f.boundingBox = fs.Bool("bounding-boxes", false, "extract bounding boxes")
+	return f
+}
+
+// apply sets the fields given on the command line in params. A definition
+// file replaces params first.
+func (f *templateFlags) apply(params *ocr.TemplateParams) error {
+	if *f.file != "" {
+		defs, err := readTemplateDefinitions(*f.file)
+		if err != nil {
+			return err
+		}
+		if len(defs) != 1 {
+			return usagef("%s: expected one template definition, got %d", *f.file, len(defs))
+		}
+		*params = defs[0].params()
+	}
+
+	var err error
+	f.fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "name":
+			params.Name = *f.name
+		case "description":
+			params.Description = *f.description
+		case "format":
+			params.Format = ocr.Format(*f.format)
+		case "model":
+			params.Model = *f.model
+		case "instructions":
+			params.Instructions = *f.instructions
+		case "schema":
+			params.Schema, err = readSchema(*f.schema)
+		case "tags":
+			params.Tags = splitList(*f.tags)
+		case "enabled":
+			params.Enabled = f.enabled
+		case "bounding-boxes":
+			params.ExtractBoundingBoxes = f.boundingBox
+		}
+	})
+	return err
+}
+
+func runTemplatesList(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("templates list", "[flags]")
+	search := fs.String("search", "", "only list templates whose name matches")
+	format := fs.String("format", "", "only list templates with this output format")
+	limit := fs.Int("limit", 0, "list at most this many templates (default: all)")
+	if _, err := c.parse(fs, args, 0); err != nil {
+		return err
+	}
+	sdk, err := c.sdk()
+	if err != nil {
+		return err
+	}
+
+	templates := []ocr.Template{}
+	opts := ocr.TemplateListOptions{Search: *search, Format: ocr.Format(*format), Limit: *limit}
+	for template, err := range sdk.Templates(ctx, opts) {
+		if err != nil {
+			return err
+		}
+		templates = append(templates, template)
+		if *limit > 0 && len(templates) == *limit {
+			break
+		}
+	}
+	if c.json {
+		return writeJSON(c.stdout, templates)
+	}
+
+	tw := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSLUG\tNAME\tFORMAT\tMODEL\tENABLED\tUSED")
+	for _, t := range templates {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%t\t%d\n", t.ID, t.Slug, t.Name, t.Format, t.Model, t.Enabled, t.UsageCount)
+	}
+	return tw.Flush()
+}
+
+func runTemplatesGet(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("templates get", "[flags] <template-id>")
+	positional, err := c.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	sdk, err := c.sdk()
+	if err != nil {
+		return err
+	}
+
+	template, err := sdk.GetTemplate(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	return c.writeTemplate(template)
+}
+
+func runTemplatesCreate(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("templates create", "[flags]")
+	flags := addTemplateFlags(fs, true)
+	if _, err := c.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	var params ocr.TemplateParams
+	if err := flags.apply(&params); err != nil {
+		return err
+	}
+	sdk, err := c.sdk()
+	if err != nil {
+		return err
+	}
+
+	template, err := sdk.CreateTemplate(ctx, params)
+	if err != nil {
+		return err
+	}
+	return c.writeTemplate(template)
+}
+
+func runTemplatesUpdate(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("templates update", "[flags] <template-id>")
+	flags := addTemplateFlags(fs, false)
+	positional, err := c.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	sdk, err := c.sdk()
+	if err != nil {
+		return err
+	}
+
+	// Updates replace every field, so unset flags keep the current values
+	current, err := sdk.GetTemplate(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	params := templateParams(current)
+	if err := flags.apply(&params); err != nil {
+		return err
+	}
+
+	template, err := sdk.UpdateTemplate(ctx, current.ID, params)
+	if err != nil {
+		return err
+	}
+	return c.writeTemplate(template)
+}
+
+func runTemplatesDelete(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("templates delete", "[flags] <template-id>")
+	positional, err := c.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	sdk, err := c.sdk()
+	if err != nil {
+		return err
+	}
+
+	if err := sdk.DeleteTemplate(ctx, positional[0]); err != nil {
+		return err
+	}
+	if c.json {
+		return writeJSON(c.stdout, map[string]any{"template_id": positional[0], "deleted": true})
+	}
+	_, err = fmt.Fprintf(c.stdout, "deleted %s\n", positional[0])
+	return err
+}
+
+// syncAction is the outcome of syncing one template
+type syncAction struct {
+	Action string `json:"action"`
+	ID     string `json:"id,omitempty"`
+	Name   string `json:"name"`
+	Note   string `json:"note,omitempty"`
+}
+
+func runTemplatesSync(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("templates sync", "[flags] <file|dir>")
+	prune := fs.Bool("prune", false, "delete templates without a definition")
+	dryRun := fs.Bool("dry-run", false, "report the changes without applying them")
+	positional, err := c.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	defs, err := readTemplateDefinitions(positional[0])
+	if err != nil {
+		return err
+	}
+	sdk, err := c.sdk()
+	if err != nil {
+		return err
+	}
+
+	var existing []ocr.Template
+	for template, err := range sdk.Templates(ctx, ocr.TemplateListOptions{}) {
+		if err != nil {
+			return err
+		}
+		existing = append(existing, template)
+	}
+
+	matched := make(map[string]bool)
+	var actions []syncAction
+	for _, def := range defs {
+		i := slices.IndexFunc(existing, func(t ocr.Template) bool {
+			if def.Slug != "" {
+				return t.Slug == def.Slug
+			}
+			return t.Name == def.Name
+		})
+
+		action := syncAction{Name: def.Name}
+		switch {
+		case i < 0:
+			action.Action = "create"
+			if !*dryRun {
+				template, err := sdk.CreateTemplate(ctx, def.params())
+				if err != nil {
+					return fmt.Errorf("template %q: %w", def.Name, err)
+				}
+				action.ID = template.ID
+			}
+		case templateMatches(&existing[i], &def):
+			action.Action = "unchanged"
+			action.ID = existing[i].ID
+		default:
+			action.Action = "update"
+			action.ID = existing[i].ID
+			if !*dryRun {
+				if _, err := sdk.UpdateTemplate(ctx, existing[i].ID, def.params()); err != nil {
+					return fmt.Errorf("template %q: %w", def.Name, err)
+				}
+			}
+		}
+		if i >= 0 {
+			matched[existing[i].ID] = true
+			if def.Model != "" && def.Model != existing[i].Model {
+				action.Note = fmt.Sprintf("model stays %s; recreate the template to change it", existing[i].Model)
+			}
+		}
+		actions = append(actions, action)
+	}
+
+	if *prune {
+		for _, t := range existing {
+			if matched[t.ID] {
+				continue
+			}
+			if !*dryRun {
+				if err := sdk.DeleteTemplate(ctx, t.ID); err != nil {
+					return fmt.Errorf("template %q: %w", t.Name, err)
+				}
+			}
+			actions = append(actions, syncAction{Action: "delete", ID: t.ID, Name: t.Name})
+		}
+	}
+
+	if c.json {
+		return writeJSON(c.stdout, map[string]any{"dry_run": *dryRun, "actions": actions})
+	}
+	tw := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ACTION\tID\tNAME\tNOTE")
+	for _, a := range actions {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", a.Action, a.ID, a.Name, a.Note)
+	}
+	return tw.Flush()
+}
+
+// readTemplateDefinitions reads the template definitions of a file, or of the
+// .yaml, .yml and .json files of a directory. A file holds one definition or a
+// list of them.
+func readTemplateDefinitions(path string) ([]templateDefinition, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, ocr.NewSDKError(ocr.ErrorTypeValidationError, "failed to read template definitions", err)
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, ocr.NewSDKError(ocr.ErrorTypeValidationError, "failed to read template definitions", err)
+		}
+		files = files[:0]
+		for _, entry := range entries {
+			switch strings.ToLower(filepath.Ext(entry.Name())) {
+			case ".yaml", ".yml", ".json":
+				if !entry.IsDir() {
+					files = append(files, filepath.Join(path, entry.Name()))
+				}
+			}
+		}
+		sort.Strings(files)
+	}
+
+	var defs []templateDefinition
+	for _, file := range files {
+		data, err := os.ReadFile(file) // #nosec G304 - path is given on the command line
+		if err != nil {
+			return nil, ocr.NewSDKError(ocr.ErrorTypeValidationError, "failed to read template definitions", err)
+		}
+
+		// JSON is valid YAML, so both formats go through the YAML decoder
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return nil, ocr.NewSDKError(ocr.ErrorTypeValidationError, fmt.Sprintf("invalid template definition %s", file), err)
+		}
+		var fileDefs []templateDefinition
+		if len(node.Content) > 0 && node.Content[0].Kind == yaml.SequenceNode {
+			err = node.Decode(&fileDefs)
+		} else {
+			var def templateDefinition
+			err = node.Decode(&def)
+			fileDefs = []templateDefinition{def}
+		}
+		if err != nil {
+			return nil, ocr.NewSDKError(ocr.ErrorTypeValidationError, fmt.Sprintf("invalid template definition %s", file), err)
+		}
+
+		for _, def := range fileDefs {
+			if def.Name == "" {
+				return nil, ocr.NewValidationError("name", fmt.Sprintf("template in %s has no name", file))
+			}
+			defs = append(defs, def)
+		}
+	}
+	return defs, nil
+}
+
+// templateMatches reports whether t already has the settings of def
+func templateMatches(t *ocr.Template, def *templateDefinition) bool {
+	return t.Name == def.Name &&
+		t.Description == def.Description &&
+		t.Format == def.Format &&
+		t.Instructions == def.Instructions &&
+		slices.Equal(t.Tags, def.Tags) &&
+		(def.Enabled == nil || *def.Enabled == t.Enabled) &&
+		(def.ExtractBoundingBoxes == nil || *def.ExtractBoundingBoxes == t.ExtractBoundingBoxes) &&
+		sameJSON(t.Schema, def.Schema)
+}
+
+// sameJSON compares values by their JSON encoding, so numbers decoded from
+// YAML and JSON compare equal
+func sameJSON(a, b map[string]any) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	normalize := func(v map[string]any) any {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		var out any
+		if err := json.Unmarshal(data, &out); err != nil {
+			return nil
+		}
+		return out
+	}
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+// templateParams returns the parameters recreating t
+func templateParams(t *ocr.Template) ocr.TemplateParams {
+	return ocr.TemplateParams{
+		Name:                 t.Name,
+		Description:          t.Description,
+		Format:               t.Format,
+		Instructions:         t.Instructions,
+		Schema:               t.Schema,
+		Tags:                 t.Tags,
+		Enabled:              &t.Enabled,
+		ExtractBoundingBoxes: &t.ExtractBoundingBoxes,
+	}
+}
+
+// writeTemplate prints a template as a field list, or as JSON in JSON mode
+func (c *cli) writeTemplate(t *ocr.Template) error {
+	if c.json {
+		return writeJSON(c.stdout, t)
+	}
+
+	tw := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "ID:\t%s\n", t.ID)
+	fmt.Fprintf(tw, "Slug:\t%s\n", t.Slug)
+	fmt.Fprintf(tw, "Name:\t%s\n", t.Name)
+	if t.Description != "" {
+		fmt.Fprintf(tw, "Description:\t%s\n", t.Description)
+	}
+	fmt.Fprintf(tw, "Format:\t%s\n", t.Format)
+	fmt.Fprintf(tw, "Model:\t%s\n", t.Model)
+	fmt.Fprintf(tw, "Enabled:\t%t\n", t.Enabled)
+	if len(t.Tags) > 0 {
+		fmt.Fprintf(tw, "Tags:\t%s\n", strings.Join(t.Tags, ", "))
+	}
+	if t.Instructions != "" {
+		fmt.Fprintf(tw, "Instructions:\t%s\n", t.Instructions)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(t.Schema) > 0 {
+		fmt.Fprintln(c.stdout, "Schema:")
+		return writeJSON(c.stdout, t.Schema)
+	}
+	return nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty items
+func splitList(s string) []string {
+	var items []string
+	for item := range strings.SplitSeq(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// templateStore is an in-memory template API of team team_1
+type templateStore struct {
+	mu        sync.Mutex
+	templates []map[string]any
+	requests  []string
+}
+
+func (s *templateStore) handler() http.Handler {
+	const base = "/organizations/org_1/teams/team_1/templates"
+	write := func(w http.ResponseWriter, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(v) //nolint:errcheck
+	}
+	find := func(id string) int {
+		for i, t := range s.templates {
+			if t["id"] == id {
+				return i
+			}
+		}
+		return -1
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+base, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		write(w, map[string]any{"data": s.templates, "has_more": false})
+	})
+	mux.HandleFunc("GET "+base+"/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		i := find(r.PathValue("id"))
+		if i < 0 {
+			http.Error(w, `{"error":"template not found"}`, http.StatusNotFound)
+			return
+		}
+		write(w, s.templates[i])
+	})
+	mux.HandleFunc("POST "+base, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		var template map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&template) //nolint:errcheck
+		template["id"] = fmt.Sprintf("tpl_%d", len(s.templates)+1)
+		template["slug"] = strings.ToLower(strings.ReplaceAll(template["name"].(string), " ", "-"))
+		s.templates = append(s.templates, template)
+		s.requests = append(s.requests, "create "+template["name"].(string))
+		write(w, template)
+	})
+	mux.HandleFunc("PUT "+base+"/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		i := find(r.PathValue("id"))
+		var update map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&update) //nolint:errcheck
+		for k, v := range update {
+			s.templates[i][k] = v
+		}
+		s.requests = append(s.requests, "update "+r.PathValue("id"))
+		write(w, s.templates[i])
+	})
+	mux.HandleFunc("DELETE "+base+"/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		i := find(r.PathValue("id"))
+		s.templates = append(s.templates[:i], s.templates[i+1:]...)
+		s.requests = append(s.requests, "delete "+r.PathValue("id"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return mux
+}
+
+// setTeamEnv points the CLI at server with the team scope of the test handlers
+func setTeamEnv(t *testing.T, server *httptest.Server) {
+	t.Helper()
+	t.Setenv("LEAPOCR_API_KEY", "test-key")
+	t.Setenv("LEAPOCR_BASE_URL", server.URL)
+	t.Setenv("LEAPOCR_ORGANIZATION_ID", "org_1")
+	t.Setenv("LEAPOCR_TEAM_ID", "team_1")
+}
+
+func runCLI(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, &stdout, &stderr)
+	if code != exitOK {
+		t.Logf("stderr: %s", stderr.String())
+	}
+	return stdout.String(), code
+}
+
+func TestTemplateCommands(t *testing.T) {
+	store := &templateStore{templates: []map[string]any{
+		{"id": "tpl_0", "slug": "receipts", "name": "Receipts", "format": "markdown", "model": "standard-v2", "enabled": true},
+	}}
+	server := httptest.NewServer(store.handler())
+	defer server.Close()
+	setTeamEnv(t, server)
+
+	out, code := runCLI(t, "templates", "list")
+	if code != exitOK || !strings.Contains(out, "receipts") {
+		t.Fatalf("list: code %d, output %q", code, out)
+	}
+
+	out, code = runCLI(t, "templates", "create", "-name", "Invoices", "-format", "markdown", "-tags", "finance, ap", "-json")
+	if code != exitOK {
+		t.Fatalf("create: code %d", code)
+	}
+	var created struct {
+		ID    string   `json:"id"`
+		Model string   `json:"model"`
+		Tags  []string `json:"tags"`
+	}
+	if err := json.Unmarshal([]byte(out), &created); err != nil {
+		t.Fatalf("create: invalid JSON %q: %v", out, err)
+	}
+	if created.Model != "standard-v2" || !equalStrings(created.Tags, []string{"finance", "ap"}) {
+		t.Errorf("create: unexpected template %+v", created)
+	}
+
+	out, code = runCLI(t, "templates", "update", created.ID, "-description", "Vendor invoices")
+	if code != exitOK || !strings.Contains(out, "Vendor invoices") || !strings.Contains(out, "finance, ap") {
+		t.Errorf("update should keep unset fields: code %d, output %q", code, out)
+	}
+
+	if out, code = runCLI(t, "templates", "delete", created.ID); code != exitOK || out != "deleted "+created.ID+"\n" {
+		t.Errorf("delete: code %d, output %q", code, out)
+	}
+	if _, code = runCLI(t, "templates", "get", created.ID); code != exitAPI {
+		t.Errorf("get after delete: expected exit code %d, got %d", exitAPI, code)
+	}
+	if _, code = runCLI(t, "templates", "create", "-format", "markdown"); code != exitValidation {
+		t.Errorf("create without name: expected exit code %d, got %d", exitValidation, code)
+	}
+}
+
+func TestTemplateSync(t *testing.T) {
+	store := &templateStore{templates: []map[string]any{
+		{"id": "tpl_a", "slug": "invoices", "name": "Invoices", "format": "structured", "model": "standard-v2", "enabled": true,
+			"schema": map[string]any{"type": "object", "properties": map[string]any{"total": map[string]any{"type": "number"}}}},
+		{"id": "tpl_b", "slug": "receipts", "name": "Receipts", "format": "markdown", "model": "standard-v2", "enabled": true},
+		{"id": "tpl_c", "slug": "legacy", "name": "Legacy", "format": "markdown", "model": "standard-v2", "enabled": true},
+		{"id": "tpl_d", "slug": "forms", "name": "Forms", "format": "markdown", "model": "standard-v2", "enabled": true,
+			"description": "Old forms", "instructions": "Be brief", "tags": []any{"old"}},
+	}}
+	server := httptest.NewServer(store.handler())
+	defer server.Close()
+	setTeamEnv(t, server)
+
+	dir := t.TempDir()
+	files := map[string]string{
+		// Unchanged: the schema numbers decode differently from YAML and JSON
+		"invoices.yaml": "slug: invoices\nname: Invoices\nformat: structured\nschema:\n  type: object\n  properties:\n    total:\n      type: number\n",
+		"receipts.json": `{"name": "Receipts", "format": "markdown", "description": "Store receipts"}`,
+		// Fields left out of a definition are cleared
+		"forms.yaml": "name: Forms\nformat: markdown\n",
+		"more.yaml":  "- name: Contracts\n  format: markdown\n  tags: [legal]\n",
+		"README.md":  "not a definition",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out, code := runCLI(t, "templates", "sync", "-prune", "-dry-run", dir)
+	if code != exitOK {
+		t.Fatalf("dry run: code %d", code)
+	}
+	for _, want := range []string{"unchanged  tpl_a", "update     tpl_b", "create", "delete     tpl_c"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("dry run: expected %q in output %q", want, out)
+		}
+	}
+	if len(store.requests) != 0 {
+		t.Fatalf("dry run changed templates: %v", store.requests)
+	}
+
+	if _, code := runCLI(t, "templates", "sync", "-prune", dir); code != exitOK {
+		t.Fatalf("sync: code %d", code)
+	}
+	want := []string{"update tpl_d", "create Contracts", "update tpl_b", "delete tpl_c"}
+	if !equalStrings(store.requests, want) {
+		t.Errorf("expected requests %v, got %v", want, store.requests)
+	}
+
+	out, _ = runCLI(t, "templates", "sync", "-json", dir)
+	if strings.Count(out, `"unchanged"`) != 4 {
+		t.Errorf("second sync should change nothing, got %s", out)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
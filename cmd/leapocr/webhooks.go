@@ -0,0 +1,493 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	ocr "github.com/leapocr/leapocr-go"
+	"github.com/leapocr/leapocr-go/webhook"
+)
+
+// webhookCommands are the subcommands of "leapocr webhooks"
+var webhookCommands = []command{
+	{name: "list", args: "[flags]", summary: "list webhook subscriptions", run: runWebhooksList},
+	{name: "create", args: "[flags]", summary: "create a webhook subscription", run: runWebhooksCreate},
+	{name: "test", args: "[flags] <webhook-id>", summary: "send a test event to a subscription", run: runWebhooksTest},
+	{name: "rotate-secret", args: "[flags] <webhook-id>", summary: "replace the signing secret of a subscription", run: runWebhooksRotateSecret},
+	{name: "events", args: "[flags] <webhook-id>", summary: "list the delivery log of a subscription", run: runWebhooksEvents},
+	{name: "replay", args: "[flags] <webhook-id>", summary: "redeliver logged events, signed with the subscription secret", run: runWebhooksReplay},
+	{name: "listen", args: "[flags]", summary: "receive deliveries locally, print, record and forward them", run: runWebhooksListen},
+}
+
+func runWebhooksList(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("webhooks list", "[flags]")
+	if _, err := c.parse(fs, args, 0); err != nil {
+		return err
+	}
+	sdk, err := c.sdk()
+	if err != nil {
+		return err
+	}
+
+	subscriptions := []ocr.WebhookSubscription{}
+	opts := ocr.WebhookListOptions{}
+	for {
+		page, err := sdk.ListWebhooks(ctx, opts)
+		if err != nil {
+			return err
+		}
+		subscriptions = append(subscriptions, page.Subscriptions...)
+		if !page.HasMore || page.NextCursor == "" {
+			break
+		}
+		opts.Cursor = page.NextCursor
+	}
+	if c.json {
+		return writeJSON(c.stdout, subscriptions)
+	}
+
+	tw := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tURL\tEVENTS\tENABLED\tFAILURES")
+	for _, s := range subscriptions {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%d\n", s.ID, s.URL, strings.Join(s.Events, ","), s.Enabled, s.FailureCount)
+	}
+	return tw.Flush()
+}
+
+func runWebhooksCreate(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("webhooks create", "[flags]")
+	url := fs.String("url", "", "URL receiving the deliveries (required)")
+	events := fs.String("events", "", `comma-separated event types, e.g. "job.completed,job.failed" (required)`)
+	description := fs.String("description", "", "subscription description")
+	disabled := fs.Bool("disabled", false, "create the subscription without enabling deliveries")
+	if _, err := c.parse(fs, args, 0); err != nil {
+		return err
+	}
+	sdk, err := c.sdk()
+	if err != nil {
+		return err
+	}
+
+	params := ocr.WebhookParams{URL: *url, Events: splitList(*events), Description: *description}
+	if *disabled {
+		params.Enabled = new(bool)
+	}
+	subscription, err := sdk.CreateWebhook(ctx, params)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return writeJSON(c.stdout, subscription)
+	}
+
+	fmt.Fprintf(c.stdout, "created %s\n", subscription.ID)
+	if subscription.Secret != "" {
+		fmt.Fprintf(c.stdout, "secret: %s\n", subscription.Secret)
+	}
+	return nil
+}
+
+func runWebhooksTest(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("webhooks test", "[flags] <webhook-id>")
+	positional, err := c.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	sdk, err := c.sdk()
+	if err != nil {
+		return err
+	}
+
+	result, err := sdk.TestWebhook(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return writeJSON(c.stdout, result)
+	}
+
+	fmt.Fprintf(c.stdout, "sent test event to %s\n", positional[0])
+	for _, key := range slices.Sorted(maps.Keys(result)) {
+		fmt.Fprintf(c.stdout, "%s: %v\n", key, result[key])
+	}
+	return nil
+}
+
+func runWebhooksRotateSecret(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("webhooks rotate-secret", "[flags] <webhook-id>")
+	positional, err := c.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	sdk, err := c.sdk()
+	if err != nil {
+		return err
+	}
+
+	rotation, err := sdk.RotateWebhookSecret(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return writeJSON(c.stdout, rotation)
+	}
+
+	fmt.Fprintf(c.stdout, "secret: %s\n", rotation.Secret)
+	if rotation.PreviousSecret != "" {
+		fmt.Fprintf(c.stdout, "previous secret: %s\n", rotation.PreviousSecret)
+	}
+	return nil
+}
+
+func runWebhooksEvents(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("webhooks events", "[flags] <webhook-id>")
+	status := fs.String("status", "", `only list events with this status, e.g. "failed"`)
+	eventType := fs.String("type", "", "only list events of this type")
+	since := fs.Duration("since", 0, "only list events created within this duration, e.g. 24h")
+	limit := fs.Int("limit", 50, "list at most this many events (0: all)")
+	positional, err := c.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	sdk, err := c.sdk()
+	if err != nil {
+		return err
+	}
+
+	events := []ocr.WebhookEvent{}
+	filter := eventFilter(positional[0], *status, *eventType, *since)
+	for event, err := range sdk.WebhookEvents(ctx, filter) {
+		if err != nil {
+			return err
+		}
+		events = append(events, event)
+		if *limit > 0 && len(events) == *limit {
+			break
+		}
+	}
+	if c.json {
+		return writeJSON(c.stdout, events)
+	}
+
+	tw := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tEVENT ID\tTYPE\tSTATUS\tHTTP\tATTEMPTS\tCREATED")
+	for _, e := range events {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%d\t%s\n",
+			e.ID, e.EventID, e.EventType, e.Status, e.HTTPStatus, e.Attempts, e.CreatedAt.Format(time.RFC3339))
+	}
+	return tw.Flush()
+}
+
+// replayResult is the outcome of redelivering one logged event
+type replayResult struct {
+	ID      string `json:"id"`
+	EventID string `json:"event_id"`
+	Type    string `json:"event_type"`
+	Error   string `json:"error,omitempty"`
+}
+
+func runWebhooksReplay(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("webhooks replay", "[flags] <webhook-id>")
+	status := fs.String("status", "failed", `replay events with this status; "" replays all`)
+	eventType := fs.String("type", "", "only replay events of this type")
+	since := fs.Duration("since", 0, "only replay events created within this duration, e.g. 24h")
+	event := fs.String("event", "", "only replay this event, by log entry or event ID")
+	to := fs.String("to", "", "deliver to this URL instead of the subscription URL")
+	secret := fs.String("secret", "", "sign with this secret instead of the subscription secret")
+	enriched := fs.Bool("enriched", false, "send payloads enriched with current job data")
+	positional, err := c.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	sdk, err := c.sdk()
+	if err != nil {
+		return err
+	}
+
+	url, key := *to, *secret
+	if url == "" || key == "" {
+		subscription, err := sdk.GetWebhook(ctx, positional[0])
+		if err != nil {
+			return err
+		}
+		if url == "" {
+			url = subscription.URL
+		}
+		if key == "" {
+			key = subscription.Secret
+		}
+	}
+	if key == "" {
+		return usagef("webhooks replay: the subscription secret is not available, pass -secret")
+	}
+
+	var results []replayResult
+	var failed int
+	filter := eventFilter(positional[0], *status, *eventType, *since)
+	for entry, err := range sdk.WebhookEvents(ctx, filter) {
+		if err != nil {
+			return err
+		}
+		if *event != "" && entry.ID != *event && entry.EventID != *event {
+			continue
+		}
+
+		result := replayResult{ID: entry.ID, EventID: entry.EventID, Type: entry.EventType}
+		if err := replayEvent(ctx, sdk, entry, *enriched, url, key); err != nil {
+			result.Error = err.Error()
+			failed++
+		}
+		results = append(results, result)
+		if !c.json {
+			if result.Error != "" {
+				fmt.Fprintf(c.stdout, "failed\t%s\t%s\t%s\n", result.EventID, result.Type, result.Error)
+			} else {
+				fmt.Fprintf(c.stdout, "replayed\t%s\t%s\n", result.EventID, result.Type)
+			}
+		}
+	}
+
+	if c.json {
+		if err := writeJSON(c.stdout, map[string]any{"url": url, "events": results}); err != nil {
+			return err
+		}
+	} else if len(results) == 0 {
+		fmt.Fprintln(c.stdout, "no events to replay")
+	}
+	if failed > 0 {
+		return ocr.NewSDKError(ocr.ErrorTypeHTTPError, fmt.Sprintf("%d of %d deliveries failed", failed, len(results)), nil)
+	}
+	return nil
+}
+
+// replayEvent redelivers a logged event to url, signed with secret
+func replayEvent(ctx context.Context, sdk *ocr.SDK, entry ocr.WebhookEvent, enriched bool, url, secret string) error {
+	var payload map[string]any
+	if enriched {
+		p, err := sdk.GetWebhookEventPayload(ctx, entry.ID)
+		if err != nil {
+			return err
+		}
+		payload = p.Payload
+	}
+
+	body, err := webhook.EncodeLog(entry, payload)
+	if err != nil {
+		return err
+	}
+	return webhook.Redeliver(ctx, nil, url, secret, body)
+}
+
+// eventFilter selects the delivery log entries of a subscription
+func eventFilter(subscriptionID, status, eventType string, since time.Duration) ocr.WebhookEventFilter {
+	filter := ocr.WebhookEventFilter{SubscriptionID: subscriptionID, Status: status, EventType: eventType}
+	if since > 0 {
+		filter.Since = time.Now().Add(-since)
+	}
+	return filter
+}
+
+func runWebhooksListen(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("webhooks listen", "[flags]")
+	addr := fs.String("addr", "localhost:8787", `address to receive deliveries on; "" disables the server when polling`)
+	secret := fs.String("secret", "", "signing secret of the subscription (default with -poll: the subscription secret)")
+	record := fs.String("o", "", "append verified deliveries to this JSON Lines file")
+	forward := fs.String("forward", "", "forward verified deliveries to this URL, e.g. http://localhost:3000/webhooks")
+	poll := fs.String("poll", "", "also feed new events of this subscription from the delivery log, without a public URL")
+	interval := fs.Duration("poll-interval", 5*time.Second, "how often to check the delivery log")
+	quiet := fs.Bool("quiet", false, "print one line per delivery without the payload")
+	if _, err := c.parse(fs, args, 0); err != nil {
+		return err
+	}
+	if *addr == "" && *poll == "" {
+		return usagef("webhooks listen: -addr can only be empty with -poll")
+	}
+
+	var sdk *ocr.SDK
+	if *poll != "" {
+		var err error
+		if sdk, err = c.sdk(); err != nil {
+			return err
+		}
+		if *secret == "" {
+			subscription, err := sdk.GetWebhook(ctx, *poll)
+			if err != nil {
+				return err
+			}
+			*secret = subscription.Secret
+		}
+	}
+	if *secret == "" {
+		return usagef("webhooks listen: -secret is required")
+	}
+
+	opts := []webhook.ReceiverOption{webhook.WithDeliveryCallback(func(d webhook.Delivery) {
+		c.printDelivery(d, *quiet)
+	})}
+	if *record != "" {
+		file, err := os.OpenFile(*record, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600) // #nosec G304 - path is given on the command line
+		if err != nil {
+			return fmt.Errorf("failed to open record file: %w", err)
+		}
+		defer func() { _ = file.Close() }() //nolint:errcheck
+		opts = append(opts, webhook.WithRecord(file))
+	}
+	if *forward != "" {
+		opts = append(opts, webhook.WithForwardURL(*forward))
+	}
+	receiver := webhook.NewReceiver(&webhook.Verifier{Secrets: []string{*secret}}, opts...)
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	if *addr != "" {
+		listener, err := net.Listen("tcp", *addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen: %w", err)
+		}
+		server := &http.Server{Handler: receiver, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+				cancel(err)
+			}
+		}()
+		defer func() { _ = server.Shutdown(context.Background()) }() //nolint:errcheck
+		fmt.Fprintf(c.stderr, "listening on http://%s\n", listener.Addr())
+	}
+	// The poller may still be delivering when the context ends; wait for it
+	// before the record file is closed and the command returns
+	var poller sync.WaitGroup
+	if *poll != "" {
+		poller.Add(1)
+		go func() {
+			defer poller.Done()
+			if err := pollDeliveries(ctx, sdk, receiver, *poll, *secret, *interval); err != nil {
+				cancel(err)
+			}
+		}()
+		fmt.Fprintf(c.stderr, "polling the delivery log of %s every %s\n", *poll, *interval)
+	}
+
+	<-ctx.Done()
+	poller.Wait()
+	if err := context.Cause(ctx); !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+// pollDeliveries feeds the events logged for a subscription after the start of
+// polling to receiver, signed with secret like a regular delivery
+func pollDeliveries(ctx context.Context, sdk *ocr.SDK, receiver *webhook.Receiver, subscriptionID, secret string, interval time.Duration) error {
+	cursor := newDeliveryCursor(time.Now())
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// The log is listed newest first and paging stops at entries older
+		// than Since, so each tick fetches only the part of the log after the cursor
+		var entries []ocr.WebhookEvent
+		filter := ocr.WebhookEventFilter{SubscriptionID: subscriptionID, Since: cursor.since}
+		for entry, err := range sdk.WebhookEvents(ctx, filter) {
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return err
+			}
+			entries = append(entries, entry)
+		}
+
+		for _, entry := range cursor.advance(entries) {
+			body, err := webhook.EncodeLog(entry, nil)
+			if err != nil {
+				return err
+			}
+			receiver.Receive(ctx, webhook.Sign(secret, time.Now(), body), body)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliveryCursor tracks the position of "webhooks listen -poll" in the delivery log
+type deliveryCursor struct {
+	// since is the creation time of the newest delivery seen so far
+	since time.Time
+	// seen holds the IDs of the deliveries created at since, which the next
+	// page lists again since the filter bound is inclusive
+	seen map[string]bool
+}
+
+func newDeliveryCursor(since time.Time) *deliveryCursor {
+	return &deliveryCursor{since: since, seen: make(map[string]bool)}
+}
+
+// advance returns the entries not returned before, oldest first, and moves
+// the cursor to the newest of them
+func (c *deliveryCursor) advance(entries []ocr.WebhookEvent) []ocr.WebhookEvent {
+	var fresh []ocr.WebhookEvent
+	for _, entry := range entries {
+		if !c.seen[entry.ID] && !entry.CreatedAt.Before(c.since) {
+			fresh = append(fresh, entry)
+		}
+	}
+	// The log lists the newest events first
+	slices.SortStableFunc(fresh, func(a, b ocr.WebhookEvent) int { return a.CreatedAt.Compare(b.CreatedAt) })
+
+	for _, entry := range fresh {
+		if entry.CreatedAt.After(c.since) {
+			c.since = entry.CreatedAt
+			clear(c.seen)
+		}
+		c.seen[entry.ID] = true
+	}
+	return fresh
+}
+
+// printDelivery reports a delivery received by "webhooks listen"; JSON mode
+// writes one JSON object per line
+func (c *cli) printDelivery(d webhook.Delivery, quiet bool) {
+	if c.json {
+		_ = json.NewEncoder(c.stdout).Encode(d) //nolint:errcheck
+		return
+	}
+
+	line := fmt.Sprintf("%s  %s  %s", d.ReceivedAt.Local().Format(time.TimeOnly), d.EventType, d.EventID)
+	switch {
+	case !d.Verified():
+		line = fmt.Sprintf("%s  rejected: %s", d.ReceivedAt.Local().Format(time.TimeOnly), d.Error)
+	case d.ForwardError != "":
+		line += "  -> forward failed: " + d.ForwardError
+	case d.ForwardStatus != 0:
+		line += fmt.Sprintf("  -> %d", d.ForwardStatus)
+	}
+	if d.RecordError != "" {
+		line += "  (not recorded: " + d.RecordError + ")"
+	}
+	fmt.Fprintln(c.stdout, line)
+
+	if quiet || !d.Verified() {
+		return
+	}
+	var body bytes.Buffer
+	if err := json.Indent(&body, d.Body, "    ", "  "); err == nil {
+		fmt.Fprintf(c.stdout, "    %s\n", body.Bytes())
+	}
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ocr "github.com/leapocr/leapocr-go"
+	"github.com/leapocr/leapocr-go/webhook"
+)
+
+func TestWebhookCommands(t *testing.T) {
+	const base = "/organizations/org_1/teams/team_1/webhooks"
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+base, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":[{"id":"wh_1","url":"https://example.com/hook","events":["job.completed"],"enabled":true}],"has_more":false}`)
+	})
+	mux.HandleFunc("POST "+base, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Events []string `json:"events"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body) //nolint:errcheck
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "wh_2", "url": "https://example.com/new", "events": body.Events, "secret": "whsec_new"}) //nolint:errcheck
+	})
+	mux.HandleFunc("GET "+base+"/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":%q,"url":"https://example.com/hook","secret":"whsec_1"}`, r.PathValue("id"))
+	})
+	mux.HandleFunc("POST "+base+"/{id}/test", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"status_code":200,"success":true}`)
+	})
+	mux.HandleFunc("POST "+base+"/{id}/regenerate-secret", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"wh_1","secret":"whsec_2"}`)
+	})
+	mux.HandleFunc("GET "+base+"/events", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":[{"id":"log_1","event_id":"evt_1","event_type":"job.completed","processing_status":"failed","attempts":3}],"has_more":false}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	setTeamEnv(t, server)
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantOut  string
+	}{
+		{name: "list", args: []string{"webhooks", "list"}, wantOut: "https://example.com/hook"},
+		{name: "create", args: []string{"webhooks", "create", "-url", "https://example.com/new", "-events", "job.completed,job.failed"}, wantOut: "secret: whsec_new"},
+		{name: "create json", args: []string{"webhooks", "create", "-json", "-url", "https://example.com/new", "-events", "job.failed"}, wantOut: `"events": [`},
+		{name: "create without events", args: []string{"webhooks", "create", "-url", "https://example.com/new"}, wantCode: exitValidation},
+		{name: "test", args: []string{"webhooks", "test", "wh_1"}, wantOut: "success: true"},
+		{name: "rotate secret", args: []string{"webhooks", "rotate-secret", "wh_1"}, wantOut: "previous secret: whsec_1"},
+		{name: "events", args: []string{"webhooks", "events", "wh_1"}, wantOut: "evt_1"},
+		{name: "unknown subcommand", args: []string{"webhooks", "frobnicate"}, wantCode: exitUsage},
+		{name: "missing subcommand", args: []string{"webhooks"}, wantCode: exitUsage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, code := runCLI(t, tt.args...)
+			if code != tt.wantCode {
+				t.Fatalf("expected exit code %d, got %d", tt.wantCode, code)
+			}
+			if !strings.Contains(out, tt.wantOut) {
+				t.Errorf("expected output to contain %q, got %q", tt.wantOut, out)
+			}
+		})
+	}
+}
+
+func TestWebhookReplay(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body) //nolint:errcheck
+		if err := webhook.Verify("whsec_1", r.Header, body); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		event, err := webhook.Parse(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		received = append(received, event.EventID())
+		mu.Unlock()
+	}))
+	defer receiver.Close()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /organizations/org_1/teams/team_1/webhooks/wh_1", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":"wh_1","url":%q,"secret":"whsec_1"}`, receiver.URL)
+	})
+	mux.HandleFunc("GET /organizations/org_1/teams/team_1/webhooks/events", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("processing_status") != "failed" {
+			http.Error(w, `{"error":"expected failed events"}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":[`+
+			`{"id":"log_1","event_id":"evt_1","event_type":"job.completed","payload":{"job_id":"job_1"},"created_at":"2026-01-02T03:04:05Z"},`+
+			`{"id":"log_2","event_id":"evt_2","event_type":"job.failed","payload":{"job_id":"job_2"},"created_at":"2026-01-02T03:04:06Z"}`+
+			`],"has_more":false}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	setTeamEnv(t, server)
+
+	out, code := runCLI(t, "webhooks", "replay", "wh_1")
+	if code != exitOK {
+		t.Fatalf("replay: code %d, output %q", code, out)
+	}
+	if !equalStrings(received, []string{"evt_1", "evt_2"}) {
+		t.Errorf("expected both events to be delivered, got %v", received)
+	}
+
+	received = nil
+	if _, code := runCLI(t, "webhooks", "replay", "wh_1", "-event", "evt_2"); code != exitOK || !equalStrings(received, []string{"evt_2"}) {
+		t.Errorf("replay -event: code %d, delivered %v", code, received)
+	}
+
+	// A wrong secret fails verification on the receiver
+	if _, code := runCLI(t, "webhooks", "replay", "wh_1", "-secret", "whsec_wrong"); code != exitAPI {
+		t.Errorf("expected exit code %d for rejected deliveries, got %d", exitAPI, code)
+	}
+}
+
+func TestWebhookListen(t *testing.T) {
+	var forwarded atomic.Int32
+	app := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body) //nolint:errcheck
+		if webhook.Verify("whsec_1", r.Header, body) == nil {
+			forwarded.Add(1)
+		}
+	}))
+	defer app.Close()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /organizations/org_1/teams/team_1/webhooks/wh_1", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"wh_1","url":"https://example.com/hook","secret":"whsec_1"}`)
+	})
+	var olderPages atomic.Int32
+	mux.HandleFunc("GET /organizations/org_1/teams/team_1/webhooks/events", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("cursor") != "" {
+			olderPages.Add(1)
+			fmt.Fprint(w, `{"data":[],"has_more":false}`)
+			return
+		}
+		older := time.Now().Add(time.Second).UTC().Format(time.RFC3339)
+		newer := time.Now().Add(2 * time.Second).UTC().Format(time.RFC3339)
+		stale := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+		fmt.Fprintf(w, `{"data":[`+
+			`{"id":"log_2","event_id":"evt_2","event_type":"job.failed","payload":{"job_id":"job_2"},"created_at":%q},`+
+			`{"id":"log_1","event_id":"evt_1","event_type":"job.completed","payload":{"job_id":"job_1"},"created_at":%q},`+
+			`{"id":"log_0","event_id":"evt_0","event_type":"job.completed","payload":{"job_id":"job_0"},"created_at":%q}`+
+			`],"has_more":true,"next_cursor":"page_2"}`, newer, older, stale)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	setTeamEnv(t, server)
+
+	record := filepath.Join(t.TempDir(), "events.jsonl")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		for ctx.Err() == nil {
+			if data, err := os.ReadFile(record); err == nil && bytes.Count(data, []byte("\n")) >= 2 {
+				cancel()
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"webhooks", "listen", "-addr", "", "-poll", "wh_1", "-poll-interval", "10ms", "-o", record, "-forward", app.URL, "-quiet"}
+	if code := run(ctx, args, &stdout, &stderr); code != exitOK {
+		t.Fatalf("listen: code %d, stderr %q", code, stderr.String())
+	}
+
+	data, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 2 || !strings.Contains(lines[0], "evt_1") {
+		t.Errorf("expected both events recorded once, oldest first, got %q", data)
+	}
+	if forwarded.Load() != 2 {
+		t.Errorf("expected 2 verified forwards, got %d", forwarded.Load())
+	}
+	if olderPages.Load() != 0 {
+		t.Errorf("expected polling to stop at entries older than the cursor, fetched %d older pages", olderPages.Load())
+	}
+	if !strings.Contains(stdout.String(), "job.completed  evt_1  -> 200") {
+		t.Errorf("unexpected output %q", stdout.String())
+	}
+}
+
+func TestDeliveryCursor(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	event := func(id string, offset time.Duration) ocr.WebhookEvent {
+		return ocr.WebhookEvent{ID: id, CreatedAt: start.Add(offset)}
+	}
+	ids := func(entries []ocr.WebhookEvent) []string {
+		var out []string
+		for _, e := range entries {
+			out = append(out, e.ID)
+		}
+		return out
+	}
+
+	cursor := newDeliveryCursor(start)
+	got := cursor.advance([]ocr.WebhookEvent{event("b", 2*time.Second), event("a", time.Second), event("old", -time.Second)})
+	if !equalStrings(ids(got), []string{"a", "b"}) || !cursor.since.Equal(start.Add(2*time.Second)) {
+		t.Fatalf("first poll returned %v and moved to %v", ids(got), cursor.since)
+	}
+
+	// The inclusive bound lists b again, next to a delivery created at the same time
+	got = cursor.advance([]ocr.WebhookEvent{event("c", 2*time.Second), event("b", 2*time.Second)})
+	if !equalStrings(ids(got), []string{"c"}) {
+		t.Fatalf("second poll returned %v", ids(got))
+	}
+
+	got = cursor.advance([]ocr.WebhookEvent{event("d", 3*time.Second), event("c", 2*time.Second), event("b", 2*time.Second)})
+	if !equalStrings(ids(got), []string{"d"}) || len(cursor.seen) != 1 {
+		t.Errorf("third poll returned %v and kept %d seen IDs", ids(got), len(cursor.seen))
+	}
+}
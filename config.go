@@ -0,0 +1,372 @@
+package ocr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables read by ConfigFromEnv and LoadConfig
+const (
+	EnvAPIKey                     = "LEAPOCR_API_KEY"
+	EnvBaseURL                    = "LEAPOCR_BASE_URL"
+	EnvProfile                    = "LEAPOCR_PROFILE"
+	EnvOrganizationID             = "LEAPOCR_ORGANIZATION_ID"
+	EnvTeamID                     = "LEAPOCR_TEAM_ID"
+	EnvTimeout                    = "LEAPOCR_TIMEOUT"
+	EnvUploadPartTimeout          = "LEAPOCR_UPLOAD_PART_TIMEOUT"
+	EnvOperationTimeout           = "LEAPOCR_OPERATION_TIMEOUT"
+	EnvRetryMaxAttempts           = "LEAPOCR_RETRY_MAX_ATTEMPTS"
+	EnvRetryInitialDelay          = "LEAPOCR_RETRY_INITIAL_DELAY"
+	EnvRetryMaxDelay              = "LEAPOCR_RETRY_MAX_DELAY"
+	EnvRateLimit                  = "LEAPOCR_RATE_LIMIT"
+	EnvRateLimitBurst             = "LEAPOCR_RATE_LIMIT_BURST"
+	EnvRateLimitMaxInFlight       = "LEAPOCR_RATE_LIMIT_MAX_IN_FLIGHT"
+	EnvRateLimitAdaptive          = "LEAPOCR_RATE_LIMIT_ADAPTIVE"
+	EnvUploadRateLimit            = "LEAPOCR_UPLOAD_RATE_LIMIT"
+	EnvUploadRateLimitBurst       = "LEAPOCR_UPLOAD_RATE_LIMIT_BURST"
+	EnvUploadRateLimitMaxInFlight = "LEAPOCR_UPLOAD_RATE_LIMIT_MAX_IN_FLIGHT"
+	EnvUploadRateLimitAdaptive    = "LEAPOCR_UPLOAD_RATE_LIMIT_ADAPTIVE"
+	EnvFormat                     = "LEAPOCR_FORMAT"
+	EnvModel                      = "LEAPOCR_MODEL"
+	EnvInstructions               = "LEAPOCR_INSTRUCTIONS"
+	EnvTemplateSlug               = "LEAPOCR_TEMPLATE_SLUG"
+)
+
+// ConfigFromEnv returns DefaultConfig with the settings of LEAPOCR_* environment variables applied
+func ConfigFromEnv() (*Config, error) {
+	config := DefaultConfig("")
+	env, err := settingsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if err := env.apply(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// LoadConfig reads a YAML or JSON configuration file (by extension; .json is
+// JSON, anything else YAML) and selects the profile named by LEAPOCR_PROFILE
+// or the file's default_profile. Environment variables override file values.
+func LoadConfig(path string) (*Config, error) {
+	return LoadConfigProfile(path, os.Getenv(EnvProfile))
+}
+
+// LoadConfigProfile is LoadConfig with an explicit profile; an empty profile
+// selects the file's default_profile, if any. Settings at the top level of the
+// file apply to every profile.
+func LoadConfigProfile(path, profile string) (*Config, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - path is chosen by the caller
+	if err != nil {
+		return nil, NewSDKError(ErrorTypeInvalidConfig, "failed to read config file", err)
+	}
+
+	var file configFile
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&file)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(&file)
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil, NewSDKError(ErrorTypeInvalidConfig, fmt.Sprintf("invalid config file %s", path), err)
+	}
+
+	if profile == "" {
+		profile = file.DefaultProfile
+	}
+	layers := []configSettings{file.configSettings}
+	if profile != "" {
+		selected, ok := file.Profiles[profile]
+		if !ok {
+			return nil, NewSDKError(ErrorTypeInvalidConfig, fmt.Sprintf("profile %q not found in %s", profile, path), nil)
+		}
+		layers = append(layers, selected)
+	}
+	env, err := settingsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	layers = append(layers, env)
+
+	config := DefaultConfig("")
+	for _, layer := range layers {
+		if err := layer.apply(config); err != nil {
+			return nil, err
+		}
+	}
+	return config, nil
+}
+
+// configFile is the layout of a configuration file
+type configFile struct {
+	configSettings `yaml:",inline"`
+	DefaultProfile string                    `json:"default_profile" yaml:"default_profile"`
+	Profiles       map[string]configSettings `json:"profiles" yaml:"profiles"`
+}
+
+// configSettings is one layer of configuration; unset fields keep the value of earlier layers
+type configSettings struct {
+	APIKey            *string            `json:"api_key,omitempty" yaml:"api_key"`
+	BaseURL           *string            `json:"base_url,omitempty" yaml:"base_url"`
+	UserAgent         *string            `json:"user_agent,omitempty" yaml:"user_agent"`
+	OrganizationID    *string            `json:"organization_id,omitempty" yaml:"organization_id"`
+	TeamID            *string            `json:"team_id,omitempty" yaml:"team_id"`
+	Timeout           *configDuration    `json:"timeout,omitempty" yaml:"timeout"`
+	UploadPartTimeout *configDuration    `json:"upload_part_timeout,omitempty" yaml:"upload_part_timeout"`
+	OperationTimeout  *configDuration    `json:"operation_timeout,omitempty" yaml:"operation_timeout"`
+	Retry             *retrySettings     `json:"retry,omitempty" yaml:"retry"`
+	RateLimit         *rateLimitSettings `json:"rate_limit,omitempty" yaml:"rate_limit"`
+	UploadRateLimit   *rateLimitSettings `json:"upload_rate_limit,omitempty" yaml:"upload_rate_limit"`
+	Defaults          *defaultSettings   `json:"defaults,omitempty" yaml:"defaults"`
+}
+
+type retrySettings struct {
+	MaxAttempts  *int            `json:"max_attempts,omitempty" yaml:"max_attempts"`
+	InitialDelay *configDuration `json:"initial_delay,omitempty" yaml:"initial_delay"`
+	MaxDelay     *configDuration `json:"max_delay,omitempty" yaml:"max_delay"`
+}
+
+type rateLimitSettings struct {
+	RequestsPerSecond *float64 `json:"requests_per_second,omitempty" yaml:"requests_per_second"`
+	Burst             *int     `json:"burst,omitempty" yaml:"burst"`
+	MaxInFlight       *int     `json:"max_in_flight,omitempty" yaml:"max_in_flight"`
+	Adaptive          *bool    `json:"adaptive,omitempty" yaml:"adaptive"`
+}
+
+type defaultSettings struct {
+	Format       *string `json:"format,omitempty" yaml:"format"`
+	Model        *string `json:"model,omitempty" yaml:"model"`
+	Instructions *string `json:"instructions,omitempty" yaml:"instructions"`
+	TemplateSlug *string `json:"template_slug,omitempty" yaml:"template_slug"`
+}
+
+// configDuration is a duration written as a Go duration string such as "30s"
+type configDuration time.Duration
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *configDuration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = configDuration(parsed)
+	return nil
+}
+
+// apply sets the fields of config present in the layer and validates them
+func (c configSettings) apply(config *Config) error {
+	if c.APIKey != nil {
+		config.APIKey = *c.APIKey
+	}
+	if c.BaseURL != nil {
+		if u, err := url.Parse(*c.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return NewSDKError(ErrorTypeInvalidConfig, fmt.Sprintf("invalid base URL %q: must be an http or https URL", *c.BaseURL), err)
+		}
+		config.BaseURL = strings.TrimRight(*c.BaseURL, "/")
+	}
+	setIfPresent(&config.UserAgent, c.UserAgent)
+	setIfPresent(&config.OrganizationID, c.OrganizationID)
+	setIfPresent(&config.TeamID, c.TeamID)
+	for _, timeout := range []struct {
+		name  string
+		value *configDuration
+		dst   *time.Duration
+	}{
+		{"timeout", c.Timeout, &config.Timeout},
+		{"upload part timeout", c.UploadPartTimeout, &config.UploadPartTimeout},
+		{"operation timeout", c.OperationTimeout, &config.OperationTimeout},
+	} {
+		if timeout.value != nil && *timeout.value < 0 {
+			return NewSDKError(ErrorTypeInvalidConfig, timeout.name+" must not be negative", nil)
+		}
+		setDurationIfPresent(timeout.dst, timeout.value)
+	}
+	if c.Retry != nil {
+		if config.Retry == nil {
+			config.Retry = &RetryPolicy{}
+		}
+		setIfPresent(&config.Retry.MaxAttempts, c.Retry.MaxAttempts)
+		setDurationIfPresent(&config.Retry.InitialDelay, c.Retry.InitialDelay)
+		setDurationIfPresent(&config.Retry.MaxDelay, c.Retry.MaxDelay)
+		if err := validateRetryPolicy(config.Retry); err != nil {
+			return err
+		}
+	}
+	if c.RateLimit != nil {
+		config.RateLimit = c.RateLimit.apply(config.RateLimit)
+		if err := validateRateLimit("rate limit", config.RateLimit); err != nil {
+			return err
+		}
+	}
+	if c.UploadRateLimit != nil {
+		config.UploadRateLimit = c.UploadRateLimit.apply(config.UploadRateLimit)
+		if err := validateRateLimit("upload rate limit", config.UploadRateLimit); err != nil {
+			return err
+		}
+	}
+	if c.Defaults != nil {
+		return c.Defaults.apply(config)
+	}
+	return nil
+}
+
+// apply returns limit, or a new RateLimit when nil, with the layer's fields set
+func (r *rateLimitSettings) apply(limit *RateLimit) *RateLimit {
+	if limit == nil {
+		limit = &RateLimit{}
+	}
+	setIfPresent(&limit.RequestsPerSecond, r.RequestsPerSecond)
+	setIfPresent(&limit.Burst, r.Burst)
+	setIfPresent(&limit.MaxInFlight, r.MaxInFlight)
+	setIfPresent(&limit.Adaptive, r.Adaptive)
+	return limit
+}
+
+// apply appends the layer's processing options to config.DefaultOptions
+func (d *defaultSettings) apply(config *Config) error {
+	if d.Format != nil {
+		format := Format(*d.Format)
+		if format != FormatMarkdown && format != FormatStructured {
+			return NewSDKError(ErrorTypeInvalidConfig, fmt.Sprintf("invalid format %q: must be %q or %q", *d.Format, FormatMarkdown, FormatStructured), nil)
+		}
+		config.DefaultOptions = append(config.DefaultOptions, WithFormat(format))
+	}
+	if d.Model != nil {
+		if *d.Model == "" {
+			return NewSDKError(ErrorTypeInvalidConfig, "model must not be empty", nil)
+		}
+		config.DefaultOptions = append(config.DefaultOptions, WithModelString(*d.Model))
+	}
+	if d.Instructions != nil {
+		config.DefaultOptions = append(config.DefaultOptions, WithInstructions(*d.Instructions))
+	}
+	if d.TemplateSlug != nil {
+		config.DefaultOptions = append(config.DefaultOptions, WithTemplateSlug(*d.TemplateSlug))
+	}
+	return nil
+}
+
+func setIfPresent[T any](dst *T, value *T) {
+	if value != nil {
+		*dst = *value
+	}
+}
+
+func setDurationIfPresent(dst *time.Duration, value *configDuration) {
+	if value != nil {
+		*dst = time.Duration(*value)
+	}
+}
+
+// settingsFromEnv reads a configuration layer from LEAPOCR_* environment variables
+func settingsFromEnv() (configSettings, error) {
+	var (
+		s    configSettings
+		errs []error
+	)
+	str := func(name string) *string {
+		if value, ok := os.LookupEnv(name); ok && value != "" {
+			return &value
+		}
+		return nil
+	}
+	parsed := func(name string, parse func(string) error) bool {
+		value := str(name)
+		if value == nil {
+			return false
+		}
+		if err := parse(*value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			return false
+		}
+		return true
+	}
+	integer := func(name string) *int {
+		var v int
+		if parsed(name, func(s string) (err error) { v, err = strconv.Atoi(s); return err }) {
+			return &v
+		}
+		return nil
+	}
+	float := func(name string) *float64 {
+		var v float64
+		if parsed(name, func(s string) (err error) { v, err = strconv.ParseFloat(s, 64); return err }) {
+			return &v
+		}
+		return nil
+	}
+	boolean := func(name string) *bool {
+		var v bool
+		if parsed(name, func(s string) (err error) { v, err = strconv.ParseBool(s); return err }) {
+			return &v
+		}
+		return nil
+	}
+	duration := func(name string) *configDuration {
+		var v configDuration
+		if parsed(name, func(s string) error { return v.UnmarshalText([]byte(s)) }) {
+			return &v
+		}
+		return nil
+	}
+	rateLimit := func(rps, burst, inFlight, adaptive string) *rateLimitSettings {
+		r := rateLimitSettings{
+			RequestsPerSecond: float(rps),
+			Burst:             integer(burst),
+			MaxInFlight:       integer(inFlight),
+			Adaptive:          boolean(adaptive),
+		}
+		if r == (rateLimitSettings{}) {
+			return nil
+		}
+		return &r
+	}
+
+	s.APIKey = str(EnvAPIKey)
+	s.BaseURL = str(EnvBaseURL)
+	s.OrganizationID = str(EnvOrganizationID)
+	s.TeamID = str(EnvTeamID)
+	s.Timeout = duration(EnvTimeout)
+	s.UploadPartTimeout = duration(EnvUploadPartTimeout)
+	s.OperationTimeout = duration(EnvOperationTimeout)
+	if retry := (retrySettings{
+		MaxAttempts:  integer(EnvRetryMaxAttempts),
+		InitialDelay: duration(EnvRetryInitialDelay),
+		MaxDelay:     duration(EnvRetryMaxDelay),
+	}); retry != (retrySettings{}) {
+		s.Retry = &retry
+	}
+	s.RateLimit = rateLimit(EnvRateLimit, EnvRateLimitBurst, EnvRateLimitMaxInFlight, EnvRateLimitAdaptive)
+	s.UploadRateLimit = rateLimit(EnvUploadRateLimit, EnvUploadRateLimitBurst, EnvUploadRateLimitMaxInFlight, EnvUploadRateLimitAdaptive)
+	if defaults := (defaultSettings{
+		Format:       str(EnvFormat),
+		Model:        str(EnvModel),
+		Instructions: str(EnvInstructions),
+		TemplateSlug: str(EnvTemplateSlug),
+	}); defaults != (defaultSettings{}) {
+		s.Defaults = &defaults
+	}
+
+	if len(errs) > 0 {
+		return configSettings{}, NewSDKError(ErrorTypeInvalidConfig, "invalid environment configuration", errors.Join(errs...))
+	}
+	return s, nil
+}
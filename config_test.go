@@ -0,0 +1,144 @@
+package ocr
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testConfigYAML = `
+base_url: https://api.example.com/
+timeout: 45s
+default_profile: dev
+profiles:
+  dev:
+    api_key: dev-key
+    base_url: http://localhost:8080
+    retry:
+      max_attempts: 5
+  prod:
+    api_key: prod-key
+    rate_limit:
+      requests_per_second: 10
+      adaptive: true
+    defaults:
+      format: markdown
+      model: pro-v2
+`
+
+func writeTestConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := writeTestConfig(t, "leapocr.yaml", testConfigYAML)
+
+	dev, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if dev.APIKey != "dev-key" || dev.BaseURL != "http://localhost:8080" || dev.Timeout != 45*time.Second {
+		t.Errorf("unexpected dev config: %+v", dev)
+	}
+	if dev.Retry == nil || dev.Retry.MaxAttempts != 5 {
+		t.Errorf("expected retry policy with 5 attempts, got %+v", dev.Retry)
+	}
+
+	t.Setenv(EnvProfile, "prod")
+	t.Setenv(EnvAPIKey, "env-key")
+	t.Setenv(EnvModel, "standard-v2")
+	prod, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if prod.APIKey != "env-key" || prod.BaseURL != "https://api.example.com" {
+		t.Errorf("unexpected prod config: %+v", prod)
+	}
+	if prod.RateLimit == nil || prod.RateLimit.RequestsPerSecond != 10 || !prod.RateLimit.Adaptive {
+		t.Errorf("unexpected rate limit: %+v", prod.RateLimit)
+	}
+	options := (&SDK{config: prod}).processingOptions([]ProcessingOption{WithInstructions("x")})
+	if options.format != FormatMarkdown || options.model != "standard-v2" || options.instructions != "x" {
+		t.Errorf("unexpected default options: %+v", options)
+	}
+
+	jsonPath := writeTestConfig(t, "leapocr.json", `{"api_key":"json-key","profiles":{"prod":{"upload_rate_limit":{"max_in_flight":2}}}}`)
+	t.Setenv(EnvAPIKey, "")
+	fromJSON, err := LoadConfig(jsonPath)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if fromJSON.APIKey != "json-key" || fromJSON.UploadRateLimit == nil || fromJSON.UploadRateLimit.MaxInFlight != 2 {
+		t.Errorf("unexpected JSON config: %+v", fromJSON)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		profile string
+	}{
+		{name: "unknown profile", file: "c.yaml", content: testConfigYAML, profile: "staging"},
+		{name: "unknown field", file: "c.yaml", content: "api_key: k\ntimeuot: 5s\n"},
+		{name: "invalid duration", file: "c.yaml", content: "timeout: soon\n"},
+		{name: "invalid format", file: "c.yaml", content: "defaults:\n  format: html\n"},
+		{name: "invalid base URL", file: "c.json", content: `{"base_url":"ftp://example.com"}`},
+		{name: "negative rate limit", file: "c.json", content: `{"rate_limit":{"burst":-1}}`},
+		{name: "malformed JSON", file: "c.json", content: `{"api_key":`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadConfigProfile(writeTestConfig(t, tt.file, tt.content), tt.profile)
+			var sdkErr *SDKError
+			if !errors.As(err, &sdkErr) || sdkErr.Type != ErrorTypeInvalidConfig {
+				t.Errorf("expected invalid config error, got %v", err)
+			}
+		})
+	}
+
+	if _, err := LoadConfig(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv(EnvAPIKey, "env-key")
+	t.Setenv(EnvBaseURL, "https://eu.example.com")
+	t.Setenv(EnvRetryInitialDelay, "250ms")
+	t.Setenv(EnvUploadRateLimitMaxInFlight, "4")
+	t.Setenv(EnvFormat, "markdown")
+
+	config, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatalf("ConfigFromEnv failed: %v", err)
+	}
+	if config.APIKey != "env-key" || config.BaseURL != "https://eu.example.com" || config.Timeout != 30*time.Second {
+		t.Errorf("unexpected config: %+v", config)
+	}
+	if config.Retry == nil || config.Retry.InitialDelay != 250*time.Millisecond {
+		t.Errorf("unexpected retry policy: %+v", config.Retry)
+	}
+	if config.UploadRateLimit == nil || config.UploadRateLimit.MaxInFlight != 4 || config.RateLimit != nil {
+		t.Errorf("unexpected rate limits: %+v %+v", config.RateLimit, config.UploadRateLimit)
+	}
+	if len(config.DefaultOptions) != 1 {
+		t.Errorf("expected one default option, got %d", len(config.DefaultOptions))
+	}
+
+	t.Setenv(EnvRateLimitAdaptive, "maybe")
+	t.Setenv(EnvTimeout, "-")
+	_, err = ConfigFromEnv()
+	var sdkErr *SDKError
+	if !errors.As(err, &sdkErr) || sdkErr.Type != ErrorTypeInvalidConfig {
+		t.Errorf("expected invalid config error, got %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultCredentialsCheckInterval is how often FileCredentials checks its file for changes
+const DefaultCredentialsCheckInterval = time.Second
+
+// apiKeyHeader carries the API key on every API request
+const apiKeyHeader = "X-API-KEY"
+
+// CredentialsProvider supplies the API key. It is consulted for every API
+// request, so rotated keys are picked up without rebuilding the SDK.
+// Implementations must be safe for concurrent use.
+type CredentialsProvider interface {
+	APIKey(ctx context.Context) (string, error)
+}
+
+// StaticCredentials is a fixed API key
+type StaticCredentials string
+
+// APIKey implements CredentialsProvider
+func (c StaticCredentials) APIKey(context.Context) (string, error) {
+	return string(c), nil
+}
+
+// CredentialsFunc adapts a function to CredentialsProvider
+type CredentialsFunc func(ctx context.Context) (string, error)
+
+// APIKey implements CredentialsProvider
+func (f CredentialsFunc) APIKey(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// EnvCredentials reads the API key from an environment variable on every request
+func EnvCredentials(name string) CredentialsProvider {
+	return CredentialsFunc(func(context.Context) (string, error) {
+		key := os.Getenv(name)
+		if key == "" {
+			return "", NewSDKError(ErrorTypeInvalidConfig, "environment variable "+name+" is not set", nil)
+		}
+		return key, nil
+	})
+}
+
+// FileCredentials reads the API key from a file, e.g. a mounted secret, and
+// reloads it when the file changes
+type FileCredentials struct {
+	path     string
+	interval time.Duration
+
+	mu      sync.Mutex
+	key     string
+	modTime time.Time
+	size    int64
+	checked time.Time
+}
+
+// NewFileCredentials reads the API key from path, ignoring surrounding whitespace.
+// The file is checked for changes at most once per checkInterval (default: DefaultCredentialsCheckInterval).
+func NewFileCredentials(path string, checkInterval time.Duration) (*FileCredentials, error) {
+	if checkInterval <= 0 {
+		checkInterval = DefaultCredentialsCheckInterval
+	}
+	c := &FileCredentials{path: path, interval: checkInterval}
+	if err := c.reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// APIKey implements CredentialsProvider. When the file cannot be read, e.g.
+// while it is being replaced, the previously loaded key is returned.
+func (c *FileCredentials) APIKey(context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Since(c.checked) >= c.interval {
+		_ = c.reloadLocked() //nolint:errcheck // keep serving the last good key
+	}
+	return c.key, nil
+}
+
+func (c *FileCredentials) reload() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.reloadLocked()
+}
+
+// reloadLocked rereads the file if it changed; callers must hold c.mu
+func (c *FileCredentials) reloadLocked() error {
+	c.checked = time.Now()
+
+	info, err := os.Stat(c.path)
+	if err != nil {
+		return NewSDKError(ErrorTypeInvalidConfig, "failed to read credentials file", err)
+	}
+	if c.key != "" && info.ModTime().Equal(c.modTime) && info.Size() == c.size {
+		return nil
+	}
+
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return NewSDKError(ErrorTypeInvalidConfig, "failed to read credentials file", err)
+	}
+	key := string(bytes.TrimSpace(data))
+	if key == "" {
+		return NewSDKError(ErrorTypeInvalidConfig, "credentials file is empty", nil)
+	}
+
+	c.key, c.modTime, c.size = key, info.ModTime(), info.Size()
+	return nil
+}
+
+// secretSet remembers recently used API keys so that logs can redact them
+type secretSet struct {
+	mu      sync.Mutex
+	secrets []string
+}
+
+// maxRememberedSecrets bounds secretSet across many rotations
+const maxRememberedSecrets = 8
+
+// newSecretSet returns a set holding the given secrets
+func newSecretSet(secrets ...string) *secretSet {
+	s := &secretSet{}
+	for _, secret := range secrets {
+		s.add(secret)
+	}
+	return s
+}
+
+func (s *secretSet) add(secret string) {
+	if s == nil || secret == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if slices.Contains(s.secrets, secret) {
+		return
+	}
+	s.secrets = append(s.secrets, secret)
+	if len(s.secrets) > maxRememberedSecrets {
+		s.secrets = s.secrets[1:]
+	}
+}
+
+func (s *secretSet) list() []string {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.secrets)
+}
+
+// credentialsTransport sets the API key from a CredentialsProvider on every request
+type credentialsTransport struct {
+	base     http.RoundTripper
+	provider CredentialsProvider
+	secrets  *secretSet
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *credentialsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key, err := t.provider.APIKey(req.Context())
+	if err != nil {
+		return nil, NewSDKError(ErrorTypeInvalidConfig, "failed to obtain API key", err)
+	}
+	if key == "" {
+		return nil, NewSDKError(ErrorTypeInvalidConfig, "API key is required", nil)
+	}
+	t.secrets.add(key)
+
+	req = req.Clone(req.Context())
+	req.Header.Set(apiKeyHeader, key)
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,100 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCredentialsRotation(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "api-key")
+	if err := os.WriteFile(keyFile, []byte("key-one\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	credentials, err := NewFileCredentials(keyFile, time.Nanosecond)
+	if err != nil {
+		t.Fatalf("NewFileCredentials failed: %v", err)
+	}
+
+	var mu sync.Mutex
+	var seen []string
+	record := func(r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		seen = append(seen, r.Header.Get("X-API-KEY"))
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /ocr/status/{id}", func(w http.ResponseWriter, r *http.Request) {
+		record(r)
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":"invalid key `+r.Header.Get("X-API-KEY")+`"}`)
+	})
+	mux.HandleFunc("DELETE /ocr/delete/{id}", func(w http.ResponseWriter, r *http.Request) {
+		record(r)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	var buf bytes.Buffer
+	sdk := newTestTeamSDKWithConfig(t, mux, func(c *Config) {
+		c.APIKey = ""
+		c.Credentials = credentials
+		c.Logger = slog.New(slog.NewTextHandler(&buf, nil))
+	})
+	ctx := context.Background()
+
+	_, _ = sdk.GetJobStatus(ctx, "job_1") //nolint:errcheck
+	if err := sdk.DeleteJob(ctx, "job_1"); err != nil {
+		t.Fatalf("DeleteJob failed: %v", err)
+	}
+
+	// Replacing the file rotates the key without rebuilding the SDK
+	if err := os.WriteFile(keyFile, []byte("key-two-rotated\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = sdk.GetJobStatus(ctx, "job_1") //nolint:errcheck
+	if err := sdk.DeleteJob(ctx, "job_1"); err != nil {
+		t.Fatalf("DeleteJob failed: %v", err)
+	}
+
+	want := []string{"key-one", "key-one", "key-two-rotated", "key-two-rotated"}
+	if !equalStrings(seen, want) {
+		t.Errorf("expected keys %v, got %v", want, seen)
+	}
+	if out := buf.String(); strings.Contains(out, "key-one") || strings.Contains(out, "key-two-rotated") {
+		t.Errorf("log output contains an API key:\n%s", out)
+	}
+}
+
+func TestCredentialsErrors(t *testing.T) {
+	if _, err := NewSDK(&Config{BaseURL: "http://localhost"}); err == nil {
+		t.Error("expected error without API key or credentials")
+	}
+
+	sdk := newTestTeamSDKWithConfig(t, http.NotFoundHandler(), func(c *Config) {
+		c.Credentials = CredentialsFunc(func(context.Context) (string, error) {
+			return "", errors.New("vault sealed")
+		})
+	})
+	_, err := sdk.GetJobStatus(context.Background(), "job_1")
+	var sdkErr *SDKError
+	if !errors.As(err, &sdkErr) || sdkErr.Type != ErrorTypeInvalidConfig {
+		t.Errorf("expected invalid config error, got %v", err)
+	}
+
+	t.Setenv("LEAPOCR_TEST_KEY", "")
+	if _, err := EnvCredentials("LEAPOCR_TEST_KEY").APIKey(context.Background()); err == nil {
+		t.Error("expected error for unset environment variable")
+	}
+	if _, err := NewFileCredentials(filepath.Join(t.TempDir(), "missing"), 0); err == nil {
+		t.Error("expected error for missing credentials file")
+	}
+}
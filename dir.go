@@ -0,0 +1,303 @@
+package ocr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"iter"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DirOptions configures ProcessDir and ProcessDirectory
+type DirOptions struct {
+	// Include lists glob patterns (path.Match syntax) a file must match to be
+	// processed. Patterns containing a slash are matched against the path
+	// relative to the root, others against the file name.
+	// Default: every supported file extension.
+	Include []string
+	// Exclude lists glob patterns of files and directories to ignore
+	Exclude []string
+	// Recursive descends into subdirectories
+	Recursive bool
+	// OutputDir receives one result file per input, mirroring the input layout.
+	// Results are written as .md for FormatMarkdown and as .json otherwise.
+	OutputDir string
+	// Overwrite reprocesses files whose output already exists instead of skipping them
+	Overwrite bool
+	// Batch configures concurrency, processing options, waiting and journaling
+	Batch BatchOptions
+	// OnFile, if set, is called with each file's outcome as it finishes
+	OnFile func(DirFileResult)
+}
+
+// DirFileResult is the outcome of a single file of a directory run
+type DirFileResult struct {
+	// Path is the slash-separated input path relative to the root
+	Path string
+	// OutputPath is the file the result is written to
+	OutputPath string
+	JobID      string
+	Result     *OCRResult
+	Err        error
+	// Skipped is set when the output already existed or, with a batch journal,
+	// when the file was processed in a previous run
+	Skipped bool
+}
+
+// DirSummary aggregates the outcome of a directory run
+type DirSummary struct {
+	// Files holds one entry per matched file in walk order
+	Files     []DirFileResult
+	Processed int
+	Failed    int
+	Skipped   int
+	Credits   int
+	Duration  time.Duration
+}
+
+// ProcessDirectory processes the files of a local directory matching opts,
+// writing results next to the inputs unless opts.OutputDir is set
+func (s *SDK) ProcessDirectory(ctx context.Context, dir string, opts DirOptions) (*DirSummary, error) {
+	if opts.OutputDir == "" {
+		opts.OutputDir = dir
+	}
+	return s.ProcessDir(ctx, os.DirFS(dir), opts)
+}
+
+// ProcessDir processes the files of fsys matching opts and writes each result
+// to opts.OutputDir. Per-file failures are reported in the summary; the
+// returned error is only set when the run could not start.
+func (s *SDK) ProcessDir(ctx context.Context, fsys fs.FS, opts DirOptions) (*DirSummary, error) {
+	ext := outputExtension(s.processingOptions(opts.Batch.Options))
+	return processDir(fsys, opts, ext, func(items []BatchItem) iter.Seq[BatchResult] {
+		batch := s.Batch(ctx, items, opts.Batch)
+		return func(yield func(BatchResult) bool) {
+			for res := range batch.Results() {
+				if !yield(res) {
+					return
+				}
+			}
+		}
+	})
+}
+
+// ProcessDirWith is ProcessDir for implementations of Client other than *SDK:
+// process runs the items of the files to process and yields one result per
+// item, and ProcessDirWith selects the files and writes their outputs
+func ProcessDirWith(fsys fs.FS, opts DirOptions, process func(items []BatchItem) iter.Seq[BatchResult]) (*DirSummary, error) {
+	return processDir(fsys, opts, outputExtension(applyProcessingOptions(opts.Batch.Options)), process)
+}
+
+// processDir processes the files of fsys matching opts with process, writing
+// results with the extension ext
+func processDir(fsys fs.FS, opts DirOptions, ext string, process func(items []BatchItem) iter.Seq[BatchResult]) (*DirSummary, error) {
+	started := time.Now()
+
+	if opts.OutputDir == "" {
+		return nil, NewSDKError(ErrorTypeInvalidConfig, "output directory is required", nil)
+	}
+	if err := validatePatterns(opts.Include, opts.Exclude); err != nil {
+		return nil, err
+	}
+
+	paths, err := matchFiles(fsys, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &DirSummary{Files: make([]DirFileResult, len(paths))}
+
+	var items []BatchItem
+	var indexes []int
+	// sources maps output paths to the file they are written for
+	sources := make(map[string]string, len(paths))
+	for i, p := range paths {
+		file := DirFileResult{
+			Path:       p,
+			OutputPath: filepath.Join(opts.OutputDir, filepath.FromSlash(strings.TrimSuffix(p, path.Ext(p))+ext)),
+		}
+
+		// Files differing only in their extension, like scan.pdf and scan.png, share an output
+		if other, ok := sources[file.OutputPath]; ok {
+			file.Err = NewSDKError(ErrorTypeValidationError,
+				fmt.Sprintf("output %s of %s is already written for %s", file.OutputPath, p, other), nil)
+			summary.Failed++
+			summary.Files[i] = file
+			if opts.OnFile != nil {
+				opts.OnFile(file)
+			}
+			continue
+		}
+		sources[file.OutputPath] = p
+
+		if !opts.Overwrite {
+			if _, err := os.Stat(file.OutputPath); err == nil {
+				file.Skipped = true
+				summary.Skipped++
+				summary.Files[i] = file
+				if opts.OnFile != nil {
+					opts.OnFile(file)
+				}
+				continue
+			}
+		}
+
+		summary.Files[i] = file
+		items = append(items, BatchItem{Key: p, Path: p, FS: fsys})
+		indexes = append(indexes, i)
+	}
+
+	for res := range process(items) {
+		file := &summary.Files[indexes[res.Index]]
+		file.JobID = res.JobID
+		file.Result = res.Result
+		file.Err = res.Err
+		file.Skipped = res.Skipped
+
+		// Results of files completed in a previous run are written again too,
+		// since that run may have stopped after journaling but before writing
+		if file.Err == nil && res.Result != nil {
+			file.Err = writeDirOutput(file.OutputPath, res.Result, ext)
+		}
+
+		switch {
+		case file.Err != nil:
+			summary.Failed++
+		case res.Skipped:
+			// Finished in a previous run according to the batch journal
+			summary.Skipped++
+		default:
+			summary.Processed++
+			if res.Result != nil && !res.Result.Cached {
+				summary.Credits += res.Result.Credits
+			}
+		}
+
+		if opts.OnFile != nil {
+			opts.OnFile(*file)
+		}
+	}
+
+	summary.Duration = time.Since(started)
+	return summary, nil
+}
+
+// matchFiles walks fsys and returns the paths of the files selected by opts
+func matchFiles(fsys fs.FS, opts DirOptions) ([]string, error) {
+	var paths []string
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == "." {
+			return nil
+		}
+
+		if d.IsDir() {
+			if !opts.Recursive || matchesAny(opts.Exclude, p) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if !d.Type().IsRegular() || matchesAny(opts.Exclude, p) {
+			return nil
+		}
+		if len(opts.Include) == 0 {
+			if ValidateFileExtension(p) != nil {
+				return nil
+			}
+		} else if !matchesAny(opts.Include, p) {
+			return nil
+		}
+
+		paths = append(paths, p)
+		return nil
+	})
+	if err != nil {
+		return nil, NewSDKError(ErrorTypeValidationError, "failed to read input directory", err)
+	}
+	return paths, nil
+}
+
+func validatePatterns(patterns ...[]string) error {
+	for _, list := range patterns {
+		for _, pattern := range list {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return NewValidationError("pattern", "invalid glob pattern: "+pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// matchesAny reports whether the slash-separated path p matches one of the patterns
+func matchesAny(patterns []string, p string) bool {
+	for _, pattern := range patterns {
+		name := p
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(p)
+		}
+		if ok, _ := path.Match(pattern, name); ok { //nolint:errcheck // patterns are validated upfront
+			return true
+		}
+	}
+	return false
+}
+
+// outputExtension returns the extension of result files for the processing configuration
+func outputExtension(config *processingConfig) string {
+	if config.format == FormatMarkdown && !config.templateSlugSet {
+		return ".md"
+	}
+	return ".json"
+}
+
+// writeDirOutput writes the text of markdown results or the extracted data of structured results
+func writeDirOutput(outputPath string, result *OCRResult, ext string) error {
+	data := []byte(result.Text)
+	if ext == ".json" {
+		var err error
+		data, err = json.MarshalIndent(result.Data, "", "  ")
+		if err != nil {
+			return NewSDKError(ErrorTypeUnknown, "failed to encode result", err)
+		}
+		data = append(data, '\n')
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o750); err != nil {
+		return NewSDKError(ErrorTypeUnknown, "failed to create output directory", err)
+	}
+	if err := writeFileAtomic(outputPath, data, 0o644); err != nil {
+		return NewSDKError(ErrorTypeUnknown, "failed to write result", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file and renames it into place,
+// so readers never observe a partially written file
+func writeFileAtomic(name string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }() //nolint:errcheck
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close() //nolint:errcheck
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		_ = tmp.Close() //nolint:errcheck
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
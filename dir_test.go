@@ -0,0 +1,172 @@
+package ocr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+)
+
+func TestProcessDir(t *testing.T) {
+	var uploads atomic.Int32
+	sdk := newTestTeamSDK(t, newFileProcessingMux(&uploads))
+
+	fsys := fstest.MapFS{
+		"a.pdf":              {Data: []byte("%PDF a")},
+		"notes.txt":          {Data: []byte("not a document")},
+		"scans/b.pdf":        {Data: []byte("%PDF b")},
+		"scans/draft_c.pdf":  {Data: []byte("%PDF c")},
+		"scans/done.pdf":     {Data: []byte("%PDF done")},
+		"archive/old.pdf":    {Data: []byte("%PDF old")},
+		"scans/deep/d.pdf":   {Data: []byte("%PDF d")},
+		"scans/deep/e.pdf":   {Data: []byte("%PDF e")},
+		"scans/deep/keep.md": {Data: []byte("# keep")},
+	}
+
+	tests := []struct {
+		name          string
+		opts          DirOptions
+		existing      []string
+		wantProcessed []string
+		wantSkipped   []string
+	}{
+		{
+			name:          "top level only",
+			wantProcessed: []string{"a.pdf"},
+		},
+		{
+			name:          "recursive with excludes",
+			opts:          DirOptions{Recursive: true, Exclude: []string{"archive", "draft_*", "scans/deep/e.*"}},
+			existing:      []string{"scans/done.md"},
+			wantProcessed: []string{"a.pdf", "scans/b.pdf", "scans/deep/d.pdf"},
+			wantSkipped:   []string{"scans/done.pdf"},
+		},
+		{
+			name:          "include patterns",
+			opts:          DirOptions{Recursive: true, Include: []string{"scans/d*.pdf"}},
+			wantProcessed: []string{"scans/done.pdf", "scans/draft_c.pdf"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := t.TempDir()
+			for _, name := range tt.existing {
+				path := filepath.Join(out, filepath.FromSlash(name))
+				_ = os.MkdirAll(filepath.Dir(path), 0o750)
+				if err := os.WriteFile(path, []byte("old"), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			opts := tt.opts
+			opts.OutputDir = out
+			opts.Batch.Options = []ProcessingOption{WithFormat(FormatMarkdown)}
+			opts.Batch.WaitOptions = WaitOptions{InitialDelay: 1, MaxJitter: 1}
+
+			summary, err := sdk.ProcessDir(context.Background(), fsys, opts)
+			if err != nil {
+				t.Fatalf("ProcessDir failed: %v", err)
+			}
+
+			var processed, skipped []string
+			for _, file := range summary.Files {
+				switch {
+				case file.Err != nil:
+					t.Errorf("%s failed: %v", file.Path, file.Err)
+				case file.Skipped:
+					skipped = append(skipped, file.Path)
+				default:
+					processed = append(processed, file.Path)
+					data, err := os.ReadFile(file.OutputPath)
+					if err != nil || string(data) != "# "+file.JobID+"\n" {
+						t.Errorf("unexpected output for %s: %q, %v", file.Path, data, err)
+					}
+				}
+			}
+			sort.Strings(processed)
+			if !equalStrings(processed, tt.wantProcessed) || !equalStrings(skipped, tt.wantSkipped) {
+				t.Errorf("processed %v, skipped %v; want %v, %v", processed, skipped, tt.wantProcessed, tt.wantSkipped)
+			}
+			if summary.Processed != len(tt.wantProcessed) || summary.Skipped != len(tt.wantSkipped) || summary.Credits != 2*len(tt.wantProcessed) {
+				t.Errorf("unexpected summary counts: %+v", summary)
+			}
+		})
+	}
+
+	if _, err := sdk.ProcessDir(context.Background(), fsys, DirOptions{}); err == nil {
+		t.Error("expected error without output directory")
+	}
+	if _, err := sdk.ProcessDir(context.Background(), fsys, DirOptions{OutputDir: t.TempDir(), Include: []string{"["}}); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
+
+func TestProcessDirOutputs(t *testing.T) {
+	var uploads atomic.Int32
+	sdk := newTestTeamSDK(t, newFileProcessingMux(&uploads))
+	fsys := fstest.MapFS{
+		"done.pdf": {Data: []byte("%PDF done")},
+		"scan.pdf": {Data: []byte("%PDF scan")},
+		"scan.png": {Data: []byte("png scan")},
+	}
+
+	// The previous run journaled done.pdf as completed but stopped before writing its output
+	journal, err := OpenFileJournal(filepath.Join(t.TempDir(), "dir.journal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer journal.Close()
+	if err := journal.Record(context.Background(), JournalEntry{Key: "done.pdf", State: JournalCompleted, JobID: "old_1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	out := t.TempDir()
+	summary, err := sdk.ProcessDir(context.Background(), fsys, DirOptions{
+		Include:   []string{"*.p*"},
+		OutputDir: out,
+		Batch: BatchOptions{
+			Options:     []ProcessingOption{WithFormat(FormatMarkdown)},
+			WaitOptions: WaitOptions{InitialDelay: 1, MaxJitter: 1},
+			Journal:     journal,
+		},
+	})
+	if err != nil {
+		t.Fatalf("ProcessDir failed: %v", err)
+	}
+
+	files := make(map[string]DirFileResult)
+	for _, file := range summary.Files {
+		files[file.Path] = file
+	}
+	if f := files["done.pdf"]; !f.Skipped || f.Err != nil {
+		t.Errorf("expected done.pdf to be skipped, got %+v", f)
+	}
+	if data, err := os.ReadFile(filepath.Join(out, "done.md")); err != nil || string(data) != "# old_1\n" {
+		t.Errorf("expected the output of done.pdf to be written from its previous job, got %q, %v", data, err)
+	}
+	if f := files["scan.pdf"]; f.Err != nil || f.Skipped {
+		t.Errorf("expected scan.pdf to be processed, got %+v", f)
+	}
+	if f := files["scan.png"]; errorTypeOf(f.Err) != ErrorTypeValidationError {
+		t.Errorf("expected scan.png to fail for sharing the output of scan.pdf, got %+v", f)
+	}
+	if summary.Processed != 1 || summary.Skipped != 1 || summary.Failed != 1 || uploads.Load() != 1 {
+		t.Errorf("unexpected summary: %+v with %d uploads", summary, uploads.Load())
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -1,6 +1,7 @@
 package ocr
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 )
@@ -115,3 +116,12 @@ func (e *SDKError) IsRetryable() bool {
 		return false
 	}
 }
+
+// errorTypeOf returns the type of an SDKError in err's chain, or ErrorTypeUnknown
+func errorTypeOf(err error) ErrorType {
+	var sdkErr *SDKError
+	if errors.As(err, &sdkErr) {
+		return sdkErr.Type
+	}
+	return ErrorTypeUnknown
+}
@@ -5,3 +5,5 @@ go 1.25.1
 replace github.com/leapocr/leapocr-go => ../..
 
 require github.com/leapocr/leapocr-go v0.0.0-00010101000000-000000000000
+
+require gopkg.in/validator.v2 v2.0.1 // indirect
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"sync"
 	"time"
 
 	ocr "github.com/leapocr/leapocr-go"
@@ -84,110 +83,60 @@ func batchProcessingExample(apiKey string) error {
 	}
 
 	// Example files to process (replace with real files or URLs)
-	files := []string{
-		"https://example.com/document1.pdf",
-		"https://example.com/document2.pdf",
-		"https://example.com/document3.pdf",
+	items := []ocr.BatchItem{
+		ocr.URLItem("https://example.com/document1.pdf"),
+		ocr.URLItem("https://example.com/document2.pdf"),
+		ocr.URLItem("https://example.com/document3.pdf"),
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
 	defer cancel()
 
-	// Process files concurrently
-	var wg sync.WaitGroup
-	results := make(chan *ocr.OCRResult, len(files))
-	errors := make(chan error, len(files))
-
-	for i, fileURL := range files {
-		wg.Add(1)
-		go func(idx int, url string) {
-			defer wg.Done()
+	// Options shared by every item; per-item options can be passed to URLItem/FileItem
+	batch := sdk.Batch(ctx, items, ocr.BatchOptions{
+		SubmitConcurrency: 2,
+		WaitConcurrency:   8,
+		Options: []ocr.ProcessingOption{
+			ocr.WithFormat(ocr.FormatStructured),
+			ocr.WithModel(ocr.ModelStandardV2),
+			ocr.WithSchema(map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"text": map[string]interface{}{"type": "string"},
+				},
+				"required": []interface{}{"text"},
+			}),
+		},
+		WaitOptions: ocr.WaitOptions{
+			InitialDelay: 2 * time.Second,
+			MaxDelay:     30 * time.Second,
+			Multiplier:   2.0,
+			MaxJitter:    5 * time.Second,
+			MaxAttempts:  50,
+		},
+	})
 
-			fmt.Printf("Starting processing for file %d: %s\n", idx+1, url)
+	// Results stream in as soon as each document finishes
+	for result := range batch.Results() {
+		if result.Err != nil {
+			fmt.Printf("[FAILED] %s: %v\n", result.Key, result.Err)
+			continue
+		}
 
-			// Start processing
-			job, err := sdk.ProcessURL(ctx, url,
-				ocr.WithFormat(ocr.FormatStructured),
-				ocr.WithModel(ocr.ModelStandardV2),
-				ocr.WithInstructions(fmt.Sprintf("Process document %d", idx+1)),
-				ocr.WithSchema(map[string]interface{}{
-					"type": "object",
-					"properties": map[string]interface{}{
-						"text": map[string]interface{}{"type": "string"},
-					},
-					"required": []interface{}{"text"},
-				}),
-			)
-			if err != nil {
-				errors <- fmt.Errorf("failed to start processing file %d: %w", idx+1, err)
-				return
-			}
-
-			// Wait for completion with custom options
-			waitOpts := ocr.WaitOptions{
-				InitialDelay: 2 * time.Second,
-				MaxDelay:     30 * time.Second,
-				Multiplier:   2.0,
-				MaxJitter:    5 * time.Second,
-				MaxAttempts:  50,
-			}
-
-			result, err := sdk.WaitUntilDoneWithOptions(ctx, job.ID, waitOpts)
-			if err != nil {
-				errors <- fmt.Errorf("failed to complete processing file %d: %w", idx+1, err)
-				return
-			}
-
-			fmt.Printf("Completed processing file %d (Job ID: %s)\n", idx+1, job.ID)
-
-			// Optional: Delete the job after processing
-			if err := sdk.DeleteJob(ctx, job.ID); err != nil {
-				fmt.Printf("Warning: Failed to delete job %s: %v\n", job.ID, err)
-			}
-
-			results <- result
-		}(i, fileURL)
-	}
+		fmt.Printf("[SUCCESS] %s (Job ID: %s) - Credits: %d, Pages: %d\n",
+			result.Key, result.JobID, result.Result.Credits, len(result.Result.Pages))
 
-	// Close channels when all goroutines finish
-	go func() {
-		wg.Wait()
-		close(results)
-		close(errors)
-	}()
-
-	// Collect results
-	var successCount int
-	var totalCredits int
-
-	// Process results and errors
-	for results != nil || errors != nil {
-		select {
-		case result, ok := <-results:
-			if !ok {
-				results = nil
-				continue
-			}
-			successCount++
-			totalCredits += result.Credits
-			fmt.Printf("[SUCCESS] Processing completed - Credits: %d, Pages: %d\n",
-				result.Credits, len(result.Pages))
-
-		case err, ok := <-errors:
-			if !ok {
-				errors = nil
-				continue
-			}
-			fmt.Printf("[FAILED] Processing failed: %v\n", err)
-
-		case <-ctx.Done():
-			return fmt.Errorf("batch processing timed out: %w", ctx.Err())
+		// Optional: Delete the job after processing
+		if err := sdk.DeleteJob(ctx, result.JobID); err != nil {
+			fmt.Printf("Warning: Failed to delete job %s: %v\n", result.JobID, err)
 		}
 	}
 
+	summary := batch.Wait()
 	fmt.Printf("\nBatch processing complete:\n")
-	fmt.Printf("  Successfully processed: %d/%d files\n", successCount, len(files))
-	fmt.Printf("  Total credits used: %d\n", totalCredits)
+	fmt.Printf("  Successfully processed: %d/%d files\n", summary.Succeeded, summary.Total)
+	fmt.Printf("  Total credits used: %d\n", summary.Credits)
+	fmt.Printf("  Duration: %s\n", summary.Duration.Round(time.Second))
 	fmt.Println()
 
 	return nil
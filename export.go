@@ -0,0 +1,81 @@
+package ocr
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// textLine is a line of a block with its estimated location. The API locates
+// blocks only, so lines divide the height of their block evenly and words
+// divide the width of their line in proportion to their length.
+type textLine struct {
+	Text   string
+	Bounds BoundingBox
+	Words  []textWord
+}
+
+// textWord is a word of a textLine
+type textWord struct {
+	Text   string
+	Bounds BoundingBox
+}
+
+// blockLines splits the text of block into located lines and words; blank lines are skipped
+func blockLines(block Block) []textLine {
+	var lines [][]rune
+	for line := range strings.SplitSeq(block.Text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, []rune(line))
+		}
+	}
+	longest := 0
+	for _, line := range lines {
+		longest = max(longest, len(line))
+	}
+
+	b := block.Bounds
+	located := make([]textLine, 0, len(lines))
+	for i, line := range lines {
+		top := b.Y + b.Height*i/len(lines)
+		bottom := b.Y + b.Height*(i+1)/len(lines)
+		// The longest line spans the block; shorter lines are narrower
+		width := b.Width * len(line) / longest
+		l := textLine{Text: string(line), Bounds: BoundingBox{X: b.X, Y: top, Width: width, Height: bottom - top}}
+
+		start := -1
+		for j := 0; j <= len(line); j++ {
+			if j < len(line) && !unicode.IsSpace(line[j]) {
+				if start < 0 {
+					start = j
+				}
+				continue
+			}
+			if start >= 0 {
+				left := b.X + width*start/len(line)
+				right := b.X + width*j/len(line)
+				l.Words = append(l.Words, textWord{
+					Text:   string(line[start:j]),
+					Bounds: BoundingBox{X: left, Y: top, Width: right - left, Height: bottom - top},
+				})
+				start = -1
+			}
+		}
+		located = append(located, l)
+	}
+	return located
+}
+
+// checkLayout returns a validation error unless every page of result has dimensions
+func checkLayout(result *OCRResult) error {
+	if result == nil {
+		return NewSDKError(ErrorTypeValidationError, "result is required", nil)
+	}
+	for _, page := range result.Pages {
+		if page.Width <= 0 || page.Height <= 0 {
+			return NewSDKError(ErrorTypeValidationError,
+				fmt.Sprintf("page %d has no dimensions; process the document with bounding box extraction enabled", page.PageNumber), nil)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,353 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// xmlNode is a parsed XML element, for checking exported documents
+type xmlNode struct {
+	Name     xml.Name
+	Attrs    map[string]string
+	Text     string
+	Children []*xmlNode
+}
+
+func parseXML(t *testing.T, data []byte) *xmlNode {
+	t.Helper()
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var stack []*xmlNode
+	var root *xmlNode
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("malformed XML: %v\n%s", err, data)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			node := &xmlNode{Name: tok.Name, Attrs: make(map[string]string)}
+			for _, a := range tok.Attr {
+				node.Attrs[a.Name.Local] = a.Value
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, node)
+			} else {
+				root = node
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].Text += strings.TrimSpace(string(tok))
+			}
+		}
+	}
+	return root
+}
+
+// box is a rectangle given by its corners
+type box struct{ x0, y0, x1, y1 int }
+
+func (b box) contains(o box) bool {
+	return o.x0 >= b.x0 && o.y0 >= b.y0 && o.x1 <= b.x1 && o.y1 <= b.y1
+}
+
+var hocrBBoxPattern = regexp.MustCompile(`(?:^|; )bbox (\d+) (\d+) (\d+) (\d+)(?:;|$)`)
+
+// hocrChildren are the classes allowed as children of each hOCR class, following the hOCR 1.2 specification
+var hocrChildren = map[string][]string{
+	"ocr_page":    {"ocr_carea", "ocr_photo"},
+	"ocr_carea":   {"ocr_par"},
+	"ocr_par":     {"ocr_line", "ocr_header", "ocr_caption"},
+	"ocr_line":    {"ocrx_word"},
+	"ocr_header":  {"ocrx_word"},
+	"ocr_caption": {"ocrx_word"},
+	"ocrx_word":   nil,
+	"ocr_photo":   nil,
+}
+
+// checkHOCR checks the rules of the hOCR specification used by WriteHOCR: the
+// declared capabilities, element nesting, required properties, unique IDs and
+// bounding boxes enclosed by those of their parents. It returns the words.
+func checkHOCR(t *testing.T, data []byte) []string {
+	t.Helper()
+	root := parseXML(t, data)
+	if root.Name.Local != "html" || root.Name.Space != "http://www.w3.org/1999/xhtml" || len(root.Children) != 2 {
+		t.Fatalf("expected an XHTML document with head and body, got %v", root.Name)
+	}
+
+	var capabilities []string
+	for _, meta := range root.Children[0].Children {
+		if meta.Attrs["name"] == "ocr-capabilities" {
+			capabilities = strings.Fields(meta.Attrs["content"])
+		}
+	}
+
+	ids := make(map[string]bool)
+	var words []string
+	var walk func(node *xmlNode, parentClass string, parent box)
+	walk = func(node *xmlNode, parentClass string, parent box) {
+		class := node.Attrs["class"]
+		if _, ok := hocrChildren[class]; !ok {
+			t.Errorf("unexpected element %s with class %q", node.Name.Local, class)
+			return
+		}
+		if !slices.Contains(capabilities, class) {
+			t.Errorf("class %s is not declared in ocr-capabilities", class)
+		}
+		if parentClass != "" && !slices.Contains(hocrChildren[parentClass], class) {
+			t.Errorf("%s is not allowed in %s", class, parentClass)
+		}
+		id := node.Attrs["id"]
+		if id == "" || ids[id] {
+			t.Errorf("missing or duplicate id %q", id)
+		}
+		ids[id] = true
+
+		title := node.Attrs["title"]
+		m := hocrBBoxPattern.FindStringSubmatch(title)
+		if m == nil {
+			t.Errorf("%s has no bbox: %q", id, title)
+			return
+		}
+		var b box
+		b.x0, _ = strconv.Atoi(m[1]) //nolint:errcheck
+		b.y0, _ = strconv.Atoi(m[2]) //nolint:errcheck
+		b.x1, _ = strconv.Atoi(m[3]) //nolint:errcheck
+		b.y1, _ = strconv.Atoi(m[4]) //nolint:errcheck
+		if b.x1 < b.x0 || b.y1 < b.y0 || (parentClass != "" && !parent.contains(b)) {
+			t.Errorf("%s: bbox %v outside of its parent %v", id, b, parent)
+		}
+		switch class {
+		case "ocr_page":
+			if !strings.Contains(title, "; ppageno ") {
+				t.Errorf("%s has no ppageno", id)
+			}
+		case "ocrx_word":
+			if !regexp.MustCompile(`; x_wconf \d{1,3}$`).MatchString(title) {
+				t.Errorf("%s has no x_wconf: %q", id, title)
+			}
+			words = append(words, node.Text)
+		}
+		for _, child := range node.Children {
+			walk(child, class, b)
+		}
+	}
+	for _, page := range root.Children[1].Children {
+		walk(page, "", box{})
+	}
+	return words
+}
+
+// altoRules are the children and required attributes of the ALTO v4 elements
+// written by WriteALTO, following alto-4-4.xsd
+var altoRules = map[string]struct {
+	children []string
+	required []string
+}{
+	"alto":            {children: []string{"Description", "Layout"}},
+	"Description":     {children: []string{"MeasurementUnit"}},
+	"MeasurementUnit": {},
+	"Layout":          {children: []string{"Page"}},
+	"Page":            {children: []string{"PrintSpace"}, required: []string{"ID", "PHYSICAL_IMG_NR"}},
+	"PrintSpace":      {children: []string{"TextBlock", "Illustration"}, required: []string{"HPOS", "VPOS", "WIDTH", "HEIGHT"}},
+	"TextBlock":       {children: []string{"TextLine"}, required: []string{"ID", "HPOS", "VPOS", "WIDTH", "HEIGHT"}},
+	"Illustration":    {required: []string{"ID", "HPOS", "VPOS", "WIDTH", "HEIGHT"}},
+	"TextLine":        {children: []string{"String", "SP"}, required: []string{"HPOS", "VPOS", "WIDTH", "HEIGHT"}},
+	"String":          {required: []string{"CONTENT", "HPOS", "VPOS", "WIDTH", "HEIGHT"}},
+	"SP":              {},
+}
+
+var ncName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// checkALTO checks the output of WriteALTO against the structure of the ALTO
+// v4 schema: namespace, element order and nesting, required attributes, ID
+// and number types, confidence ranges and positions inside the parent. It
+// returns the CONTENT of the String elements.
+func checkALTO(t *testing.T, data []byte) []string {
+	t.Helper()
+	root := parseXML(t, data)
+	if root.Name.Space != altoNamespace {
+		t.Fatalf("expected namespace %s, got %q", altoNamespace, root.Name.Space)
+	}
+	if len(root.Children) != 2 || root.Children[0].Name.Local != "Description" || root.Children[0].Children[0].Text != "pixel" {
+		t.Fatalf("expected Description with pixel measurement followed by Layout")
+	}
+
+	ids := make(map[string]bool)
+	var strs []string
+	var walk func(node *xmlNode, parent string, parentBox box, hasParentBox bool)
+	walk = func(node *xmlNode, parent string, parentBox box, hasParentBox bool) {
+		name := node.Name.Local
+		rules, ok := altoRules[name]
+		if !ok || (parent != "" && !slices.Contains(altoRules[parent].children, name)) {
+			t.Errorf("%s is not allowed in %s", name, parent)
+			return
+		}
+		if node.Name.Space != altoNamespace {
+			t.Errorf("%s is not in the ALTO namespace", name)
+		}
+		for _, attr := range rules.required {
+			if _, ok := node.Attrs[attr]; !ok {
+				t.Errorf("%s %s lacks required attribute %s", name, node.Attrs["ID"], attr)
+			}
+		}
+		if id, ok := node.Attrs["ID"]; ok {
+			if !ncName.MatchString(id) || ids[id] {
+				t.Errorf("invalid or duplicate ID %q", id)
+			}
+			ids[id] = true
+		}
+		for _, attr := range []string{"PC", "WC"} {
+			if v, ok := node.Attrs[attr]; ok {
+				if f, err := strconv.ParseFloat(v, 64); err != nil || f < 0 || f > 1 {
+					t.Errorf("%s of %s out of range: %q", attr, name, v)
+				}
+			}
+		}
+
+		b, hasBox := parentBox, hasParentBox
+		if _, ok := node.Attrs["HPOS"]; ok {
+			var values [4]int
+			for i, attr := range []string{"HPOS", "VPOS", "WIDTH", "HEIGHT"} {
+				v, err := strconv.Atoi(node.Attrs[attr])
+				if err != nil || v < 0 {
+					t.Errorf("%s of %s is not a non-negative number: %q", attr, name, node.Attrs[attr])
+				}
+				values[i] = v
+			}
+			b, hasBox = box{values[0], values[1], values[0] + values[2], values[1] + values[3]}, true
+			if hasParentBox && !parentBox.contains(b) {
+				t.Errorf("%s %s at %v outside of its parent %v", name, node.Attrs["ID"], b, parentBox)
+			}
+		}
+		if name == "Page" {
+			width, _ := strconv.Atoi(node.Attrs["WIDTH"])   //nolint:errcheck
+			height, _ := strconv.Atoi(node.Attrs["HEIGHT"]) //nolint:errcheck
+			b, hasBox = box{0, 0, width, height}, true
+		}
+		if name == "String" {
+			strs = append(strs, node.Attrs["CONTENT"])
+		}
+		for _, child := range node.Children {
+			walk(child, name, b, hasBox)
+		}
+	}
+	walk(root, "", box{}, false)
+	return strs
+}
+
+// xmllint validates an exported document with xmllint and the given
+// validation flags, skipping the test when xmllint is not installed
+func xmllint(t *testing.T, data []byte, args ...string) {
+	t.Helper()
+	bin, err := exec.LookPath("xmllint")
+	if err != nil {
+		t.Skip("xmllint not installed")
+	}
+	catalog, err := filepath.Abs(filepath.Join("testdata", "schemas", "catalog.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "document.xml")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	args = append([]string{"--noout", "--nonet"}, append(args, path)...)
+	cmd := exec.Command(bin, args...) // #nosec G204 - test arguments
+	cmd.Env = append(os.Environ(), "XML_CATALOG_FILES="+catalog)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("xmllint %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+// officialSchema returns the path of a schema downloaded by "make
+// testdata-schemas", skipping the test when it is missing
+func officialSchema(t *testing.T, name string) string {
+	t.Helper()
+	path := filepath.Join("testdata", "schemas", name)
+	if _, err := os.Stat(path); err != nil {
+		t.Skipf("%s is missing; download the official schemas with make testdata-schemas", path)
+	}
+	return path
+}
+
+func TestExport(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /ocr/result/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"job_id":"job_1","status":"completed","credits_used":1,"pages":[{"page_number":1,"confidence":0.93,`+
+			`"result":"# Invoice <42>\n\nAcme & Co\nTotal 12.50","dimensions":{"width":1000,"height":1400},"bounding_boxes":[`+
+			`{"type":"heading","text":"Invoice <42>","bounds":{"x":100,"y":80,"width":300,"height":40}},`+
+			`{"type":"paragraph","text":"Acme & Co\n\nTotal 12.50","bounds":{"x":100,"y":200,"width":500,"height":120}},`+
+			`{"type":"figure","bounds":{"x":600,"y":600,"width":200,"height":200}}]}]}`)
+	})
+	sdk := newTestTeamSDK(t, mux)
+
+	result, err := sdk.GetJobResult(context.Background(), "job_1")
+	if err != nil {
+		t.Fatalf("GetJobResult failed: %v", err)
+	}
+	page := result.Pages[0]
+	if page.Width != 1000 || page.Height != 1400 || len(page.Blocks) != 3 || page.Blocks[1].Bounds != (BoundingBox{X: 100, Y: 200, Width: 500, Height: 120}) {
+		t.Fatalf("unexpected page layout: %+v", page)
+	}
+
+	want := []string{"Invoice", "<42>", "Acme", "&", "Co", "Total", "12.50"}
+
+	var hocr bytes.Buffer
+	if err := WriteHOCR(&hocr, result); err != nil {
+		t.Fatalf("WriteHOCR failed: %v", err)
+	}
+	if words := checkHOCR(t, hocr.Bytes()); !slices.Equal(words, want) {
+		t.Errorf("hOCR words: expected %q, got %q", want, words)
+	}
+	for _, fragment := range []string{`class="ocr_header"`, `class="ocr_photo"`, `x_wconf 93`, `ppageno 0`} {
+		if !strings.Contains(hocr.String(), fragment) {
+			t.Errorf("hOCR lacks %s", fragment)
+		}
+	}
+
+	var alto bytes.Buffer
+	if err := WriteALTO(&alto, result); err != nil {
+		t.Fatalf("WriteALTO failed: %v", err)
+	}
+	if strs := checkALTO(t, alto.Bytes()); !slices.Equal(strs, want) {
+		t.Errorf("ALTO strings: expected %q, got %q", want, strs)
+	}
+	if strings.Count(alto.String(), "<TextLine") != 3 || !strings.Contains(alto.String(), `PC="0.93"`) || !strings.Contains(alto.String(), "<Illustration") {
+		t.Errorf("unexpected ALTO document:\n%s", alto.String())
+	}
+
+	t.Run("XHTML DTD", func(t *testing.T) {
+		xmllint(t, hocr.Bytes(), "--dtdvalid", officialSchema(t, "xhtml1-transitional.dtd"))
+	})
+	t.Run("ALTO schema", func(t *testing.T) {
+		xmllint(t, alto.Bytes(), "--schema", officialSchema(t, "alto-4-4.xsd"))
+	})
+
+	// Results without layout cannot be exported
+	result.Pages[0].Width = 0
+	for name, write := range map[string]func(io.Writer, *OCRResult) error{"hOCR": WriteHOCR, "ALTO": WriteALTO} {
+		if err := write(io.Discard, result); errorTypeOf(err) != ErrorTypeValidationError {
+			t.Errorf("%s: expected validation error without dimensions, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package ocr
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+const hocrPrelude = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Transitional//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd">
+`
+
+// hocrCapabilities are the hOCR classes and properties WriteHOCR may produce
+const hocrCapabilities = "ocr_page ocr_carea ocr_par ocr_line ocr_header ocr_caption ocrx_word ocr_photo ocrp_wconf"
+
+// hocrElement is an element of the hOCR body; its name is set per element
+type hocrElement struct {
+	XMLName  xml.Name
+	Class    string `xml:"class,attr"`
+	ID       string `xml:"id,attr"`
+	Title    string `xml:"title,attr"`
+	Text     string `xml:",chardata"`
+	Children []hocrElement
+}
+
+type hocrMeta struct {
+	Name      string `xml:"name,attr,omitempty"`
+	HTTPEquiv string `xml:"http-equiv,attr,omitempty"`
+	Content   string `xml:"content,attr"`
+}
+
+type hocrDocument struct {
+	XMLName xml.Name `xml:"html"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Lang    string   `xml:"xml:lang,attr"`
+	Head    struct {
+		Title string     `xml:"title"`
+		Meta  []hocrMeta `xml:"meta"`
+	} `xml:"head"`
+	Body struct {
+		Pages []hocrElement
+	} `xml:"body"`
+}
+
+// WriteHOCR writes result as an hOCR document in XHTML. Every page needs
+// dimensions and bounding boxes, which the API returns when bounding box
+// extraction is enabled. Blocks become ocr_carea areas with one paragraph;
+// their lines and words are located by dividing the block evenly, since the
+// API locates blocks only. Words carry the page confidence as x_wconf.
+func WriteHOCR(w io.Writer, result *OCRResult) error {
+	if err := checkLayout(result); err != nil {
+		return err
+	}
+
+	doc := hocrDocument{Xmlns: "http://www.w3.org/1999/xhtml", Lang: "en"}
+	doc.Head.Meta = []hocrMeta{
+		{HTTPEquiv: "Content-Type", Content: "text/html;charset=utf-8"},
+		{Name: "ocr-system", Content: "leapocr-go " + Version},
+		{Name: "ocr-capabilities", Content: hocrCapabilities},
+		{Name: "ocr-number-of-pages", Content: fmt.Sprint(len(result.Pages))},
+	}
+	for i, page := range result.Pages {
+		doc.Body.Pages = append(doc.Body.Pages, hocrPage(i, page))
+	}
+
+	if _, err := io.WriteString(w, hocrPrelude); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", " ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func hocrPage(index int, page PageResult) hocrElement {
+	n := page.PageNumber
+	el := hocrElement{
+		XMLName: xml.Name{Local: "div"},
+		Class:   "ocr_page",
+		ID:      fmt.Sprintf("page_%d", n),
+		Title:   fmt.Sprintf("bbox 0 0 %d %d; ppageno %d", page.Width, page.Height, index),
+	}
+	wconf := ""
+	if page.Confidence != nil {
+		wconf = fmt.Sprintf("; x_wconf %d", int(math.Round(*page.Confidence*100)))
+	}
+
+	for b, block := range page.Blocks {
+		id := fmt.Sprintf("%d_%d", n, b+1)
+		lines := blockLines(block)
+		if len(lines) == 0 {
+			if block.Type == "figure" {
+				el.Children = append(el.Children, hocrElement{
+					XMLName: xml.Name{Local: "div"}, Class: "ocr_photo", ID: "block_" + id, Title: hocrBBox(block.Bounds),
+				})
+			}
+			continue
+		}
+
+		par := hocrElement{XMLName: xml.Name{Local: "p"}, Class: "ocr_par", ID: "par_" + id, Title: hocrBBox(block.Bounds)}
+		for l, line := range lines {
+			lineID := fmt.Sprintf("%s_%d", id, l+1)
+			span := hocrElement{XMLName: xml.Name{Local: "span"}, Class: hocrLineClass(block.Type), ID: "line_" + lineID, Title: hocrBBox(line.Bounds)}
+			for wi, word := range line.Words {
+				span.Children = append(span.Children, hocrElement{
+					XMLName: xml.Name{Local: "span"},
+					Class:   "ocrx_word",
+					ID:      fmt.Sprintf("word_%s_%d", lineID, wi+1),
+					Title:   hocrBBox(word.Bounds) + wconf,
+					Text:    word.Text,
+				})
+			}
+			par.Children = append(par.Children, span)
+		}
+		el.Children = append(el.Children, hocrElement{
+			XMLName:  xml.Name{Local: "div"},
+			Class:    "ocr_carea",
+			ID:       "block_" + id,
+			Title:    hocrBBox(block.Bounds),
+			Children: []hocrElement{par},
+		})
+	}
+	return el
+}
+
+// hocrLineClass returns the line class for lines of a block type, as Tesseract does
+func hocrLineClass(blockType string) string {
+	switch strings.ToLower(blockType) {
+	case "heading":
+		return "ocr_header"
+	case "caption":
+		return "ocr_caption"
+	default:
+		return "ocr_line"
+	}
+}
+
+// hocrBBox returns the bbox property, given by the top left and bottom right corners
+func hocrBBox(b BoundingBox) string {
+	return fmt.Sprintf("bbox %d %d %d %d", b.X, b.Y, b.X+b.Width, b.Y+b.Height)
+}
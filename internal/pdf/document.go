@@ -0,0 +1,568 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ErrEncrypted is returned for encrypted documents, which cannot be updated
+// without their keys
+var ErrEncrypted = errors.New("pdf: encrypted documents are not supported")
+
+// maxDepth bounds reference chains and page tree nesting in damaged files
+const maxDepth = 64
+
+// xrefEntry locates an object either at an offset in the file or at an index
+// in an object stream
+type xrefEntry struct {
+	offset   int
+	stream   int
+	index    int
+	inStream bool
+	free     bool
+}
+
+// Document is a parsed PDF file
+type Document struct {
+	data []byte
+	xref map[int]xrefEntry
+	// Trailer is the trailer dictionary of the latest update
+	Trailer Dict
+	// startxref is the offset of the latest cross-reference section
+	startxref int
+	// xrefStream tells whether the latest section is a cross-reference stream
+	xrefStream bool
+	objStreams map[int][]int
+}
+
+// Page is a page of a Document with the attributes it inherits from the page tree
+type Page struct {
+	Ref  Ref
+	Dict Dict
+	// Resources is the resource dictionary or a reference to it
+	Resources any
+	// Box is the visible region of the page: its crop box, else its media box
+	Box [4]float64
+	// Rotate is the clockwise rotation of the page in degrees: 0, 90, 180 or 270
+	Rotate int
+}
+
+// Open parses the cross-reference data of a PDF file. Objects are read on
+// demand, so later calls may still fail on damaged files.
+func Open(data []byte) (*Document, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, "\x00\t\n\f\r "), []byte("%PDF-")) {
+		return nil, errors.New("pdf: missing %PDF header")
+	}
+	i := bytes.LastIndex(data, []byte("startxref"))
+	if i < 0 {
+		return nil, errors.New("pdf: missing startxref")
+	}
+	p := parser{data: data, pos: i + len("startxref")}
+	start, err := strconv.Atoi(p.keyword())
+	if err != nil {
+		return nil, fmt.Errorf("pdf: invalid startxref: %w", err)
+	}
+
+	d := &Document{data: data, xref: map[int]xrefEntry{}, startxref: start, objStreams: map[int][]int{}}
+	seen := map[int]bool{}
+	for offset, first := start, true; ; first = false {
+		if seen[offset] || len(seen) > maxDepth {
+			return nil, errors.New("pdf: cross-reference sections form a loop")
+		}
+		seen[offset] = true
+		trailer, isStream, err := d.readXref(offset)
+		if err != nil {
+			return nil, err
+		}
+		if first {
+			d.Trailer = trailer
+			d.xrefStream = isStream
+		}
+		// A hybrid file lists its compressed objects in a stream as well
+		if stm, ok := Int(trailer[Name("XRefStm")]); ok && !seen[stm] {
+			seen[stm] = true
+			if _, _, err := d.readXref(stm); err != nil {
+				return nil, err
+			}
+		}
+		prev, ok := Int(trailer[Name("Prev")])
+		if !ok {
+			break
+		}
+		offset = prev
+	}
+
+	if _, ok := d.Trailer[Name("Encrypt")]; ok {
+		return nil, ErrEncrypted
+	}
+	if _, ok := d.Trailer[Name("Root")].(Ref); !ok {
+		return nil, errors.New("pdf: trailer has no document catalog")
+	}
+	return d, nil
+}
+
+// readXref reads the cross-reference section at offset; entries of newer
+// sections, read first, take precedence
+func (d *Document) readXref(offset int) (Dict, bool, error) {
+	if offset < 0 || offset >= len(d.data) {
+		return nil, false, fmt.Errorf("pdf: cross-reference offset %d out of range", offset)
+	}
+	p := parser{data: d.data, pos: offset}
+	save := p.pos
+	if p.keyword() != "xref" {
+		p.pos = save
+		return d.readXrefStream(&p)
+	}
+
+	for {
+		save = p.pos
+		first, err := strconv.Atoi(p.keyword())
+		if err != nil {
+			p.pos = save
+			break
+		}
+		count, err := strconv.Atoi(p.keyword())
+		if err != nil {
+			return nil, false, p.errorf("invalid cross-reference subsection")
+		}
+		for n := first; n < first+count; n++ {
+			off, err1 := strconv.Atoi(p.keyword())
+			_, err2 := strconv.Atoi(p.keyword())
+			kind := p.keyword()
+			if err1 != nil || err2 != nil || (kind != "n" && kind != "f") {
+				return nil, false, p.errorf("invalid cross-reference entry")
+			}
+			if _, ok := d.xref[n]; !ok {
+				// Free entries shadow the entries of older sections too
+				d.xref[n] = xrefEntry{offset: off, free: kind == "f"}
+			}
+		}
+	}
+	if err := p.expect("trailer"); err != nil {
+		return nil, false, err
+	}
+	obj, err := p.object()
+	if err != nil {
+		return nil, false, err
+	}
+	trailer, ok := obj.(Dict)
+	if !ok {
+		return nil, false, p.errorf("trailer is not a dictionary")
+	}
+	return trailer, false, nil
+}
+
+func (d *Document) readXrefStream(p *parser) (Dict, bool, error) {
+	_, obj, err := p.indirectObject(d.length)
+	if err != nil {
+		return nil, false, err
+	}
+	stream, ok := obj.(*Stream)
+	if !ok || stream.Dict[Name("Type")] != Name("XRef") {
+		return nil, false, errors.New("pdf: startxref does not point to a cross-reference section")
+	}
+	data, err := d.Decode(stream)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var widths [3]int
+	w, _ := stream.Dict[Name("W")].(Array)
+	if len(w) != 3 {
+		return nil, false, errors.New("pdf: invalid cross-reference stream widths")
+	}
+	rowSize := 0
+	for i, v := range w {
+		if widths[i], ok = Int(v); !ok || widths[i] < 0 || widths[i] > 8 {
+			return nil, false, errors.New("pdf: invalid cross-reference stream widths")
+		}
+		rowSize += widths[i]
+	}
+	size, _ := Int(stream.Dict[Name("Size")])
+	index := Array{0, size}
+	if a, ok := stream.Dict[Name("Index")].(Array); ok {
+		index = a
+	}
+
+	field := func(row []byte, i int) (int, bool) {
+		start := 0
+		for _, n := range widths[:i] {
+			start += n
+		}
+		if widths[i] == 0 {
+			return 0, false
+		}
+		v := 0
+		for _, b := range row[start : start+widths[i]] {
+			v = v<<8 | int(b)
+		}
+		return v, true
+	}
+	for i := 0; i+1 < len(index); i += 2 {
+		first, ok1 := Int(index[i])
+		count, ok2 := Int(index[i+1])
+		if !ok1 || !ok2 {
+			return nil, false, errors.New("pdf: invalid cross-reference stream index")
+		}
+		for n := first; n < first+count; n++ {
+			if len(data) < rowSize {
+				return nil, false, errors.New("pdf: truncated cross-reference stream")
+			}
+			row := data[:rowSize]
+			data = data[rowSize:]
+			if _, ok := d.xref[n]; ok {
+				continue
+			}
+			// The type defaults to 1 when its field is absent
+			kind, ok := field(row, 0)
+			if !ok {
+				kind = 1
+			}
+			a, _ := field(row, 1)
+			b, _ := field(row, 2)
+			switch kind {
+			case 0:
+				d.xref[n] = xrefEntry{free: true}
+			case 1:
+				d.xref[n] = xrefEntry{offset: a}
+			case 2:
+				d.xref[n] = xrefEntry{stream: a, index: b, inStream: true}
+			}
+		}
+	}
+	return stream.Dict, true, nil
+}
+
+// length resolves the Length of a stream
+func (d *Document) length(v any) (int, error) {
+	v, err := d.Resolve(v)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := Int(v)
+	if !ok {
+		return 0, errors.New("pdf: invalid stream length")
+	}
+	return n, nil
+}
+
+// Resolve follows v while it is a reference; a reference to a missing object resolves to nil
+func (d *Document) Resolve(v any) (any, error) {
+	for range maxDepth {
+		ref, ok := v.(Ref)
+		if !ok {
+			return v, nil
+		}
+		obj, err := d.Object(ref)
+		if err != nil {
+			return nil, err
+		}
+		v = obj
+	}
+	return nil, errors.New("pdf: reference chain too long")
+}
+
+// Object reads the object ref refers to
+func (d *Document) Object(ref Ref) (any, error) {
+	e, ok := d.xref[ref.Num]
+	if !ok || e.free {
+		return nil, nil
+	}
+	if e.inStream {
+		return d.streamObject(ref.Num, e)
+	}
+	if e.offset <= 0 || e.offset >= len(d.data) {
+		return nil, fmt.Errorf("pdf: object %d offset out of range", ref.Num)
+	}
+	p := parser{data: d.data, pos: e.offset}
+	got, obj, err := p.indirectObject(d.length)
+	if err != nil {
+		return nil, fmt.Errorf("pdf: reading object %d: %w", ref.Num, err)
+	}
+	if got.Num != ref.Num {
+		return nil, fmt.Errorf("pdf: object %d is not at its cross-reference offset", ref.Num)
+	}
+	return obj, nil
+}
+
+// streamObject reads an object stored in an object stream
+func (d *Document) streamObject(num int, e xrefEntry) (any, error) {
+	obj, err := d.Object(Ref{Num: e.stream})
+	if err != nil {
+		return nil, err
+	}
+	stream, ok := obj.(*Stream)
+	if !ok {
+		return nil, fmt.Errorf("pdf: object stream %d not found", e.stream)
+	}
+	data, err := d.Decode(stream)
+	if err != nil {
+		return nil, err
+	}
+	first, _ := Int(stream.Dict[Name("First")])
+	offsets, ok := d.objStreams[e.stream]
+	if !ok {
+		n, _ := Int(stream.Dict[Name("N")])
+		p := parser{data: data}
+		for range n {
+			objNum, err1 := strconv.Atoi(p.keyword())
+			off, err2 := strconv.Atoi(p.keyword())
+			if err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("pdf: invalid header in object stream %d", e.stream)
+			}
+			offsets = append(offsets, objNum, off)
+		}
+		d.objStreams[e.stream] = offsets
+	}
+	if 2*e.index+1 >= len(offsets) || offsets[2*e.index] != num {
+		return nil, fmt.Errorf("pdf: object %d not found in object stream %d", num, e.stream)
+	}
+	p := parser{data: data, pos: first + offsets[2*e.index+1]}
+	if p.pos < 0 || p.pos >= len(data) {
+		return nil, fmt.Errorf("pdf: object %d offset out of range", num)
+	}
+	return p.object()
+}
+
+// Decode returns the content of stream with its filters undone. Only
+// FlateDecode is supported, with or without PNG and TIFF predictors.
+func (d *Document) Decode(stream *Stream) ([]byte, error) {
+	filters, err := d.Resolve(stream.Dict[Name("Filter")])
+	if err != nil {
+		return nil, err
+	}
+	params, err := d.Resolve(stream.Dict[Name("DecodeParms")])
+	if err != nil {
+		return nil, err
+	}
+	if f, ok := filters.(Name); ok {
+		filters, params = Array{f}, Array{params}
+	}
+	filterList, _ := filters.(Array)
+	paramList, _ := params.(Array)
+
+	data := stream.Data
+	for i, f := range filterList {
+		if f != Name("FlateDecode") && f != Name("Fl") {
+			return nil, fmt.Errorf("pdf: unsupported filter %v", f)
+		}
+		r, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("pdf: decoding stream: %w", err)
+		}
+		data, err = io.ReadAll(r)
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("pdf: decoding stream: %w", err)
+		}
+		var parms Dict
+		if i < len(paramList) {
+			p, err := d.Resolve(paramList[i])
+			if err != nil {
+				return nil, err
+			}
+			parms, _ = p.(Dict)
+		}
+		if data, err = unpredict(data, parms); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// unpredict undoes the predictor of Flate-encoded data
+func unpredict(data []byte, parms Dict) ([]byte, error) {
+	predictor, _ := Int(parms[Name("Predictor")])
+	if predictor <= 1 {
+		return data, nil
+	}
+	intOr := func(key Name, def int) int {
+		if v, ok := Int(parms[key]); ok {
+			return v
+		}
+		return def
+	}
+	colors, bits, columns := intOr("Colors", 1), intOr("BitsPerComponent", 8), intOr("Columns", 1)
+	bpp := max(1, (colors*bits+7)/8)
+	rowSize := (colors*bits*columns + 7) / 8
+	if rowSize <= 0 {
+		return nil, errors.New("pdf: invalid predictor parameters")
+	}
+
+	if predictor == 2 {
+		if bits != 8 {
+			return nil, errors.New("pdf: unsupported TIFF predictor depth")
+		}
+		out := bytes.Clone(data)
+		for row := 0; row+rowSize <= len(out); row += rowSize {
+			for i := row + bpp; i < row+rowSize; i++ {
+				out[i] += out[i-bpp]
+			}
+		}
+		return out, nil
+	}
+
+	// PNG predictors prefix each row with its filter type
+	var out []byte
+	prev := make([]byte, rowSize)
+	for len(data) > 0 {
+		if len(data) < rowSize+1 {
+			break
+		}
+		kind, row := data[0], bytes.Clone(data[1:rowSize+1])
+		data = data[rowSize+1:]
+		for i := range row {
+			var left, upLeft byte
+			if i >= bpp {
+				left, upLeft = row[i-bpp], prev[i-bpp]
+			}
+			up := prev[i]
+			switch kind {
+			case 0:
+			case 1:
+				row[i] += left
+			case 2:
+				row[i] += up
+			case 3:
+				row[i] += byte((int(left) + int(up)) / 2)
+			case 4:
+				row[i] += paeth(left, up, upLeft)
+			default:
+				return nil, fmt.Errorf("pdf: invalid PNG predictor %d", kind)
+			}
+		}
+		out = append(out, row...)
+		prev = row
+	}
+	return out, nil
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	switch {
+	case pa <= pb && pa <= pc:
+		return a
+	case pb <= pc:
+		return b
+	}
+	return c
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// pageAttributes are the inheritable attributes of the page tree
+type pageAttributes struct {
+	resources any
+	mediaBox  any
+	cropBox   any
+	rotate    any
+}
+
+// Pages returns the pages of the document in order
+func (d *Document) Pages() ([]Page, error) {
+	catalog, err := d.Resolve(d.Trailer[Name("Root")])
+	if err != nil {
+		return nil, err
+	}
+	root, ok := catalog.(Dict)
+	if !ok {
+		return nil, errors.New("pdf: invalid document catalog")
+	}
+	ref, ok := root[Name("Pages")].(Ref)
+	if !ok {
+		return nil, errors.New("pdf: document catalog has no page tree")
+	}
+	var pages []Page
+	err = d.walkPages(ref, pageAttributes{}, map[int]bool{}, 0, &pages)
+	return pages, err
+}
+
+// walkPages appends the pages under the node ref to pages; attrs holds the
+// attributes inherited from its ancestors
+func (d *Document) walkPages(ref Ref, attrs pageAttributes, seen map[int]bool, depth int, pages *[]Page) error {
+	if seen[ref.Num] || depth > maxDepth {
+		return errors.New("pdf: page tree forms a loop")
+	}
+	seen[ref.Num] = true
+	obj, err := d.Object(ref)
+	if err != nil {
+		return err
+	}
+	node, ok := obj.(Dict)
+	if !ok {
+		return fmt.Errorf("pdf: page tree node %d is not a dictionary", ref.Num)
+	}
+	for key, attr := range map[Name]*any{
+		"Resources": &attrs.resources, "MediaBox": &attrs.mediaBox, "CropBox": &attrs.cropBox, "Rotate": &attrs.rotate,
+	} {
+		if v, ok := node[key]; ok {
+			*attr = v
+		}
+	}
+
+	if node[Name("Type")] == Name("Pages") || node[Name("Kids")] != nil {
+		kids, err := d.Resolve(node[Name("Kids")])
+		if err != nil {
+			return err
+		}
+		kidList, _ := kids.(Array)
+		for _, kid := range kidList {
+			kidRef, ok := kid.(Ref)
+			if !ok {
+				return fmt.Errorf("pdf: page tree node %d has an invalid kid", ref.Num)
+			}
+			if err := d.walkPages(kidRef, attrs, seen, depth+1, pages); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	page := Page{Ref: ref, Dict: node, Resources: attrs.resources}
+	box, err := d.rectangle(attrs.cropBox)
+	if err != nil || box[2] <= box[0] || box[3] <= box[1] {
+		if box, err = d.rectangle(attrs.mediaBox); err != nil || box[2] <= box[0] || box[3] <= box[1] {
+			return fmt.Errorf("pdf: page %d has no valid media box", len(*pages)+1)
+		}
+	}
+	page.Box = box
+	if v, err := d.Resolve(attrs.rotate); err == nil {
+		if r, ok := Int(v); ok {
+			r = (r%360 + 360) % 360
+			page.Rotate = r - r%90
+		}
+	}
+	*pages = append(*pages, page)
+	return nil
+}
+
+// rectangle reads a rectangle, normalized so that its lower left corner comes first
+func (d *Document) rectangle(v any) ([4]float64, error) {
+	var r [4]float64
+	v, err := d.Resolve(v)
+	if err != nil {
+		return r, err
+	}
+	arr, ok := v.(Array)
+	if !ok || len(arr) != 4 {
+		return r, errors.New("pdf: invalid rectangle")
+	}
+	for i, n := range arr {
+		if n, err = d.Resolve(n); err != nil {
+			return r, err
+		}
+		if r[i], ok = Float(n); !ok {
+			return r, errors.New("pdf: invalid rectangle")
+		}
+	}
+	return [4]float64{min(r[0], r[2]), min(r[1], r[3]), max(r[0], r[2]), max(r[1], r[3])}, nil
+}
@@ -0,0 +1,169 @@
+// Package pdf reads the object structure of PDF files and appends incremental
+// updates to them. It understands cross-reference tables and streams, object
+// streams and Flate compression, which is what locating and amending pages
+// needs; it does not render or decrypt documents.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Name is a PDF name without its leading slash, as written in the file
+type Name string
+
+// String is a decoded PDF string
+type String []byte
+
+// Number is a PDF number, kept as written so it can be written back unchanged
+type Number string
+
+// Array is a PDF array
+type Array []any
+
+// Dict is a PDF dictionary
+type Dict map[Name]any
+
+// Ref is an indirect reference to an object
+type Ref struct {
+	Num int
+	Gen int
+}
+
+// Stream is a stream object; Data is the stream content before any filter is undone
+type Stream struct {
+	Dict Dict
+	Data []byte
+}
+
+// NewFlateStream returns a stream holding data compressed with FlateDecode
+func NewFlateStream(dict Dict, data []byte) *Stream {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	_, _ = zw.Write(data) //nolint:errcheck // writes to a bytes.Buffer cannot fail
+	_ = zw.Close()        //nolint:errcheck
+	dict = dict.Copy()
+	dict["Filter"] = Name("FlateDecode")
+	return &Stream{Dict: dict, Data: buf.Bytes()}
+}
+
+// Float returns the value of a number object
+func Float(v any) (float64, bool) {
+	switch n := v.(type) {
+	case Number:
+		f, err := strconv.ParseFloat(string(n), 64)
+		return f, err == nil
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// Int returns the value of an integer object
+func Int(v any) (int, bool) {
+	f, ok := Float(v)
+	if !ok || f != float64(int(f)) {
+		return 0, false
+	}
+	return int(f), true
+}
+
+// Copy returns a shallow copy of d
+func (d Dict) Copy() Dict {
+	c := make(Dict, len(d)+1)
+	for k, v := range d {
+		c[k] = v
+	}
+	return c
+}
+
+// writeObject writes v in PDF syntax; it accepts the types of this package
+// along with nil, bool, int and float64
+func writeObject(w *bytes.Buffer, v any) error {
+	switch o := v.(type) {
+	case nil:
+		w.WriteString("null")
+	case bool:
+		w.WriteString(strconv.FormatBool(o))
+	case int:
+		w.WriteString(strconv.Itoa(o))
+	case float64:
+		w.WriteString(FormatNumber(o))
+	case Number:
+		w.WriteString(string(o))
+	case Name:
+		w.WriteByte('/')
+		w.WriteString(string(o))
+	case String:
+		fmt.Fprintf(w, "<%X>", []byte(o))
+	case Ref:
+		fmt.Fprintf(w, "%d %d R", o.Num, o.Gen)
+	case Array:
+		w.WriteByte('[')
+		for i, item := range o {
+			if i > 0 {
+				w.WriteByte(' ')
+			}
+			if err := writeObject(w, item); err != nil {
+				return err
+			}
+		}
+		w.WriteByte(']')
+	case Dict:
+		// Sorted keys keep the output deterministic
+		keys := make([]string, 0, len(o))
+		for k := range o {
+			keys = append(keys, string(k))
+		}
+		sort.Strings(keys)
+		w.WriteString("<<")
+		for _, k := range keys {
+			w.WriteString(" /")
+			w.WriteString(k)
+			w.WriteByte(' ')
+			if err := writeObject(w, o[Name(k)]); err != nil {
+				return err
+			}
+		}
+		w.WriteString(" >>")
+	case *Stream:
+		return fmt.Errorf("pdf: streams must be indirect objects")
+	default:
+		return fmt.Errorf("pdf: cannot write %T", v)
+	}
+	return nil
+}
+
+// FormatNumber formats f compactly with at most four decimals
+func FormatNumber(f float64) string {
+	s := strconv.FormatFloat(f, 'f', 4, 64)
+	s = strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// countingWriter counts the bytes written to w and keeps the first error
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
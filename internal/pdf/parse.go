@@ -0,0 +1,319 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// errSyntax reports malformed PDF syntax
+var errSyntax = errors.New("pdf: syntax error")
+
+// parser reads objects from data starting at pos
+type parser struct {
+	data []byte
+	pos  int
+}
+
+func isSpace(c byte) bool {
+	switch c {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+	return false
+}
+
+func isDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w at offset %d: %s", errSyntax, p.pos, fmt.Sprintf(format, args...))
+}
+
+// skipSpace skips whitespace and comments
+func (p *parser) skipSpace() {
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		switch {
+		case isSpace(c):
+			p.pos++
+		case c == '%':
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' && p.data[p.pos] != '\r' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// keyword reads a regular token such as a number, a boolean or an operator
+func (p *parser) keyword() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.data) && !isSpace(p.data[p.pos]) && !isDelimiter(p.data[p.pos]) {
+		p.pos++
+	}
+	return string(p.data[start:p.pos])
+}
+
+// expect reads the keyword want
+func (p *parser) expect(want string) error {
+	if got := p.keyword(); got != want {
+		return p.errorf("expected %q, found %q", want, got)
+	}
+	return nil
+}
+
+// object reads the next direct object; streams are read by indirectObject
+func (p *parser) object() (any, error) {
+	p.skipSpace()
+	if p.pos >= len(p.data) {
+		return nil, p.errorf("unexpected end of data")
+	}
+	switch c := p.data[p.pos]; {
+	case c == '/':
+		p.pos++
+		start := p.pos
+		for p.pos < len(p.data) && !isSpace(p.data[p.pos]) && !isDelimiter(p.data[p.pos]) {
+			p.pos++
+		}
+		return Name(p.data[start:p.pos]), nil
+	case c == '(':
+		return p.literalString()
+	case c == '<' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '<':
+		return p.dict()
+	case c == '<':
+		return p.hexString()
+	case c == '[':
+		p.pos++
+		var arr Array
+		for {
+			p.skipSpace()
+			if p.pos < len(p.data) && p.data[p.pos] == ']' {
+				p.pos++
+				return arr, nil
+			}
+			item, err := p.object()
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, item)
+		}
+	}
+
+	start := p.pos
+	word := p.keyword()
+	switch word {
+	case "":
+		return nil, p.errorf("unexpected %q", p.data[p.pos])
+	case "null":
+		return nil, nil
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	if _, err := strconv.ParseFloat(word, 64); err != nil {
+		p.pos = start
+		return nil, p.errorf("unexpected %q", word)
+	}
+
+	// An integer may start a reference "num gen R"
+	if num, err := strconv.Atoi(word); err == nil {
+		save := p.pos
+		if gen, err := strconv.Atoi(p.keyword()); err == nil && p.keyword() == "R" {
+			return Ref{Num: num, Gen: gen}, nil
+		}
+		p.pos = save
+	}
+	return Number(word), nil
+}
+
+func (p *parser) dict() (Dict, error) {
+	p.pos += 2
+	d := Dict{}
+	for {
+		p.skipSpace()
+		if bytes.HasPrefix(p.data[p.pos:], []byte(">>")) {
+			p.pos += 2
+			return d, nil
+		}
+		key, err := p.object()
+		if err != nil {
+			return nil, err
+		}
+		name, ok := key.(Name)
+		if !ok {
+			return nil, p.errorf("dictionary key is %T, not a name", key)
+		}
+		value, err := p.object()
+		if err != nil {
+			return nil, err
+		}
+		// A null value is equivalent to a missing entry
+		if value != nil {
+			d[name] = value
+		}
+	}
+}
+
+func (p *parser) literalString() (String, error) {
+	p.pos++
+	var s []byte
+	depth := 0
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return s, nil
+			}
+			depth--
+		case '\r':
+			// End-of-line markers are read as a single newline
+			if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+				p.pos++
+			}
+			c = '\n'
+		case '\\':
+			if p.pos >= len(p.data) {
+				break
+			}
+			c = p.data[p.pos]
+			p.pos++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r', '\n':
+				// A backslash at the end of a line continues the string
+				if c == '\r' && p.pos < len(p.data) && p.data[p.pos] == '\n' {
+					p.pos++
+				}
+				continue
+			case '0', '1', '2', '3', '4', '5', '6', '7':
+				v := int(c - '0')
+				for i := 0; i < 2 && p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '7'; i++ {
+					v = v*8 + int(p.data[p.pos]-'0')
+					p.pos++
+				}
+				c = byte(v)
+			}
+		}
+		s = append(s, c)
+	}
+	return nil, p.errorf("unterminated string")
+}
+
+func (p *parser) hexString() (String, error) {
+	p.pos++
+	var s []byte
+	var digit byte
+	half := false
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+		var v byte
+		switch {
+		case c == '>':
+			if half {
+				s = append(s, digit<<4)
+			}
+			return s, nil
+		case isSpace(c):
+			continue
+		case c >= '0' && c <= '9':
+			v = c - '0'
+		case c >= 'a' && c <= 'f':
+			v = c - 'a' + 10
+		case c >= 'A' && c <= 'F':
+			v = c - 'A' + 10
+		default:
+			return nil, p.errorf("invalid hex digit %q", c)
+		}
+		if half {
+			s = append(s, digit<<4|v)
+		} else {
+			digit = v
+		}
+		half = !half
+	}
+	return nil, p.errorf("unterminated hex string")
+}
+
+// indirectObject reads "num gen obj ... endobj" and returns the object and its
+// reference; length resolves the length of a stream, which may be indirect
+func (p *parser) indirectObject(length func(any) (int, error)) (Ref, any, error) {
+	var ref Ref
+	var err error
+	if ref.Num, err = strconv.Atoi(p.keyword()); err != nil {
+		return ref, nil, p.errorf("expected object number")
+	}
+	if ref.Gen, err = strconv.Atoi(p.keyword()); err != nil {
+		return ref, nil, p.errorf("expected generation number")
+	}
+	if err := p.expect("obj"); err != nil {
+		return ref, nil, err
+	}
+	obj, err := p.object()
+	if err != nil {
+		return ref, nil, err
+	}
+	dict, ok := obj.(Dict)
+	if !ok {
+		return ref, obj, nil
+	}
+	save := p.pos
+	if p.keyword() != "stream" {
+		p.pos = save
+		return ref, obj, nil
+	}
+
+	// The keyword is followed by CRLF or LF, then the data
+	if bytes.HasPrefix(p.data[p.pos:], []byte("\r\n")) {
+		p.pos += 2
+	} else if p.pos < len(p.data) && (p.data[p.pos] == '\n' || p.data[p.pos] == '\r') {
+		p.pos++
+	}
+	start := p.pos
+	n, err := length(dict[Name("Length")])
+	if err != nil || n < 0 || start+n > len(p.data) || !p.endsStream(start+n) {
+		// Fall back on searching for the end of the stream
+		end := bytes.Index(p.data[start:], []byte("endstream"))
+		if end < 0 {
+			return ref, nil, p.errorf("unterminated stream")
+		}
+		n = end
+		for n > 0 && (p.data[start+n-1] == '\n' || p.data[start+n-1] == '\r') {
+			n--
+		}
+	}
+	p.pos = start + n
+	if err := p.expect("endstream"); err != nil {
+		return ref, nil, err
+	}
+	return ref, &Stream{Dict: dict, Data: p.data[start : start+n]}, nil
+}
+
+// endsStream reports whether the endstream keyword follows offset
+func (p *parser) endsStream(offset int) bool {
+	q := parser{data: p.data, pos: offset}
+	return q.keyword() == "endstream"
+}
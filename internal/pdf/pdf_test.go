@@ -0,0 +1,183 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseObjects(t *testing.T) {
+	tests := []struct {
+		input string
+		want  any
+	}{
+		{"/Name#20x", Name("Name#20x")},
+		{"-12.5", Number("-12.5")},
+		{"12 0 R", Ref{Num: 12}},
+		{"[1 2 0 R 3 true null]", Array{Number("1"), Ref{Num: 2}, Number("3"), true, nil}},
+		{"(a(b)c\\)\\n\\101\\\n)", String("a(b)c)\nA")},
+		{"<48 65 6c6>", String("Hel`")},
+		{"<< /A [ ] /B << >> % comment\n /C null >>", Dict{"A": Array(nil), "B": Dict{}}},
+	}
+	for _, tt := range tests {
+		p := parser{data: []byte(tt.input)}
+		got, err := p.object()
+		if err != nil {
+			t.Errorf("%q: %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: expected %#v, got %#v", tt.input, tt.want, got)
+		}
+	}
+
+	for _, input := range []string{"<< /A", "(open", "<4G>", "[1 2", "endobj"} {
+		p := parser{data: []byte(input)}
+		if _, err := p.object(); err == nil {
+			t.Errorf("%q: expected a syntax error", input)
+		}
+	}
+}
+
+// compressedPDF returns a PDF whose catalog and pages are in an object stream
+// indexed by a cross-reference stream with a PNG predictor
+func compressedPDF(t *testing.T) (data []byte, startxref int) {
+	t.Helper()
+	deflate := func(b []byte) []byte {
+		var buf bytes.Buffer
+		zw := zlib.NewWriter(&buf)
+		_, _ = zw.Write(b)
+		_ = zw.Close()
+		return buf.Bytes()
+	}
+
+	var buf bytes.Buffer
+	offsets := make([]int, 7)
+	buf.WriteString("%PDF-1.5\n%\xe2\xe3\xcf\xd3\n")
+	offsets[4] = buf.Len()
+	content := "BT /F1 12 Tf (Hello) Tj ET"
+	fmt.Fprintf(&buf, "4 0 obj\n<< /Length %d >>\nstream\n%s\nendstream\nendobj\n", len(content), content)
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 /MediaBox [0 0 612 792] >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R /CropBox [10 20 310 420] >>",
+	}
+	var header, body strings.Builder
+	for i, obj := range objects {
+		fmt.Fprintf(&header, "%d %d ", i+1, body.Len())
+		body.WriteString(obj + "\n")
+	}
+	stm := deflate([]byte(header.String() + body.String()))
+	offsets[5] = buf.Len()
+	// The length is indirect and stored in the next object
+	fmt.Fprintf(&buf, "5 0 obj\n<< /Type /ObjStm /N 3 /First %d /Filter /FlateDecode /Length 6 0 R >>\nstream\n", header.Len())
+	buf.Write(stm)
+	buf.WriteString("\nendstream\nendobj\n")
+	offsets[6] = buf.Len()
+	fmt.Fprintf(&buf, "6 0 obj\n%d\nendobj\n", len(stm))
+
+	startxref = buf.Len()
+	rows := [][]byte{{0, 0, 0, 255}, {2, 0, 5, 0}, {2, 0, 5, 1}, {2, 0, 5, 2}}
+	for _, n := range []int{4, 5, 6, 7} {
+		off := startxref
+		if n < 7 {
+			off = offsets[n]
+		}
+		rows = append(rows, []byte{1, byte(off >> 8), byte(off), 0})
+	}
+	var xref []byte
+	prev := make([]byte, 4)
+	for _, row := range rows {
+		xref = append(xref, 2)
+		for i := range row {
+			xref = append(xref, row[i]-prev[i])
+		}
+		prev = row
+	}
+	xref = deflate(xref)
+	fmt.Fprintf(&buf, "7 0 obj\n<< /Type /XRef /Size 8 /W [1 2 1] /Root 1 0 R /Filter /FlateDecode "+
+		"/DecodeParms << /Columns 4 /Predictor 12 >> /Length %d >>\nstream\n", len(xref))
+	buf.Write(xref)
+	fmt.Fprintf(&buf, "\nendstream\nendobj\nstartxref\n%d\n%%%%EOF\n", startxref)
+	return buf.Bytes(), startxref
+}
+
+func TestDocumentUpdate(t *testing.T) {
+	data, startxref := compressedPDF(t)
+	doc, err := Open(data)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	pages, err := doc.Pages()
+	if err != nil {
+		t.Fatalf("Pages failed: %v", err)
+	}
+	if len(pages) != 1 || pages[0].Ref != (Ref{Num: 3}) || pages[0].Box != [4]float64{10, 20, 310, 420} {
+		t.Fatalf("unexpected pages: %+v", pages)
+	}
+	obj, err := doc.Object(Ref{Num: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stream, ok := obj.(*Stream); !ok || string(stream.Data) != "BT /F1 12 Tf (Hello) Tj ET" {
+		t.Fatalf("unexpected content stream: %#v", obj)
+	}
+
+	update := doc.NewUpdate()
+	page := pages[0].Dict.Copy()
+	page["Rotate"] = -90
+	added := update.Add(NewFlateStream(Dict{}, []byte("q Q")))
+	page["Contents"] = Array{page["Contents"], added}
+	update.Replace(pages[0].Ref, page)
+	var out bytes.Buffer
+	if _, err := update.WriteTo(&out); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	if !bytes.HasPrefix(out.Bytes(), data) {
+		t.Fatal("update does not preserve the original file")
+	}
+
+	updated, err := Open(out.Bytes())
+	if err != nil {
+		t.Fatalf("Open of the update failed: %v\n%s", err, out.Bytes()[len(data):])
+	}
+	if !updated.xrefStream || updated.Trailer[Name("Prev")] != Number(fmt.Sprint(startxref)) || updated.Trailer[Name("Root")] != (Ref{Num: 1}) {
+		t.Errorf("unexpected trailer: %v", updated.Trailer)
+	}
+	pages, err = updated.Pages()
+	if err != nil || len(pages) != 1 || pages[0].Rotate != 270 {
+		t.Fatalf("unexpected updated pages: %+v, %v", pages, err)
+	}
+	contents, _ := pages[0].Dict[Name("Contents")].(Array)
+	if len(contents) != 2 || contents[1] != added {
+		t.Fatalf("unexpected contents: %v", contents)
+	}
+	obj, err = updated.Object(added)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded, err := updated.Decode(obj.(*Stream)); err != nil || string(decoded) != "q Q" {
+		t.Errorf("unexpected added stream: %q, %v", decoded, err)
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	data, _ := compressedPDF(t)
+	encrypted := bytes.Replace(data, []byte("/Root 1 0 R"), []byte("/Encrypt 9 0 R"), 1)
+	for name, input := range map[string][]byte{
+		"html":      []byte("<html></html>"),
+		"truncated": data[:len(data)/2],
+		"encrypted": encrypted,
+	} {
+		if _, err := Open(input); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := Open(encrypted); err != ErrEncrypted {
+		t.Errorf("expected ErrEncrypted, got %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// Update is an incremental update of a Document: new and replaced objects
+// appended to the unchanged original file with a cross-reference section of
+// the same kind as the original's latest one
+type Update struct {
+	doc     *Document
+	next    int
+	objects map[int]updateObject
+}
+
+type updateObject struct {
+	gen int
+	obj any
+}
+
+// NewUpdate starts an incremental update of d
+func (d *Document) NewUpdate() *Update {
+	next, _ := Int(d.Trailer[Name("Size")])
+	for num := range d.xref {
+		next = max(next, num+1)
+	}
+	return &Update{doc: d, next: max(next, 1), objects: map[int]updateObject{}}
+}
+
+// Add adds a new object and returns its reference
+func (u *Update) Add(obj any) Ref {
+	ref := Ref{Num: u.next}
+	u.next++
+	u.objects[ref.Num] = updateObject{obj: obj}
+	return ref
+}
+
+// Replace replaces the object ref refers to
+func (u *Update) Replace(ref Ref, obj any) {
+	u.objects[ref.Num] = updateObject{gen: ref.Gen, obj: obj}
+}
+
+// WriteTo writes the original file followed by the update
+func (u *Update) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	data := u.doc.data
+	// cw keeps the first error, which WriteTo returns
+	_, _ = cw.Write(data) //nolint:errcheck
+	if len(data) > 0 && data[len(data)-1] != '\n' && data[len(data)-1] != '\r' {
+		_, _ = io.WriteString(cw, "\n") //nolint:errcheck
+	}
+
+	nums := make([]int, 0, len(u.objects))
+	for num := range u.objects {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	offsets := map[int]int64{}
+	var buf bytes.Buffer
+	for _, num := range nums {
+		o := u.objects[num]
+		offsets[num] = cw.n
+		buf.Reset()
+		fmt.Fprintf(&buf, "%d %d obj\n", num, o.gen)
+		if err := writeIndirect(&buf, o.obj); err != nil {
+			return cw.n, err
+		}
+		buf.WriteString("\nendobj\n")
+		_, _ = cw.Write(buf.Bytes()) //nolint:errcheck
+	}
+
+	trailer := Dict{
+		"Size": u.next,
+		"Prev": u.doc.startxref,
+	}
+	for _, key := range []Name{"Root", "Info", "ID"} {
+		if v, ok := u.doc.Trailer[key]; ok {
+			trailer[key] = v
+		}
+	}
+	start := cw.n
+	buf.Reset()
+	if u.doc.xrefStream {
+		if err := u.writeXrefStream(&buf, nums, offsets, trailer, start); err != nil {
+			return cw.n, err
+		}
+	} else {
+		buf.WriteString("xref\n0 1\n0000000000 65535 f\r\n")
+		for _, run := range subsections(nums) {
+			fmt.Fprintf(&buf, "%d %d\n", run[0], len(run))
+			for _, num := range run {
+				fmt.Fprintf(&buf, "%010d %05d n\r\n", offsets[num], u.objects[num].gen)
+			}
+		}
+		buf.WriteString("trailer\n")
+		if err := writeObject(&buf, trailer); err != nil {
+			return cw.n, err
+		}
+		buf.WriteByte('\n')
+	}
+	fmt.Fprintf(&buf, "startxref\n%d\n%%%%EOF\n", start)
+	_, _ = cw.Write(buf.Bytes()) //nolint:errcheck
+	return cw.n, cw.err
+}
+
+// writeXrefStream writes the update's cross-reference stream, which lists itself
+func (u *Update) writeXrefStream(buf *bytes.Buffer, nums []int, offsets map[int]int64, trailer Dict, start int64) error {
+	self := u.next
+	trailer["Size"] = self + 1
+	nums = append(nums, self)
+	offsets[self] = start
+
+	var index Array
+	var rows []byte
+	for _, run := range subsections(nums) {
+		index = append(index, run[0], len(run))
+	}
+	for _, num := range nums {
+		gen := 0
+		if o, ok := u.objects[num]; ok {
+			gen = o.gen
+		}
+		off := offsets[num]
+		rows = append(rows, 1, byte(off>>24), byte(off>>16), byte(off>>8), byte(off), byte(gen>>8), byte(gen))
+	}
+	if start > 0xFFFFFFFF {
+		return errors.New("pdf: file too large for a cross-reference stream")
+	}
+	trailer["Type"] = Name("XRef")
+	trailer["W"] = Array{1, 4, 2}
+	trailer["Index"] = index
+	fmt.Fprintf(buf, "%d 0 obj\n", self)
+	if err := writeIndirect(buf, &Stream{Dict: trailer, Data: rows}); err != nil {
+		return err
+	}
+	buf.WriteString("\nendobj\n")
+	return nil
+}
+
+// writeIndirect writes the body of an indirect object, which may be a stream
+func writeIndirect(buf *bytes.Buffer, obj any) error {
+	stream, ok := obj.(*Stream)
+	if !ok {
+		return writeObject(buf, obj)
+	}
+	dict := stream.Dict.Copy()
+	dict["Length"] = Number(strconv.Itoa(len(stream.Data)))
+	if err := writeObject(buf, dict); err != nil {
+		return err
+	}
+	buf.WriteString("\nstream\n")
+	buf.Write(stream.Data)
+	buf.WriteString("\nendstream")
+	return nil
+}
+
+// subsections splits sorted object numbers into runs of consecutive numbers
+func subsections(nums []int) [][]int {
+	var runs [][]int
+	for i, num := range nums {
+		if i == 0 || num != nums[i-1]+1 {
+			runs = append(runs, nil)
+		}
+		runs[len(runs)-1] = append(runs[len(runs)-1], num)
+	}
+	return runs
+}
@@ -25,7 +25,6 @@ func (s *SDK) DeleteJob(ctx context.Context, jobID string) error {
 		return NewSDKError(ErrorTypeAPIError, "failed to build delete request", err)
 	}
 
-	req.Header.Set("X-API-KEY", s.config.APIKey)
 	if s.config.UserAgent != "" {
 		req.Header.Set("User-Agent", s.config.UserAgent)
 	}
@@ -37,7 +36,7 @@ func (s *SDK) DeleteJob(ctx context.Context, jobID string) error {
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		return NewSDKError(ErrorTypeAPIError, "failed to delete job", err)
+		return s.handleAPIError(ctx, err, nil, "failed to delete job")
 	}
 	defer func() {
 		if closeErr := resp.Body.Close(); closeErr != nil {
@@ -49,14 +48,20 @@ func (s *SDK) DeleteJob(ctx context.Context, jobID string) error {
 	if resp.StatusCode >= 300 {
 		body, err := io.ReadAll(resp.Body)
 		if err != nil {
-			return NewSDKError(ErrorTypeAPIError, fmt.Sprintf("failed to delete job: %s (failed to read response body)", resp.Status), err)
+			sdkErr := NewSDKError(ErrorTypeAPIError, fmt.Sprintf("failed to delete job: %s (failed to read response body)", resp.Status), err)
+			sdkErr.StatusCode = resp.StatusCode
+			return sdkErr
 		}
 		message := strings.TrimSpace(string(body))
 		if message == "" {
 			message = resp.Status
 		}
-		return NewSDKError(ErrorTypeAPIError, fmt.Sprintf("failed to delete job: %s", message), nil)
+		s.logger.ErrorContext(ctx, "failed to delete job", "job_id", jobID, "status", resp.StatusCode)
+		sdkErr := NewSDKError(ErrorTypeAPIError, fmt.Sprintf("failed to delete job: %s", message), nil)
+		sdkErr.StatusCode = resp.StatusCode
+		return sdkErr
 	}
 
+	s.logger.InfoContext(ctx, "job deleted", "job_id", jobID)
 	return nil
 }
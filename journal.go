@@ -0,0 +1,145 @@
+package ocr
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// JournalState is the state of a batch item recorded in a BatchJournal
+type JournalState string
+
+const (
+	// JournalQueued means the item is about to be submitted
+	JournalQueued JournalState = "queued"
+	// JournalUploaded means a job was created for the item and is being processed
+	JournalUploaded JournalState = "uploaded"
+	// JournalCompleted means the item's job finished successfully
+	JournalCompleted JournalState = "completed"
+	// JournalFailed means the item could not be processed
+	JournalFailed JournalState = "failed"
+)
+
+// JournalEntry records a state transition of a batch item
+type JournalEntry struct {
+	Key     string       `json:"key"`
+	State   JournalState `json:"state"`
+	JobID   string       `json:"job_id,omitempty"`
+	Credits int          `json:"credits,omitempty"`
+	Error   string       `json:"error,omitempty"`
+	Time    time.Time    `json:"time"`
+}
+
+// BatchJournal persists the progress of a batch so that a restarted batch can
+// skip finished items and re-attach to jobs that are still processing instead
+// of submitting them again. Implementations must be safe for concurrent use.
+type BatchJournal interface {
+	// Load returns the latest entry recorded for each item key
+	Load(ctx context.Context) (map[string]JournalEntry, error)
+	// Record persists an entry; it must be durable once Record returns
+	Record(ctx context.Context, entry JournalEntry) error
+}
+
+// FileJournal is a BatchJournal backed by an append-only JSON Lines file
+type FileJournal struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// OpenFileJournal opens or creates the journal file at path.
+// A partial entry left at the end of the file by a crash is discarded.
+func OpenFileJournal(path string) (*FileJournal, error) {
+	if err := truncatePartialEntry(path); err != nil {
+		return nil, NewSDKError(ErrorTypeInvalidConfig, "failed to repair batch journal", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600) // #nosec G304 - path is provided by the caller
+	if err != nil {
+		return nil, NewSDKError(ErrorTypeInvalidConfig, "failed to open batch journal", err)
+	}
+	return &FileJournal{path: path, file: file}, nil
+}
+
+// Load implements BatchJournal
+func (j *FileJournal) Load(_ context.Context) (map[string]JournalEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	file, err := os.Open(j.path)
+	if err != nil {
+		return nil, NewSDKError(ErrorTypeUnknown, "failed to read batch journal", err)
+	}
+	defer func() { _ = file.Close() }() //nolint:errcheck
+
+	entries := make(map[string]JournalEntry)
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// Anything after the last newline is an incomplete entry
+			return entries, nil
+		}
+		if err != nil {
+			return nil, NewSDKError(ErrorTypeUnknown, "failed to read batch journal", err)
+		}
+
+		var entry JournalEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, NewSDKError(ErrorTypeUnknown, "corrupt batch journal entry", err)
+		}
+		entries[entry.Key] = entry
+	}
+}
+
+// Record implements BatchJournal by appending the entry and syncing the file
+func (j *FileJournal) Record(_ context.Context, entry JournalEntry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return NewSDKError(ErrorTypeUnknown, "failed to encode batch journal entry", err)
+	}
+	line = append(line, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, err := j.file.Write(line); err != nil {
+		return NewSDKError(ErrorTypeUnknown, "failed to write batch journal", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return NewSDKError(ErrorTypeUnknown, "failed to sync batch journal", err)
+	}
+	return nil
+}
+
+// Close closes the journal file
+func (j *FileJournal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
+
+// truncatePartialEntry cuts the file at path after its last complete line
+func truncatePartialEntry(path string) error {
+	data, err := os.ReadFile(path) // #nosec G304 - path is provided by the caller
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 || data[len(data)-1] == '\n' {
+		return nil
+	}
+	return os.Truncate(path, int64(bytes.LastIndexByte(data, '\n')+1))
+}
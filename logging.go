@@ -0,0 +1,138 @@
+package ocr
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// redacted replaces secrets in log output
+const redacted = "[REDACTED]"
+
+// signatureParams matches credentials in presigned storage URLs (S3, GCS and Azure style)
+var signatureParams = regexp.MustCompile(`(?i)((?:x-amz-|x-goog-)?(?:signature|credential|security-token)|sig)=[^&\s"']+`)
+
+// newLogger returns the SDK's logger, which drops everything when no logger is configured
+func newLogger(logger *slog.Logger, secrets *secretSet) *slog.Logger {
+	if logger == nil {
+		return slog.New(slog.DiscardHandler)
+	}
+	return slog.New(&redactHandler{inner: logger.Handler(), secrets: secrets})
+}
+
+// redactURL strips the query, which carries the signature of presigned URLs
+func redactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	clean := *u
+	clean.RawQuery = ""
+	clean.User = nil
+	return clean.String()
+}
+
+// redactURLError removes the query of the URL embedded in errors returned by http.Client
+func redactURLError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		if u, parseErr := url.Parse(urlErr.URL); parseErr == nil {
+			urlErr.URL = redactURL(u)
+		}
+	}
+	return err
+}
+
+// redactHandler removes API keys and presigned URL signatures from messages and attributes
+type redactHandler struct {
+	inner   slog.Handler
+	secrets *secretSet
+}
+
+// Enabled implements slog.Handler
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, h.redact(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(h.redactAttr(a))
+		return true
+	})
+	return h.inner.Handle(ctx, out)
+}
+
+// WithAttrs implements slog.Handler
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clean := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		clean[i] = h.redactAttr(a)
+	}
+	return &redactHandler{inner: h.inner.WithAttrs(clean), secrets: h.secrets}
+}
+
+// WithGroup implements slog.Handler
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{inner: h.inner.WithGroup(name), secrets: h.secrets}
+}
+
+func (h *redactHandler) redactAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, h.redact(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		clean := make([]any, len(group))
+		for i, ga := range group {
+			clean[i] = h.redactAttr(ga)
+		}
+		return slog.Group(a.Key, clean...)
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return slog.String(a.Key, h.redact(err.Error()))
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
+
+func (h *redactHandler) redact(s string) string {
+	for _, secret := range h.secrets.list() {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	return signatureParams.ReplaceAllString(s, "$1="+redacted)
+}
+
+// loggingTransport logs every API request with its status and duration
+type loggingTransport struct {
+	base   http.RoundTripper
+	logger *slog.Logger
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	started := time.Now()
+	resp, err := t.base.RoundTrip(req)
+
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("url", redactURL(req.URL)),
+		slog.Duration("duration", time.Since(started)),
+	}
+	switch {
+	case err != nil:
+		t.logger.LogAttrs(req.Context(), slog.LevelWarn, "api request failed", append(attrs, slog.Any("error", err))...)
+	case resp.StatusCode == http.StatusTooManyRequests:
+		t.logger.LogAttrs(req.Context(), slog.LevelWarn, "api request throttled",
+			append(attrs, slog.Int("status", resp.StatusCode), slog.String("retry_after", resp.Header.Get("Retry-After")))...)
+	default:
+		t.logger.LogAttrs(req.Context(), slog.LevelDebug, "api request", append(attrs, slog.Int("status", resp.StatusCode))...)
+	}
+	return resp, err
+}
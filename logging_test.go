@@ -0,0 +1,128 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLogging(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	var uploads atomic.Int32
+	sdk := newTestTeamSDKWithConfig(t, newFileProcessingMux(&uploads), func(c *Config) {
+		c.Logger = logger
+	})
+	ctx := context.Background()
+	if _, err := sdk.ProcessFileAndWait(ctx, bytes.NewReader([]byte("%PDF")), "doc.pdf", WithFormat(FormatMarkdown)); err != nil {
+		t.Fatalf("ProcessFileAndWait failed: %v", err)
+	}
+
+	// Failed uploads return errors embedding the presigned URL
+	sdk.uploadClient = wrapClient(sdk.uploadClient, transportOptions{chain: []Middleware{
+		func(*http.Request, RoundTripFunc) (*http.Response, error) { return nil, errors.New("storage unavailable") },
+	}})
+	_, err := sdk.ProcessFile(ctx, bytes.NewReader([]byte("%PDF")), "doc.pdf", WithFormat(FormatMarkdown))
+	if err == nil || strings.Contains(err.Error(), "deadbeef") {
+		t.Errorf("expected upload error without signature, got %v", err)
+	}
+
+	out := buf.String()
+	for _, event := range []string{"upload initiated", "part uploaded", "upload completed", "job completed", "api request", "part upload failed"} {
+		if !strings.Contains(out, `"msg":"`+event+`"`) {
+			t.Errorf("expected %q event in log output:\n%s", event, out)
+		}
+	}
+	for _, secret := range []string{"test-key", "deadbeef", "AKIA123"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("log output contains secret %q:\n%s", secret, out)
+		}
+	}
+}
+
+func TestLoggingLevels(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	var calls atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /ocr/status/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") == "missing" {
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+			return
+		}
+		calls.Add(1)
+		http.Error(w, `{"message":"unavailable"}`, http.StatusServiceUnavailable)
+	})
+	sdk := newTestTeamSDKWithConfig(t, mux, func(c *Config) {
+		c.Logger = logger
+		c.Retry = &RetryPolicy{MaxAttempts: 2, InitialDelay: time.Millisecond}
+	})
+
+	ctx := context.Background()
+	if _, err := sdk.GetJobStatus(ctx, "missing"); err == nil {
+		t.Fatal("expected error for missing job")
+	}
+	if _, err := sdk.GetJobStatus(ctx, "job_1"); err == nil {
+		t.Fatal("expected error for unavailable server")
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected 2 attempts, got %d", calls.Load())
+	}
+
+	var levels []string
+	var retried bool
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry struct {
+			Level   string `json:"level"`
+			Msg     string `json:"msg"`
+			Status  int    `json:"status"`
+			Attempt int    `json:"attempt"`
+			Delay   int64  `json:"delay"`
+		}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		switch entry.Msg {
+		case "failed to get job status":
+			levels = append(levels, fmt.Sprintf("%s %d", entry.Level, entry.Status))
+		case "retrying api request":
+			retried = entry.Attempt == 1 && entry.Status == http.StatusServiceUnavailable && entry.Delay == int64(time.Millisecond)
+		}
+	}
+	if !equalStrings(levels, []string{"WARN 404", "ERROR 503"}) {
+		t.Errorf("unexpected API error log levels %v:\n%s", levels, buf.String())
+	}
+	if !retried {
+		t.Errorf("expected retry event with attempt, status and delay:\n%s", buf.String())
+	}
+}
+
+func TestRedactHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newLogger(slog.New(slog.NewTextHandler(&buf, nil)), newSecretSet("sk-live-123"))
+
+	logger.With("key", "sk-live-123").Error("request with sk-live-123 failed",
+		"error", errors.New(`Put "https://bucket.s3/doc?X-Amz-Signature=abc&sig=def": EOF`),
+		slog.Group("request", "url", "https://bucket/doc?X-Goog-Signature=ghi"),
+	)
+
+	out := buf.String()
+	for _, secret := range []string{"sk-live-123", "abc", "def", "ghi"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("log output contains secret %q: %s", secret, out)
+		}
+	}
+	if !strings.Contains(out, "X-Amz-Signature="+redacted) {
+		t.Errorf("expected redaction marker in %s", out)
+	}
+}
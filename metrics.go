@@ -0,0 +1,94 @@
+package ocr
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// submissionRetention is how long the submission time of a job nobody waits
+// for is kept; waits for older jobs measure time to completion from their start
+const submissionRetention = 24 * time.Hour
+
+// submissionSweepInterval is how often expired submission times are dropped
+const submissionSweepInterval = time.Hour
+
+// submissionTimes remembers when jobs were submitted, so that time to
+// completion covers queueing and processing before the wait started
+type submissionTimes struct {
+	mu        sync.Mutex
+	times     map[string]time.Time
+	lastSweep time.Time
+}
+
+// add records the submission of a job. Expired entries are dropped at most
+// once per submissionSweepInterval, so that large batches add in constant time.
+func (t *submissionTimes) add(jobID string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.times == nil {
+		t.times = make(map[string]time.Time)
+		t.lastSweep = now
+	}
+	if now.Sub(t.lastSweep) >= submissionSweepInterval {
+		for id, submitted := range t.times {
+			if now.Sub(submitted) > submissionRetention {
+				delete(t.times, id)
+			}
+		}
+		t.lastSweep = now
+	}
+	t.times[jobID] = now
+}
+
+// take returns and forgets the submission time of a job
+func (t *submissionTimes) take(jobID string) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	submitted, ok := t.times[jobID]
+	delete(t.times, jobID)
+	return submitted, ok
+}
+
+type waitMetricsKey struct{}
+
+// WithoutWaitMetrics returns a context under which waiting for a job reports
+// no completion, failure or abandonment to Config.Metrics. Waiters that report
+// the outcome themselves with RecordWait use it for the waits they delegate.
+func WithoutWaitMetrics(ctx context.Context) context.Context {
+	return context.WithValue(ctx, waitMetricsKey{}, true)
+}
+
+// RecordWait reports the outcome of waiting for a job that started at started
+// to Config.Metrics, for waiters other than WaitUntilDone such as
+// webhook.Waiter. Time to completion is measured from the job's submission
+// when it was submitted by this SDK and from started otherwise.
+func (s *SDK) RecordWait(jobID string, started time.Time, result *OCRResult, err error) {
+	switch {
+	case err == nil:
+		if submitted, ok := s.submissions.take(jobID); ok {
+			started = submitted
+		}
+		credits := 0
+		if result != nil {
+			credits = result.Credits
+		}
+		s.recorder().JobCompleted(time.Since(started), credits)
+	case errorTypeOf(err) == ErrorTypeJobError:
+		s.submissions.take(jobID)
+		s.recorder().JobFailed(string(ErrorTypeJobError))
+	default:
+		// The job may still finish and be waited for again
+		s.recorder().WaitAbandoned(string(errorTypeOf(err)))
+	}
+}
+
+// recordWait is RecordWait unless ctx disables wait metrics
+func (s *SDK) recordWait(ctx context.Context, jobID string, started time.Time, result *OCRResult, err error) {
+	if disabled, _ := ctx.Value(waitMetricsKey{}).(bool); disabled {
+		return
+	}
+	s.RecordWait(jobID, started, result, err)
+}
@@ -0,0 +1,53 @@
+// Package metrics defines the interface through which the SDK reports its
+// activity, so that it can be exported to any metrics system.
+package metrics
+
+import "time"
+
+// Job sources reported to Recorder.JobSubmitted
+const (
+	SourceFile = "file"
+	SourceURL  = "url"
+)
+
+// Recorder receives measurements of SDK activity.
+// Implementations must be safe for concurrent use.
+type Recorder interface {
+	// JobSubmitted is called when a job was created from a file or URL
+	JobSubmitted(source string)
+	// JobCompleted is called when waiting for a job returned its result, with
+	// the time since the job was submitted, or since the wait started for jobs
+	// submitted elsewhere
+	JobCompleted(timeToCompletion time.Duration, credits int)
+	// JobFailed is called when submitting a job failed or the job itself failed,
+	// with the ocr.ErrorType of the failure
+	JobFailed(errorType string)
+	// WaitAbandoned is called when waiting for a job stopped before the job
+	// finished, e.g. on a timeout, with the ocr.ErrorType of the failure
+	WaitAbandoned(errorType string)
+	// PartUploaded is called for every file part uploaded to storage
+	PartUploaded(bytes int, latency time.Duration)
+	// PollAttempt is called for every job status poll with the reported status
+	PollAttempt(status string)
+}
+
+// Nop is a Recorder that discards all measurements
+type Nop struct{}
+
+// JobSubmitted implements Recorder
+func (Nop) JobSubmitted(string) {}
+
+// JobCompleted implements Recorder
+func (Nop) JobCompleted(time.Duration, int) {}
+
+// JobFailed implements Recorder
+func (Nop) JobFailed(string) {}
+
+// WaitAbandoned implements Recorder
+func (Nop) WaitAbandoned(string) {}
+
+// PartUploaded implements Recorder
+func (Nop) PartUploaded(int, time.Duration) {}
+
+// PollAttempt implements Recorder
+func (Nop) PollAttempt(string) {}
@@ -0,0 +1,159 @@
+// Package prometheus exports SDK metrics to Prometheus.
+//
+//	collector := prometheus.NewCollector(prometheus.Options{})
+//	registry.MustRegister(collector)
+//	config.Metrics = collector
+package prometheus
+
+import (
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+
+	"github.com/leapocr/leapocr-go/metrics"
+)
+
+// DefaultNamespace prefixes every metric name unless Options.Namespace is set
+const DefaultNamespace = "leapocr"
+
+// Options configures a Collector
+type Options struct {
+	// Namespace prefixes metric names (default: DefaultNamespace)
+	Namespace string
+	// ConstLabels are added to every metric, e.g. to tell services apart
+	ConstLabels prom.Labels
+	// CompletionBuckets are the histogram buckets of time-to-completion in seconds
+	// (default: 1s to about 17 minutes)
+	CompletionBuckets []float64
+	// UploadBuckets are the histogram buckets of part upload latency in seconds
+	// (default: prometheus.DefBuckets)
+	UploadBuckets []float64
+}
+
+// Collector is a metrics.Recorder that exposes the SDK's activity as Prometheus metrics
+type Collector struct {
+	submitted      *prom.CounterVec
+	completed      prom.Counter
+	failed         *prom.CounterVec
+	abandoned      *prom.CounterVec
+	credits        prom.Counter
+	completionTime prom.Histogram
+	uploadBytes    prom.Counter
+	uploadLatency  prom.Histogram
+	polls          *prom.CounterVec
+}
+
+var (
+	_ metrics.Recorder = (*Collector)(nil)
+	_ prom.Collector   = (*Collector)(nil)
+)
+
+// NewCollector creates a Collector; register it with a prometheus.Registerer
+// and set it as ocr.Config.Metrics
+func NewCollector(opts Options) *Collector {
+	if opts.Namespace == "" {
+		opts.Namespace = DefaultNamespace
+	}
+	if opts.CompletionBuckets == nil {
+		opts.CompletionBuckets = prom.ExponentialBuckets(1, 2, 11)
+	}
+	if opts.UploadBuckets == nil {
+		opts.UploadBuckets = prom.DefBuckets
+	}
+
+	ns, labels := opts.Namespace, opts.ConstLabels
+	return &Collector{
+		submitted: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: ns, Name: "jobs_submitted_total", ConstLabels: labels,
+			Help: "Number of OCR jobs submitted, by source (file or url).",
+		}, []string{"source"}),
+		completed: prom.NewCounter(prom.CounterOpts{
+			Namespace: ns, Name: "jobs_completed_total", ConstLabels: labels,
+			Help: "Number of OCR jobs that completed successfully.",
+		}),
+		failed: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: ns, Name: "jobs_failed_total", ConstLabels: labels,
+			Help: "Number of failed job submissions and failed jobs, by SDK error type.",
+		}, []string{"error_type"}),
+		abandoned: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: ns, Name: "waits_abandoned_total", ConstLabels: labels,
+			Help: "Number of waits for jobs that stopped before the job finished, by SDK error type.",
+		}, []string{"error_type"}),
+		credits: prom.NewCounter(prom.CounterOpts{
+			Namespace: ns, Name: "credits_consumed_total", ConstLabels: labels,
+			Help: "Credits consumed by completed jobs.",
+		}),
+		completionTime: prom.NewHistogram(prom.HistogramOpts{
+			Namespace: ns, Name: "job_completion_seconds", ConstLabels: labels,
+			Help:    "Time from job submission to completion.",
+			Buckets: opts.CompletionBuckets,
+		}),
+		uploadBytes: prom.NewCounter(prom.CounterOpts{
+			Namespace: ns, Name: "upload_bytes_total", ConstLabels: labels,
+			Help: "Bytes uploaded to storage.",
+		}),
+		uploadLatency: prom.NewHistogram(prom.HistogramOpts{
+			Namespace: ns, Name: "upload_part_duration_seconds", ConstLabels: labels,
+			Help:    "Latency of file part uploads to storage.",
+			Buckets: opts.UploadBuckets,
+		}),
+		polls: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: ns, Name: "poll_attempts_total", ConstLabels: labels,
+			Help: "Number of job status polls, by reported status.",
+		}, []string{"status"}),
+	}
+}
+
+// JobSubmitted implements metrics.Recorder
+func (c *Collector) JobSubmitted(source string) {
+	c.submitted.WithLabelValues(source).Inc()
+}
+
+// JobCompleted implements metrics.Recorder
+func (c *Collector) JobCompleted(timeToCompletion time.Duration, credits int) {
+	c.completed.Inc()
+	c.completionTime.Observe(timeToCompletion.Seconds())
+	c.credits.Add(float64(credits))
+}
+
+// JobFailed implements metrics.Recorder
+func (c *Collector) JobFailed(errorType string) {
+	c.failed.WithLabelValues(errorType).Inc()
+}
+
+// WaitAbandoned implements metrics.Recorder
+func (c *Collector) WaitAbandoned(errorType string) {
+	c.abandoned.WithLabelValues(errorType).Inc()
+}
+
+// PartUploaded implements metrics.Recorder
+func (c *Collector) PartUploaded(bytes int, latency time.Duration) {
+	c.uploadBytes.Add(float64(bytes))
+	c.uploadLatency.Observe(latency.Seconds())
+}
+
+// PollAttempt implements metrics.Recorder
+func (c *Collector) PollAttempt(status string) {
+	c.polls.WithLabelValues(status).Inc()
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prom.Desc) {
+	for _, m := range c.collectors() {
+		m.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prom.Metric) {
+	for _, m := range c.collectors() {
+		m.Collect(ch)
+	}
+}
+
+func (c *Collector) collectors() []prom.Collector {
+	return []prom.Collector{
+		c.submitted, c.completed, c.failed, c.abandoned, c.credits,
+		c.completionTime, c.uploadBytes, c.uploadLatency, c.polls,
+	}
+}
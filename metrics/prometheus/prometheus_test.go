@@ -0,0 +1,55 @@
+package prometheus
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	collector := NewCollector(Options{ConstLabels: prom.Labels{"service": "billing"}})
+	registry := prom.NewPedanticRegistry()
+	if err := registry.Register(collector); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	collector.JobSubmitted("file")
+	collector.JobSubmitted("file")
+	collector.JobSubmitted("url")
+	collector.JobCompleted(3*time.Second, 4)
+	collector.JobCompleted(5*time.Second, 2)
+	collector.JobFailed("job_error")
+	collector.WaitAbandoned("timeout")
+	collector.PartUploaded(1024, 50*time.Millisecond)
+	collector.PollAttempt("processing")
+	collector.PollAttempt("completed")
+
+	if got := testutil.ToFloat64(collector.submitted.WithLabelValues("file")); got != 2 {
+		t.Errorf("file submissions = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(collector.credits); got != 6 {
+		t.Errorf("credits = %v, want 6", got)
+	}
+	if got := testutil.ToFloat64(collector.uploadBytes); got != 1024 {
+		t.Errorf("upload bytes = %v, want 1024", got)
+	}
+
+	expected := `
+# HELP leapocr_jobs_failed_total Number of failed job submissions and failed jobs, by SDK error type.
+# TYPE leapocr_jobs_failed_total counter
+leapocr_jobs_failed_total{error_type="job_error",service="billing"} 1
+# HELP leapocr_waits_abandoned_total Number of waits for jobs that stopped before the job finished, by SDK error type.
+# TYPE leapocr_waits_abandoned_total counter
+leapocr_waits_abandoned_total{error_type="timeout",service="billing"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "leapocr_jobs_failed_total", "leapocr_waits_abandoned_total"); err != nil {
+		t.Error(err)
+	}
+
+	if count, err := testutil.GatherAndCount(registry); err != nil || count != 11 {
+		t.Errorf("expected 11 series, got %d (%v)", count, err)
+	}
+}
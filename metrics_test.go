@@ -0,0 +1,149 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/leapocr/leapocr-go/metrics"
+)
+
+// recordingMetrics is a metrics.Recorder remembering every call
+type recordingMetrics struct {
+	mu        sync.Mutex
+	submitted []string
+	completed []int
+	durations []time.Duration
+	failed    []string
+	abandoned []string
+	bytes     int
+	polls     []string
+}
+
+func (m *recordingMetrics) JobSubmitted(source string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.submitted = append(m.submitted, source)
+}
+
+func (m *recordingMetrics) JobCompleted(timeToCompletion time.Duration, credits int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.completed = append(m.completed, credits)
+	m.durations = append(m.durations, timeToCompletion)
+}
+
+func (m *recordingMetrics) JobFailed(errorType string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failed = append(m.failed, errorType)
+}
+
+func (m *recordingMetrics) WaitAbandoned(errorType string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.abandoned = append(m.abandoned, errorType)
+}
+
+func (m *recordingMetrics) PartUploaded(n int, _ time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bytes += n
+}
+
+func (m *recordingMetrics) PollAttempt(status string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.polls = append(m.polls, status)
+}
+
+func TestMetrics(t *testing.T) {
+	var uploads atomic.Int32
+	mux := newFileProcessingMux(&uploads)
+	mux.HandleFunc("POST /ocr/uploads/url", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	mux.HandleFunc("GET /ocr/status/failed_1", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"job_id":"failed_1","status":"failed","error_message":"unreadable"}`)
+	})
+
+	recorder := &recordingMetrics{}
+	sdk := newTestTeamSDKWithConfig(t, mux, func(c *Config) {
+		c.Metrics = recorder
+	})
+
+	ctx := context.Background()
+	content := []byte("%PDF-1.4")
+	job, err := sdk.ProcessFile(ctx, bytes.NewReader(content), "doc.pdf", WithFormat(FormatMarkdown))
+	if err != nil {
+		t.Fatalf("ProcessFile failed: %v", err)
+	}
+	// Time to completion counts from the submission, not from the wait
+	time.Sleep(20 * time.Millisecond)
+	if _, err := sdk.WaitUntilDone(ctx, job.ID); err != nil {
+		t.Fatalf("WaitUntilDone failed: %v", err)
+	}
+	if _, err := sdk.ProcessURL(ctx, "https://example.com/doc.pdf", WithFormat(FormatMarkdown)); err == nil {
+		t.Fatal("expected ProcessURL to fail")
+	}
+	if _, err := sdk.WaitUntilDone(ctx, "failed_1"); err == nil {
+		t.Fatal("expected failed job")
+	}
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := sdk.WaitUntilDone(canceled, "job_1"); err == nil {
+		t.Fatal("expected canceled wait to fail")
+	}
+	// Waits reporting their outcome themselves are not recorded twice
+	if _, err := sdk.WaitUntilDone(WithoutWaitMetrics(canceled), "job_1"); err == nil {
+		t.Fatal("expected canceled wait to fail")
+	}
+
+	if len(recorder.submitted) != 1 || recorder.submitted[0] != metrics.SourceFile {
+		t.Errorf("unexpected submissions: %v", recorder.submitted)
+	}
+	if len(recorder.completed) != 1 || recorder.completed[0] != 2 {
+		t.Errorf("unexpected completions: %v", recorder.completed)
+	}
+	if len(recorder.durations) != 1 || recorder.durations[0] < 20*time.Millisecond {
+		t.Errorf("expected time to completion from submission, got %v", recorder.durations)
+	}
+	if !equalStrings(recorder.failed, []string{string(ErrorTypeAPIError), string(ErrorTypeJobError)}) {
+		t.Errorf("unexpected failures: %v", recorder.failed)
+	}
+	if len(recorder.abandoned) != 1 || recorder.abandoned[0] == string(ErrorTypeJobError) {
+		t.Errorf("unexpected abandoned waits: %v", recorder.abandoned)
+	}
+	if recorder.bytes != len(content) {
+		t.Errorf("uploaded bytes = %d, want %d", recorder.bytes, len(content))
+	}
+	if !equalStrings(recorder.polls, []string{"completed", "failed"}) {
+		t.Errorf("unexpected polls: %v", recorder.polls)
+	}
+}
+
+func TestSubmissionTimesExpiry(t *testing.T) {
+	var times submissionTimes
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	times.add("old", start)
+	times.add("recent", start.Add(submissionRetention))
+
+	// Expired entries are kept until the next sweep
+	times.add("job_1", start.Add(submissionRetention+time.Minute))
+	if len(times.times) != 3 {
+		t.Errorf("expected no sweep within the interval, got %v", times.times)
+	}
+	times.add("job_2", start.Add(submissionRetention+submissionSweepInterval))
+	if _, ok := times.times["old"]; ok || len(times.times) != 3 {
+		t.Errorf("expected the expired entry to be swept, got %v", times.times)
+	}
+	if submitted, ok := times.take("recent"); !ok || !submitted.Equal(start.Add(submissionRetention)) {
+		t.Errorf("unexpected submission time %v, %v", submitted, ok)
+	}
+}
@@ -0,0 +1,100 @@
+package ocr
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// RoundTripFunc sends a request and returns its response
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+// Middleware intercepts an outgoing request. It may modify the request, which
+// is a clone owned by the chain, and must call next to send it unless it
+// responds itself. Middleware runs in the order it is configured.
+type Middleware func(req *http.Request, next RoundTripFunc) (*http.Response, error)
+
+// SetHeader returns middleware setting a header on every request
+func SetHeader(name, value string) Middleware {
+	return func(req *http.Request, next RoundTripFunc) (*http.Response, error) {
+		req.Header.Set(name, value)
+		return next(req)
+	}
+}
+
+// middlewareTransport runs a middleware chain in front of the wrapped transport
+type middlewareTransport struct {
+	base  http.RoundTripper
+	chain []Middleware
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *middlewareTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(req.Context())
+	return t.next(0)(req)
+}
+
+// next returns the function invoking the chain from position i onwards
+func (t *middlewareTransport) next(i int) RoundTripFunc {
+	if i == len(t.chain) {
+		return t.base.RoundTrip
+	}
+	return func(req *http.Request) (*http.Response, error) {
+		return t.chain[i](req, t.next(i+1))
+	}
+}
+
+// transportOptions selects the layers wrapClient adds in front of a client's transport
+type transportOptions struct {
+	credentials CredentialsProvider
+	secrets     *secretSet
+	retry       *RetryPolicy
+	limit       *RateLimit
+	timeout     time.Duration
+	chain       []Middleware
+	logger      *slog.Logger
+	propagator  propagation.TextMapPropagator
+}
+
+// wrapClient returns a copy of client sending requests through trace context
+// propagation, authentication, the middleware chain, retries, the rate limiter,
+// the per-request timeout and request logging, in that order; client is
+// returned as is when none of them is set
+func wrapClient(client *http.Client, opts transportOptions) *http.Client {
+	if opts.credentials == nil && opts.retry == nil && opts.limit == nil && opts.timeout <= 0 && len(opts.chain) == 0 && opts.logger == nil && opts.propagator == nil {
+		return client
+	}
+
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if opts.logger != nil {
+		transport = &loggingTransport{base: transport, logger: opts.logger}
+	}
+	if opts.timeout > 0 {
+		transport = &timeoutTransport{base: transport, timeout: opts.timeout}
+	}
+	if opts.limit != nil {
+		transport = &limitedTransport{base: transport, limiter: newRateLimiter(*opts.limit)}
+	}
+	if opts.retry != nil {
+		transport = &retryTransport{base: transport, policy: applyRetryDefaults(*opts.retry), logger: opts.logger}
+	}
+	if len(opts.chain) > 0 {
+		transport = &middlewareTransport{base: transport, chain: opts.chain}
+	}
+	if opts.credentials != nil {
+		transport = &credentialsTransport{base: transport, provider: opts.credentials, secrets: opts.secrets}
+	}
+	if opts.propagator != nil {
+		transport = &propagatingTransport{base: transport, propagator: opts.propagator}
+	}
+
+	wrapped := *client
+	wrapped.Transport = transport
+	return &wrapped
+}
@@ -0,0 +1,104 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// headerTransport simulates an auth-injecting transport configured on Config.HTTPClient
+type headerTransport struct {
+	name, value string
+}
+
+func (t headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set(t.name, t.value)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestMiddlewareChains(t *testing.T) {
+	var uploads atomic.Int32
+	var mu sync.Mutex
+	seen := make(map[string]http.Header)
+
+	files := newFileProcessingMux(&uploads)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen[r.Method+" "+r.URL.Path] = r.Header.Clone()
+		mu.Unlock()
+		files.ServeHTTP(w, r)
+	})
+
+	var order []string
+	trace := func(name string) Middleware {
+		return func(req *http.Request, next RoundTripFunc) (*http.Response, error) {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return next(req)
+		}
+	}
+
+	sdk := newTestTeamSDKWithConfig(t, handler, func(c *Config) {
+		c.HTTPClient = &http.Client{Transport: headerTransport{"Authorization", "Bearer secret"}}
+		c.APIMiddleware = []Middleware{trace("api-1"), trace("api-2"), SetHeader("X-Api-Tag", "api")}
+		c.UploadMiddleware = []Middleware{trace("upload"), SetHeader("X-Upload-Tag", "upload")}
+	})
+
+	ctx := context.Background()
+	if _, err := sdk.ProcessFile(ctx, bytes.NewReader([]byte("%PDF")), "doc.pdf", WithFormat(FormatMarkdown)); err != nil {
+		t.Fatalf("ProcessFile failed: %v", err)
+	}
+
+	api := seen["POST /ocr/uploads/direct"]
+	if api.Get("X-Api-Tag") != "api" || api.Get("Authorization") == "" || api.Get("X-Upload-Tag") != "" {
+		t.Errorf("unexpected API request headers: %v", api)
+	}
+	upload := seen["PUT /upload"]
+	if upload.Get("X-Upload-Tag") != "upload" || upload.Get("X-Api-Tag") != "" {
+		t.Errorf("unexpected upload request headers: %v", upload)
+	}
+	if upload.Get("Authorization") != "" || upload.Get("X-Api-Key") != "" {
+		t.Errorf("credentials leaked to the storage upload: %v", upload)
+	}
+
+	want := []string{"api-1", "api-2", "upload", "api-1", "api-2"}
+	if len(order) != len(want) {
+		t.Fatalf("middleware order = %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("middleware order = %v, want %v", order, want)
+		}
+	}
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	var called atomic.Bool
+	handler := http.HandlerFunc(func(http.ResponseWriter, *http.Request) { called.Store(true) })
+
+	sdk := newTestTeamSDKWithConfig(t, handler, func(c *Config) {
+		c.APIMiddleware = []Middleware{func(req *http.Request, _ RoundTripFunc) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Header:     http.Header{},
+				Body:       http.NoBody,
+				Request:    req,
+			}, nil
+		}}
+	})
+
+	err := sdk.DeleteJob(context.Background(), "job_1")
+	var sdkErr *SDKError
+	if !errors.As(err, &sdkErr) || sdkErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected API error with the short-circuited status, got %v", err)
+	}
+	if called.Load() {
+		t.Error("expected request not to reach the server")
+	}
+}
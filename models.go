@@ -0,0 +1,36 @@
+package ocr
+
+import (
+	"context"
+)
+
+// ModelInfo describes an OCR model that can be passed to WithModel or WithModelString
+type ModelInfo struct {
+	Name             string `json:"name"`
+	DisplayName      string `json:"display_name"`
+	Description      string `json:"description"`
+	CreditsPerPage   int    `json:"credits_per_page"`
+	SurchargePerPage int    `json:"surcharge_per_page"`
+	Priority         int    `json:"priority"`
+}
+
+// ListModels returns the OCR models available to the account
+func (s *SDK) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	resp, httpResp, err := s.client.ModelsAPI.ListOCRModels(ctx).Execute()
+	if err != nil {
+		return nil, s.handleAPIError(ctx, err, httpResp, "failed to list models")
+	}
+
+	models := make([]ModelInfo, 0, len(resp.Models))
+	for _, m := range resp.Models {
+		models = append(models, ModelInfo{
+			Name:             m.GetName(),
+			DisplayName:      m.GetDisplayName(),
+			Description:      m.GetDescription(),
+			CreditsPerPage:   int(m.GetCreditsPerPage()),
+			SurchargePerPage: int(m.GetSurchargePerPage()),
+			Priority:         int(m.GetPriority()),
+		})
+	}
+	return models, nil
+}
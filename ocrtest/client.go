@@ -0,0 +1,459 @@
+package ocrtest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"iter"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	ocr "github.com/leapocr/leapocr-go"
+)
+
+// Call is a call of a Client method
+type Call struct {
+	// Method is the name of the ocr.Client method, e.g. "ProcessFile"
+	Method string
+	JobID  string
+	// ID is the template, webhook subscription or delivery log entry of the call
+	ID       string
+	FileName string
+	URL      string
+	Content  []byte
+	// Settings are the resolved processing options of ProcessFile, ProcessURL and ProcessFileAndWait
+	Settings    ocr.ProcessingSettings
+	WaitOptions ocr.WaitOptions
+	// Err is the error returned by the call
+	Err error
+}
+
+// Client is an in-memory implementation of ocr.Client for unit tests of code
+// that depends on the interface. Jobs are scripted like those of a Server:
+// each GetJobStatus call reports the next scripted status, and waiting polls
+// through the script without delay. WaitUntilDone blocks until its context is
+// done when the script ends in a status the SDK keeps polling on.
+//
+// Templates and webhook subscriptions are kept in memory, and the delivery
+// log lists the events added with AddWebhookEvent.
+//
+// Every call is recorded; the zero value is not usable, use NewClient.
+type Client struct {
+	// JobFunc scripts each submitted job (default: processing, completed)
+	JobFunc func(Submission) Job
+	// Models is returned by ListModels (default: standard-v2 and pro-v2)
+	Models []ocr.ModelInfo
+	// EventTypes is returned by GetWebhookEventTypes and limits the events
+	// webhooks can subscribe to (default: the job events)
+	EventTypes []string
+
+	mu     sync.Mutex
+	errs   map[string]error
+	jobs   map[string]*job
+	order  []string
+	nextID int
+	calls  []Call
+
+	templates []ocr.Template
+	webhooks  []ocr.WebhookSubscription
+	events    map[string][]ocr.WebhookEvent
+	// now returns the time of created and updated resources
+	now func() time.Time
+}
+
+var _ ocr.Client = (*Client)(nil)
+
+// NewClient creates a fake client
+func NewClient() *Client {
+	return &Client{
+		Models:     defaultModels(),
+		EventTypes: defaultEventTypes(),
+		errs:       make(map[string]error),
+		jobs:       make(map[string]*job),
+		events:     make(map[string][]ocr.WebhookEvent),
+		now:        time.Now,
+	}
+}
+
+// SetError makes every call of method, e.g. "GetJobResult", return err until
+// it is cleared with a nil error
+func (c *Client) SetError(method string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err == nil {
+		delete(c.errs, method)
+		return
+	}
+	c.errs[method] = err
+}
+
+// Calls returns the recorded calls in call order
+func (c *Client) Calls() []Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.calls)
+}
+
+// CallsTo returns the recorded calls of method
+func (c *Client) CallsTo(method string) []Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var calls []Call
+	for _, call := range c.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Submissions returns the submitted jobs in submission order
+func (c *Client) Submissions() []Submission {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	submissions := make([]Submission, 0, len(c.order))
+	for _, id := range c.order {
+		submissions = append(submissions, c.jobs[id].submission)
+	}
+	return submissions
+}
+
+// ProcessURL implements ocr.Client
+func (c *Client) ProcessURL(_ context.Context, fileURL string, opts ...ocr.ProcessingOption) (*ocr.Job, error) {
+	call := Call{Method: "ProcessURL", URL: fileURL}
+	job, err := c.submitURL(&call, opts)
+	return job, c.record(call, err)
+}
+
+// ProcessFile implements ocr.Client
+func (c *Client) ProcessFile(_ context.Context, file io.Reader, filename string, opts ...ocr.ProcessingOption) (*ocr.Job, error) {
+	call := Call{Method: "ProcessFile", FileName: filename}
+	job, err := c.submitFile(&call, file, opts)
+	return job, c.record(call, err)
+}
+
+// ProcessFileAndWait implements ocr.Client
+func (c *Client) ProcessFileAndWait(ctx context.Context, file io.Reader, filename string, opts ...ocr.ProcessingOption) (*ocr.OCRResult, error) {
+	call := Call{Method: "ProcessFileAndWait", FileName: filename}
+	job, err := c.submitFile(&call, file, opts)
+	if err != nil {
+		return nil, c.record(call, err)
+	}
+	result, err := c.wait(ctx, job.ID, ocr.WaitOptions{})
+	return result, c.record(call, err)
+}
+
+// WaitUntilDone implements ocr.Client
+func (c *Client) WaitUntilDone(ctx context.Context, jobID string) (*ocr.OCRResult, error) {
+	call := Call{Method: "WaitUntilDone", JobID: jobID}
+	if err := c.injected(call.Method); err != nil {
+		return nil, c.record(call, err)
+	}
+	result, err := c.wait(ctx, jobID, ocr.WaitOptions{})
+	return result, c.record(call, err)
+}
+
+// WaitUntilDoneWithOptions implements ocr.Client; only MaxAttempts is honored
+func (c *Client) WaitUntilDoneWithOptions(ctx context.Context, jobID string, opts ocr.WaitOptions) (*ocr.OCRResult, error) {
+	call := Call{Method: "WaitUntilDoneWithOptions", JobID: jobID, WaitOptions: opts}
+	if err := c.injected(call.Method); err != nil {
+		return nil, c.record(call, err)
+	}
+	result, err := c.wait(ctx, jobID, opts)
+	return result, c.record(call, err)
+}
+
+// GetJobStatus implements ocr.Client
+func (c *Client) GetJobStatus(_ context.Context, jobID string) (*ocr.JobStatusInfo, error) {
+	call := Call{Method: "GetJobStatus", JobID: jobID}
+	if err := c.injected(call.Method); err != nil {
+		return nil, c.record(call, err)
+	}
+	status, err := c.status(jobID)
+	return status, c.record(call, err)
+}
+
+// GetJobResult implements ocr.Client
+func (c *Client) GetJobResult(_ context.Context, jobID string) (*ocr.OCRResult, error) {
+	call := Call{Method: "GetJobResult", JobID: jobID}
+	if err := c.injected(call.Method); err != nil {
+		return nil, c.record(call, err)
+	}
+	result, err := c.result(jobID)
+	return result, c.record(call, err)
+}
+
+// DeleteJob implements ocr.Client
+func (c *Client) DeleteJob(_ context.Context, jobID string) error {
+	call := Call{Method: "DeleteJob", JobID: jobID}
+	if err := c.injected(call.Method); err != nil {
+		return c.record(call, err)
+	}
+
+	c.mu.Lock()
+	j, err := c.lookup(jobID)
+	if err == nil {
+		j.submission.Deleted = true
+		j.submission.Content = nil
+	}
+	c.mu.Unlock()
+	return c.record(call, err)
+}
+
+// ListModels implements ocr.Client
+func (c *Client) ListModels(context.Context) ([]ocr.ModelInfo, error) {
+	call := Call{Method: "ListModels"}
+	if err := c.injected(call.Method); err != nil {
+		return nil, c.record(call, err)
+	}
+	return slices.Clone(c.Models), c.record(call, nil)
+}
+
+// ProcessDirectory implements ocr.Client like ocr.SDK.ProcessDirectory
+func (c *Client) ProcessDirectory(ctx context.Context, dir string, opts ocr.DirOptions) (*ocr.DirSummary, error) {
+	if opts.OutputDir == "" {
+		opts.OutputDir = dir
+	}
+	return c.processDir(ctx, Call{Method: "ProcessDirectory", FileName: dir}, os.DirFS(dir), opts)
+}
+
+// ProcessDir implements ocr.Client. Files are selected and their outputs
+// written like ocr.SDK.ProcessDir does, but they are processed one at a time
+// with ProcessFileAndWait, which records a call per file with its path as the
+// file name. Batch journals and wait options are ignored.
+func (c *Client) ProcessDir(ctx context.Context, fsys fs.FS, opts ocr.DirOptions) (*ocr.DirSummary, error) {
+	return c.processDir(ctx, Call{Method: "ProcessDir"}, fsys, opts)
+}
+
+func (c *Client) processDir(ctx context.Context, call Call, fsys fs.FS, opts ocr.DirOptions) (*ocr.DirSummary, error) {
+	if err := c.injected(call.Method); err != nil {
+		return nil, c.record(call, err)
+	}
+
+	summary, err := ocr.ProcessDirWith(fsys, opts, func(items []ocr.BatchItem) iter.Seq[ocr.BatchResult] {
+		return func(yield func(ocr.BatchResult) bool) {
+			for i, item := range items {
+				started := time.Now()
+				res := ocr.BatchResult{Index: i, Key: item.Key, Item: item}
+				res.Result, res.Err = c.processDirFile(ctx, item, opts.Batch.Options)
+				if res.Result != nil {
+					res.JobID = res.Result.JobID
+				}
+				res.Duration = time.Since(started)
+				if !yield(res) {
+					return
+				}
+			}
+		}
+	})
+	return summary, c.record(call, err)
+}
+
+// processDirFile processes a file item of ProcessDir
+func (c *Client) processDirFile(ctx context.Context, item ocr.BatchItem, opts []ocr.ProcessingOption) (*ocr.OCRResult, error) {
+	f, err := item.FS.Open(item.Path)
+	if err != nil {
+		return nil, ocr.NewSDKError(ocr.ErrorTypeValidationError, "failed to open file", err)
+	}
+	defer f.Close() //nolint:errcheck
+	return c.ProcessFileAndWait(ctx, f, item.Path, append(slices.Clip(opts), item.Options...)...)
+}
+
+// record appends call with err to the recorded calls and returns err
+func (c *Client) record(call Call, err error) error {
+	call.Err = err
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, call)
+	return err
+}
+
+// injected returns the error set for method, if any
+func (c *Client) injected(method string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.errs[method]
+}
+
+func (c *Client) submitURL(call *Call, opts []ocr.ProcessingOption) (*ocr.Job, error) {
+	if err := c.injected(call.Method); err != nil {
+		return nil, err
+	}
+	if err := ocr.ValidateURL(call.URL); err != nil {
+		return nil, ocr.NewSDKError(ocr.ErrorTypeValidationError, "invalid URL", err)
+	}
+	settings, err := ocr.ResolveProcessingOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	call.Settings = settings
+	return c.submit(call, Submission{URL: call.URL}), nil
+}
+
+func (c *Client) submitFile(call *Call, file io.Reader, opts []ocr.ProcessingOption) (*ocr.Job, error) {
+	if err := c.injected(call.Method); err != nil {
+		return nil, err
+	}
+	if err := ocr.ValidateFileExtension(call.FileName); err != nil {
+		return nil, ocr.NewSDKError(ocr.ErrorTypeValidationError, "invalid filename", err)
+	}
+	settings, err := ocr.ResolveProcessingOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	call.Settings = settings
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, ocr.NewSDKError(ocr.ErrorTypeUploadError, "failed to read file content", err)
+	}
+	if len(content) == 0 {
+		return nil, ocr.NewSDKError(ocr.ErrorTypeValidationError, "file is empty", nil)
+	}
+	call.Content = content
+	return c.submit(call, Submission{FileName: call.FileName, Content: content}), nil
+}
+
+// submit creates a started job for submission with the settings of call
+func (c *Client) submit(call *Call, submission Submission) *ocr.Job {
+	submission.Format = string(call.Settings.Format)
+	submission.Model = call.Settings.Model
+	submission.Instructions = call.Settings.Instructions
+	submission.Schema = call.Settings.Schema
+	submission.TemplateSlug = call.Settings.TemplateSlug
+
+	c.mu.Lock()
+	c.nextID++
+	submission.JobID = fmt.Sprintf("job_%d", c.nextID)
+	script := c.JobFunc
+	c.mu.Unlock()
+
+	var scripted Job
+	if script != nil {
+		scripted = script(submission)
+	}
+	j := newJob(submission, scripted)
+	j.started = true
+
+	c.mu.Lock()
+	c.jobs[submission.JobID] = j
+	c.order = append(c.order, submission.JobID)
+	c.mu.Unlock()
+
+	call.JobID = submission.JobID
+	return &ocr.Job{ID: submission.JobID, Status: "processing"}
+}
+
+// lookup returns a job that was not deleted; callers must hold c.mu
+func (c *Client) lookup(jobID string) (*job, error) {
+	j, ok := c.jobs[jobID]
+	if !ok || j.submission.Deleted {
+		return nil, apiError(http.StatusNotFound, "job not found")
+	}
+	return j, nil
+}
+
+func (c *Client) status(jobID string) (*ocr.JobStatusInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	j, err := c.lookup(jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := j.poll()
+	info := &ocr.JobStatusInfo{ID: jobID, Status: string(status)}
+	switch status {
+	case StatusCompleted, StatusPartiallyDone:
+		info.Progress = 100
+	case StatusProcessing:
+		if total := len(j.script.Pages); total > 0 {
+			info.Progress = float64(total/2) / float64(total) * 100
+		}
+	case StatusFailed:
+		info.Error = j.errorMessage()
+	}
+	return info, nil
+}
+
+func (c *Client) result(jobID string) (*ocr.OCRResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	j, err := c.lookup(jobID)
+	if err != nil {
+		return nil, err
+	}
+	status, ok := j.resultStatus()
+	if !ok {
+		return nil, apiError(http.StatusConflict, "job is not completed")
+	}
+
+	// Pages convert as in the SDK: strings are text, maps are merged into Data
+	result := &ocr.OCRResult{JobID: jobID, Status: string(status), Credits: j.script.Credits}
+	result.Pages = make([]ocr.PageResult, len(j.script.Pages))
+	for i, page := range j.script.Pages {
+		result.Pages[i].PageNumber = i + 1
+		switch v := page.(type) {
+		case string:
+			result.Pages[i].Text = v
+			result.Text += v + "\n"
+		case map[string]any:
+			result.Pages[i].Data = v
+			if result.Data == nil {
+				result.Data = make(map[string]any)
+			}
+			maps.Copy(result.Data, v)
+		}
+	}
+	return result, nil
+}
+
+// wait polls a job like the SDK until it completes, fails or runs out of
+// attempts; without a limit on attempts it blocks until ctx is done once the
+// script is exhausted
+func (c *Client) wait(ctx context.Context, jobID string, opts ocr.WaitOptions) (*ocr.OCRResult, error) {
+	for attempts := 0; ; attempts++ {
+		if opts.MaxAttempts > 0 && attempts >= opts.MaxAttempts {
+			return nil, ocr.NewSDKError(ocr.ErrorTypeTimeout, "maximum polling attempts exceeded", nil)
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, ocr.NewSDKError(ocr.ErrorTypeTimeout, "context canceled while waiting for completion", err)
+		}
+
+		status, err := c.status(jobID)
+		if err != nil {
+			return nil, err
+		}
+		switch status.Status {
+		case string(StatusCompleted):
+			return c.result(jobID)
+		case string(StatusFailed):
+			return nil, ocr.NewSDKError(ocr.ErrorTypeJobError, "job failed: "+status.Error, nil)
+		}
+
+		if opts.MaxAttempts == 0 && c.exhausted(jobID) {
+			<-ctx.Done()
+			return nil, ocr.NewSDKError(ocr.ErrorTypeTimeout, "context canceled while waiting", ctx.Err())
+		}
+	}
+}
+
+// exhausted reports whether every scripted status of a job was reported
+func (c *Client) exhausted(jobID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	j := c.jobs[jobID]
+	return j.polls >= len(j.script.Statuses)
+}
+
+// apiError returns the error the SDK returns for an API response with status
+func apiError(status int, message string) error {
+	err := ocr.NewSDKError(ocr.ErrorTypeAPIError, message, nil)
+	err.StatusCode = status
+	return err
+}
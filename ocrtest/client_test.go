@@ -0,0 +1,275 @@
+package ocrtest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	ocr "github.com/leapocr/leapocr-go"
+)
+
+func TestClient(t *testing.T) {
+	client := NewClient()
+	client.JobFunc = func(s Submission) Job {
+		if strings.Contains(s.URL, "broken") {
+			return Job{Statuses: []Status{StatusFailed}, Error: "unreadable document"}
+		}
+		return Job{Pages: []any{"# Page 1", "# Page 2"}}
+	}
+	ctx := context.Background()
+
+	var c ocr.Client = client
+	job, err := c.ProcessFile(ctx, strings.NewReader("%PDF-1.7 fake"), "doc.pdf", ocr.WithFormat(ocr.FormatMarkdown), ocr.WithModel(ocr.ModelProV2))
+	if err != nil {
+		t.Fatalf("ProcessFile failed: %v", err)
+	}
+	if _, err := c.GetJobResult(ctx, job.ID); !isStatus(err, http.StatusConflict) {
+		t.Errorf("expected 409 before completion, got %v", err)
+	}
+	result, err := c.WaitUntilDone(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("WaitUntilDone failed: %v", err)
+	}
+	if len(result.Pages) != 2 || result.Credits != 2 || result.Text != "# Page 1\n# Page 2\n" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	broken, err := c.ProcessURL(ctx, "https://example.com/broken.pdf", ocr.WithTemplateSlug("invoices"))
+	if err != nil {
+		t.Fatalf("ProcessURL failed: %v", err)
+	}
+	if _, err := c.WaitUntilDone(ctx, broken.ID); !isErrorType(err, ocr.ErrorTypeJobError) || !strings.Contains(err.Error(), "unreadable document") {
+		t.Errorf("expected job error, got %v", err)
+	}
+
+	if err := c.DeleteJob(ctx, job.ID); err != nil {
+		t.Fatalf("DeleteJob failed: %v", err)
+	}
+	if _, err := c.GetJobStatus(ctx, job.ID); !isStatus(err, http.StatusNotFound) {
+		t.Errorf("expected 404 after delete, got %v", err)
+	}
+	if _, err := c.ProcessFile(ctx, strings.NewReader("data"), "doc.txt"); !isErrorType(err, ocr.ErrorTypeValidationError) {
+		t.Errorf("expected validation error, got %v", err)
+	}
+
+	calls := client.CallsTo("ProcessFile")
+	if len(calls) != 2 || string(calls[0].Content) != "%PDF-1.7 fake" || calls[0].Settings.Model != "pro-v2" || calls[1].Err == nil {
+		t.Errorf("unexpected ProcessFile calls: %+v", calls)
+	}
+	if calls := client.CallsTo("ProcessURL"); len(calls) != 1 || calls[0].Settings.TemplateSlug != "invoices" || calls[0].Settings.Format != "" {
+		t.Errorf("unexpected ProcessURL calls: %+v", calls)
+	}
+	if submissions := client.Submissions(); len(submissions) != 2 || !submissions[0].Deleted {
+		t.Errorf("unexpected submissions: %+v", submissions)
+	}
+	if n := len(client.Calls()); n != 8 {
+		t.Errorf("expected 8 calls, got %d", n)
+	}
+}
+
+func TestClientWaiting(t *testing.T) {
+	client := NewClient()
+	client.JobFunc = func(Submission) Job {
+		return Job{Statuses: []Status{StatusProcessing}}
+	}
+	ctx := context.Background()
+
+	job, err := client.ProcessURL(ctx, "https://example.com/doc.pdf", ocr.WithFormat(ocr.FormatMarkdown))
+	if err != nil {
+		t.Fatalf("ProcessURL failed: %v", err)
+	}
+	if _, err := client.WaitUntilDoneWithOptions(ctx, job.ID, ocr.WaitOptions{MaxAttempts: 3}); !isErrorType(err, ocr.ErrorTypeTimeout) {
+		t.Errorf("expected timeout after max attempts, got %v", err)
+	}
+
+	// A job that never finishes blocks until the context is done
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.WaitUntilDone(waitCtx, job.ID); !isErrorType(err, ocr.ErrorTypeTimeout) {
+		t.Errorf("expected timeout, got %v", err)
+	}
+
+	injected := errors.New("boom")
+	client.SetError("ListModels", injected)
+	if _, err := client.ListModels(ctx); !errors.Is(err, injected) {
+		t.Errorf("expected injected error, got %v", err)
+	}
+	client.SetError("ListModels", nil)
+	if models, err := client.ListModels(ctx); err != nil || len(models) != 2 {
+		t.Errorf("expected default models, got %v, %v", models, err)
+	}
+}
+
+func isErrorType(err error, errorType ocr.ErrorType) bool {
+	var sdkErr *ocr.SDKError
+	return errors.As(err, &sdkErr) && sdkErr.Type == errorType
+}
+
+func TestClientTemplates(t *testing.T) {
+	client := NewClient()
+	ctx := context.Background()
+
+	var c ocr.TemplateClient = client
+	invoices, err := c.CreateTemplate(ctx, ocr.TemplateParams{Name: "Invoice Fields", Format: ocr.FormatStructured, Schema: map[string]any{"type": "object"}, Tags: []string{"billing"}})
+	if err != nil {
+		t.Fatalf("CreateTemplate failed: %v", err)
+	}
+	if invoices.Slug != "invoice-fields" || invoices.Model != string(ocr.ModelStandardV2) || !invoices.Enabled {
+		t.Errorf("unexpected template: %+v", invoices)
+	}
+	if _, err := c.CreateTemplate(ctx, ocr.TemplateParams{Name: "Invoice Fields", Format: ocr.FormatMarkdown}); !isStatus(err, http.StatusConflict) {
+		t.Errorf("expected 409 for a duplicate slug, got %v", err)
+	}
+	if _, err := c.CreateTemplate(ctx, ocr.TemplateParams{Format: ocr.FormatMarkdown}); !isErrorType(err, ocr.ErrorTypeValidationError) {
+		t.Errorf("expected validation error, got %v", err)
+	}
+	for _, name := range []string{"Receipts", "Contracts"} {
+		if _, err := c.CreateTemplate(ctx, ocr.TemplateParams{Name: name, Format: ocr.FormatMarkdown}); err != nil {
+			t.Fatalf("CreateTemplate failed: %v", err)
+		}
+	}
+
+	// Updates replace every field but the model
+	updated, err := c.UpdateTemplate(ctx, invoices.ID, ocr.TemplateParams{Name: "Invoices", Format: ocr.FormatMarkdown, Model: string(ocr.ModelProV2)})
+	if err != nil {
+		t.Fatalf("UpdateTemplate failed: %v", err)
+	}
+	if updated.Name != "Invoices" || updated.Tags != nil || updated.Schema != nil || updated.Model != string(ocr.ModelStandardV2) {
+		t.Errorf("unexpected updated template: %+v", updated)
+	}
+
+	page, err := c.ListTemplates(ctx, ocr.TemplateListOptions{Limit: 2})
+	if err != nil || len(page.Templates) != 2 || !page.HasMore {
+		t.Fatalf("unexpected first page: %+v, %v", page, err)
+	}
+	var names []string
+	for template, err := range c.Templates(ctx, ocr.TemplateListOptions{Limit: 1, Format: ocr.FormatMarkdown}) {
+		if err != nil {
+			t.Fatalf("Templates failed: %v", err)
+		}
+		names = append(names, template.Name)
+	}
+	if strings.Join(names, ",") != "Invoices,Receipts,Contracts" {
+		t.Errorf("unexpected markdown templates: %v", names)
+	}
+
+	if err := c.DeleteTemplate(ctx, invoices.ID); err != nil {
+		t.Fatalf("DeleteTemplate failed: %v", err)
+	}
+	if _, err := c.GetTemplate(ctx, invoices.ID); !isStatus(err, http.StatusNotFound) {
+		t.Errorf("expected 404 after delete, got %v", err)
+	}
+	if calls := client.CallsTo("DeleteTemplate"); len(calls) != 1 || calls[0].ID != invoices.ID {
+		t.Errorf("unexpected delete calls: %+v", calls)
+	}
+}
+
+func TestClientWebhooks(t *testing.T) {
+	client := NewClient()
+	ctx := context.Background()
+
+	var c ocr.WebhookClient = client
+	subscription, err := c.CreateWebhook(ctx, ocr.WebhookParams{URL: "https://example.com/hooks", Events: []string{"job.completed", "job.failed"}})
+	if err != nil {
+		t.Fatalf("CreateWebhook failed: %v", err)
+	}
+	if subscription.Secret == "" || !subscription.Enabled {
+		t.Errorf("unexpected subscription: %+v", subscription)
+	}
+	if _, err := c.CreateWebhook(ctx, ocr.WebhookParams{URL: "https://example.com/hooks", Events: []string{"job.deleted"}}); !isStatus(err, http.StatusBadRequest) {
+		t.Errorf("expected 400 for an unknown event type, got %v", err)
+	}
+
+	rotation, err := c.RotateWebhookSecret(ctx, subscription.ID)
+	if err != nil {
+		t.Fatalf("RotateWebhookSecret failed: %v", err)
+	}
+	if rotation.PreviousSecret != subscription.Secret || rotation.Secret == subscription.Secret {
+		t.Errorf("unexpected rotation: %+v", rotation)
+	}
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, status := range []string{"delivered", "failed", "delivered"} {
+		client.AddWebhookEvent(subscription.ID, ocr.WebhookEvent{Status: status, CreatedAt: start.Add(time.Duration(i) * time.Minute), Payload: map[string]any{"n": i}})
+	}
+	var ids []string
+	for event, err := range c.WebhookEvents(ctx, ocr.WebhookEventFilter{SubscriptionID: subscription.ID, PageSize: 1, Since: start.Add(time.Minute)}) {
+		if err != nil {
+			t.Fatalf("WebhookEvents failed: %v", err)
+		}
+		ids = append(ids, event.ID)
+	}
+	if strings.Join(ids, ",") != "log_3,log_2" {
+		t.Errorf("expected newest entries first, got %v", ids)
+	}
+	failed, err := c.ListWebhookEvents(ctx, ocr.WebhookEventFilter{SubscriptionID: subscription.ID, Status: "failed"}, "")
+	if err != nil || len(failed.Events) != 1 || failed.Events[0].WebhookURL != subscription.URL {
+		t.Fatalf("unexpected failed deliveries: %+v, %v", failed, err)
+	}
+	payload, err := c.GetWebhookEventPayload(ctx, failed.Events[0].ID)
+	if err != nil || payload.Payload["n"] != 1 {
+		t.Errorf("unexpected payload: %+v, %v", payload, err)
+	}
+
+	if _, err := c.TestWebhook(ctx, subscription.ID); err != nil {
+		t.Fatalf("TestWebhook failed: %v", err)
+	}
+	tests, err := c.ListWebhookEvents(ctx, ocr.WebhookEventFilter{SubscriptionID: subscription.ID, EventType: "webhook.test"}, "")
+	if err != nil || len(tests.Events) != 1 {
+		t.Errorf("expected a logged test delivery, got %+v, %v", tests, err)
+	}
+
+	disabled := false
+	if _, err := c.UpdateWebhook(ctx, subscription.ID, ocr.WebhookParams{Events: []string{"job.completed"}, Enabled: &disabled}); err != nil {
+		t.Fatalf("UpdateWebhook failed: %v", err)
+	}
+	if health, err := c.CheckWebhookHealth(ctx, subscription.ID); err != nil || health["healthy"] != false {
+		t.Errorf("expected a disabled webhook to be unhealthy, got %v, %v", health, err)
+	}
+	if list, err := c.ListWebhooks(ctx, ocr.WebhookListOptions{Enabled: &disabled}); err != nil || len(list.Subscriptions) != 1 {
+		t.Errorf("unexpected disabled webhooks: %+v, %v", list, err)
+	}
+
+	if err := c.DeleteWebhook(ctx, subscription.ID); err != nil {
+		t.Fatalf("DeleteWebhook failed: %v", err)
+	}
+	if _, err := c.ListWebhookEvents(ctx, ocr.WebhookEventFilter{SubscriptionID: subscription.ID}, ""); !isStatus(err, http.StatusNotFound) {
+		t.Errorf("expected 404 after delete, got %v", err)
+	}
+}
+
+func TestClientProcessDir(t *testing.T) {
+	client := NewClient()
+	fsys := fstest.MapFS{
+		"a.pdf":       {Data: []byte("%PDF-1.7 a")},
+		"scans/b.pdf": {Data: []byte("%PDF-1.7 b")},
+		"notes.txt":   {Data: []byte("skip me")},
+		"scans/c.png": {Data: []byte("unsupported")},
+	}
+
+	var c ocr.DirectoryProcessor = client
+	summary, err := c.ProcessDir(context.Background(), fsys, ocr.DirOptions{
+		Recursive: true,
+		OutputDir: t.TempDir(),
+		Batch:     ocr.BatchOptions{Options: []ocr.ProcessingOption{ocr.WithFormat(ocr.FormatMarkdown)}},
+	})
+	if err != nil {
+		t.Fatalf("ProcessDir failed: %v", err)
+	}
+	if summary.Processed != 2 || summary.Failed != 0 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+	var names []string
+	for _, s := range client.Submissions() {
+		names = append(names, s.FileName)
+	}
+	slices.Sort(names)
+	if strings.Join(names, ",") != "a.pdf,scans/b.pdf" {
+		t.Errorf("unexpected submissions: %v", names)
+	}
+}
@@ -0,0 +1,325 @@
+package ocrtest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+
+	ocr "github.com/leapocr/leapocr-go"
+)
+
+// Mode selects whether a Recorder records or replays exchanges
+type Mode int
+
+const (
+	// ModeReplay answers requests from the cassette without network access
+	ModeReplay Mode = iota
+	// ModeRecord sends requests and saves the exchanges to the cassette on Stop
+	ModeRecord
+)
+
+// Redacted replaces secrets and presigned URL signatures in cassettes
+const Redacted = "REDACTED"
+
+// ErrNoInteraction is returned in replay mode for requests without a recorded exchange
+var ErrNoInteraction = errors.New("ocrtest: no recorded interaction")
+
+// Cassette is the file format of recorded exchanges
+type Cassette struct {
+	Interactions []Interaction `yaml:"interactions"`
+}
+
+// Interaction is a recorded request and its response
+type Interaction struct {
+	Request  RecordedRequest  `yaml:"request"`
+	Response RecordedResponse `yaml:"response"`
+}
+
+// RecordedRequest is a request of an Interaction
+type RecordedRequest struct {
+	Method  string      `yaml:"method"`
+	URL     string      `yaml:"url"`
+	Headers http.Header `yaml:"headers,omitempty"`
+	// Body is kept for JSON requests only; file parts are left out
+	Body string `yaml:"body,omitempty"`
+}
+
+// RecordedResponse is the response of an Interaction
+type RecordedResponse struct {
+	StatusCode int         `yaml:"status_code"`
+	Headers    http.Header `yaml:"headers,omitempty"`
+	Body       string      `yaml:"body,omitempty"`
+	// BodyEncoding is "base64" for bodies that are not valid UTF-8
+	BodyEncoding string `yaml:"body_encoding,omitempty"`
+}
+
+// RecorderOption configures a Recorder
+type RecorderOption func(*Recorder)
+
+// WithRecordTransport sets the transport requests are sent with in record mode
+// (default: http.DefaultTransport)
+func WithRecordTransport(transport http.RoundTripper) RecorderOption {
+	return func(r *Recorder) {
+		r.transport = transport
+	}
+}
+
+// WithScrubber adds a function that removes sensitive data from interactions
+// before they are saved, in addition to the built-in scrubbing
+func WithScrubber(fn func(*Interaction)) RecorderOption {
+	return func(r *Recorder) {
+		r.scrubbers = append(r.scrubbers, fn)
+	}
+}
+
+// Recorder is an http.RoundTripper recording exchanges with the API and
+// storage into a cassette file and replaying them, so that tests written
+// against a live server can run offline.
+//
+// Credentials headers and the signatures of presigned URLs are replaced with
+// Redacted before interactions are saved. Requests are matched to recorded
+// interactions by method, path and scrubbed query in recording order, so
+// cassettes replay against any base URL.
+type Recorder struct {
+	path      string
+	mode      Mode
+	transport http.RoundTripper
+	scrubbers []func(*Interaction)
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// NewRecorder creates a recorder for the cassette at path. In replay mode the
+// cassette must exist; in record mode it is overwritten by Stop.
+func NewRecorder(path string, mode Mode, opts ...RecorderOption) (*Recorder, error) {
+	r := &Recorder{path: path, mode: mode, transport: http.DefaultTransport}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if mode == ModeReplay {
+		data, err := os.ReadFile(path) // #nosec G304 - cassette path chosen by the test
+		if err != nil {
+			return nil, fmt.Errorf("ocrtest: failed to read cassette: %w", err)
+		}
+		if err := yaml.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("ocrtest: invalid cassette %s: %w", path, err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+	return r, nil
+}
+
+// Mode returns the mode of the recorder
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Client returns an HTTP client using the recorder as transport
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Configure routes API requests and storage uploads of config through the recorder
+func (r *Recorder) Configure(config *ocr.Config) {
+	config.HTTPClient = r.Client()
+	config.UploadHTTPClient = r.Client()
+}
+
+// Interactions returns the recorded or loaded interactions
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.cassette.Interactions...)
+}
+
+// Stop saves the cassette in record mode; it does nothing in replay mode
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	data, err := yaml.Marshal(&r.cassette)
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("ocrtest: failed to encode cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o750); err != nil {
+		return fmt.Errorf("ocrtest: failed to create cassette directory: %w", err)
+	}
+	if err := os.WriteFile(r.path, data, 0o600); err != nil {
+		return fmt.Errorf("ocrtest: failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.mode == ModeReplay {
+		return r.replay(req)
+	}
+	return r.record(req)
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		_ = req.Body.Close() //nolint:errcheck
+		if err != nil {
+			return nil, err
+		}
+		reqBody = data
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close() //nolint:errcheck
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method:  req.Method,
+			URL:     req.URL.String(),
+			Headers: req.Header.Clone(),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Headers:    resp.Header.Clone(),
+		},
+	}
+	if isJSON(req.Header.Get("Content-Type")) {
+		interaction.Request.Body = string(reqBody)
+	}
+	if utf8.Valid(respBody) {
+		interaction.Response.Body = string(respBody)
+	} else {
+		interaction.Response.Body = base64.StdEncoding.EncodeToString(respBody)
+		interaction.Response.BodyEncoding = "base64"
+	}
+	scrub(&interaction)
+	for _, fn := range r.scrubbers {
+		fn(&interaction)
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_, _ = io.Copy(io.Discard, req.Body) //nolint:errcheck
+		_ = req.Body.Close()                 //nolint:errcheck
+	}
+	key := matchKey(req.Method, scrubString(req.URL.String()))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || matchKey(interaction.Request.Method, interaction.Request.URL) != key {
+			continue
+		}
+		r.used[i] = true
+
+		body := []byte(interaction.Response.Body)
+		if interaction.Response.BodyEncoding == "base64" {
+			decoded, err := base64.StdEncoding.DecodeString(interaction.Response.Body)
+			if err != nil {
+				return nil, fmt.Errorf("ocrtest: invalid response body in cassette: %w", err)
+			}
+			body = decoded
+		}
+		header := interaction.Response.Headers.Clone()
+		if header == nil {
+			header = make(http.Header)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w for %s %s", ErrNoInteraction, req.Method, redactQuery(req.URL))
+}
+
+// sensitiveHeaders are replaced with Redacted in cassettes
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "X-Api-Key", "Cookie", "Set-Cookie"}
+
+// signatureParam matches the credentials of presigned S3 and GCS URLs,
+// including in JSON strings where & is escaped as \u0026
+var signatureParam = regexp.MustCompile(`(?i)((?:X-Amz|X-Goog)-(?:Signature|Credential|Security-Token)=)[^&"\\\s]+`)
+
+// scrub redacts credentials headers and presigned URL signatures
+func scrub(interaction *Interaction) {
+	for _, headers := range []http.Header{interaction.Request.Headers, interaction.Response.Headers} {
+		for _, name := range sensitiveHeaders {
+			if headers.Get(name) != "" {
+				headers.Set(name, Redacted)
+			}
+		}
+	}
+	interaction.Request.URL = scrubString(interaction.Request.URL)
+	interaction.Request.Body = scrubString(interaction.Request.Body)
+	if interaction.Response.BodyEncoding == "" {
+		interaction.Response.Body = scrubString(interaction.Response.Body)
+	}
+	if location := interaction.Response.Headers.Get("Location"); location != "" {
+		interaction.Response.Headers.Set("Location", scrubString(location))
+	}
+}
+
+func scrubString(s string) string {
+	return signatureParam.ReplaceAllString(s, "${1}"+Redacted)
+}
+
+// matchKey identifies the requests an interaction answers, ignoring scheme and host
+func matchKey(method, rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return method + " " + rawURL
+	}
+	return method + " " + u.EscapedPath() + "?" + u.RawQuery
+}
+
+// redactQuery returns u without its query for error messages
+func redactQuery(u *url.URL) string {
+	clean := *u
+	clean.RawQuery = ""
+	return clean.String()
+}
+
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
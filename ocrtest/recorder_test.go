@@ -0,0 +1,86 @@
+package ocrtest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ocr "github.com/leapocr/leapocr-go"
+)
+
+func TestRecorder(t *testing.T) {
+	cassette := filepath.Join(t.TempDir(), "cassettes", "process.yaml")
+	content := []byte("%PDF-1.7 recorded")
+
+	// scenario processes a file and returns the text of its result
+	scenario := func(t *testing.T, config *ocr.Config) string {
+		t.Helper()
+		sdk, err := ocr.NewSDK(config)
+		if err != nil {
+			t.Fatalf("failed to create SDK: %v", err)
+		}
+		ctx := context.Background()
+		job, err := sdk.ProcessFile(ctx, bytes.NewReader(content), "doc.pdf", ocr.WithFormat(ocr.FormatMarkdown))
+		if err != nil {
+			t.Fatalf("ProcessFile failed: %v", err)
+		}
+		result, err := sdk.WaitUntilDoneWithOptions(ctx, job.ID, fastWait)
+		if err != nil {
+			t.Fatalf("WaitUntilDone failed: %v", err)
+		}
+		if _, err := sdk.GetJobStatus(ctx, "job_missing"); !isStatus(err, http.StatusNotFound) {
+			t.Errorf("expected recorded 404, got %v", err)
+		}
+		return result.Text
+	}
+
+	server := NewServer(WithPartSize(8), WithJob(Job{
+		Statuses: []Status{StatusProcessing, StatusCompleted},
+		Pages:    []any{"# Recorded"},
+	}))
+	recorder, err := NewRecorder(cassette, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := server.Config()
+	recorder.Configure(config)
+	recorded := scenario(t, config)
+	server.Close()
+	if err := recorder.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+
+	data, err := os.ReadFile(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{DefaultAPIKey, "X-Amz-Signature=fake"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains %q", secret)
+		}
+	}
+
+	// The server is gone: the replay must not touch the network
+	replayer, err := NewRecorder(cassette, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config = ocr.DefaultConfig("another-key")
+	config.BaseURL = "http://leapocr.invalid"
+	replayer.Configure(config)
+	if replayed := scenario(t, config); replayed != recorded || !strings.Contains(replayed, "# Recorded") {
+		t.Errorf("replayed %q, recorded %q", replayed, recorded)
+	}
+
+	if _, err := replayer.Client().Get("http://leapocr.invalid/ocr/models"); !errors.Is(err, ErrNoInteraction) {
+		t.Errorf("expected ErrNoInteraction for an unrecorded request, got %v", err)
+	}
+	if _, err := NewRecorder(filepath.Join(t.TempDir(), "missing.yaml"), ModeReplay); err == nil {
+		t.Error("expected an error for a missing cassette")
+	}
+}
@@ -0,0 +1,711 @@
+// Package ocrtest provides an in-process fake of the LeapOCR API for tests.
+//
+// The fake implements the endpoints used to process documents: direct uploads
+// with presigned part URLs, remote URL uploads, job status and results, job
+// deletion and the model list. Job lifecycles, failures, latency and rate
+// limiting are scriptable:
+//
+//	server := ocrtest.NewServer(ocrtest.WithJob(ocrtest.Job{
+//		Statuses: []ocrtest.Status{ocrtest.StatusProcessing, ocrtest.StatusCompleted},
+//		Pages:    []any{"# Invoice"},
+//	}))
+//	defer server.Close()
+//
+//	sdk, err := ocr.NewSDK(server.Config())
+//
+// Client runs the same job scripts in memory, for tests of code that depends
+// on the ocr.Client interface rather than on *ocr.SDK.
+package ocrtest
+
+import (
+	"crypto/md5" // #nosec G501 - ETags of the storage API are MD5 digests
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	ocr "github.com/leapocr/leapocr-go"
+)
+
+const (
+	// DefaultAPIKey is the API key accepted by a Server unless WithAPIKey is used
+	DefaultAPIKey = "test-key"
+	// DefaultPartSize is the size of the upload parts handed out for direct uploads
+	DefaultPartSize = 5 << 20
+)
+
+// Endpoint names a group of requests of the API, used to target failures and latency
+type Endpoint string
+
+const (
+	// EndpointAll matches every endpoint
+	EndpointAll Endpoint = ""
+	// EndpointDirectUpload is POST /ocr/uploads/direct
+	EndpointDirectUpload Endpoint = "direct upload"
+	// EndpointUploadPart is the PUT of a file part to its presigned URL
+	EndpointUploadPart Endpoint = "upload part"
+	// EndpointCompleteUpload is POST /ocr/uploads/{job_id}/complete
+	EndpointCompleteUpload Endpoint = "complete upload"
+	// EndpointURLUpload is POST /ocr/uploads/url
+	EndpointURLUpload Endpoint = "url upload"
+	// EndpointStatus is GET /ocr/status/{job_id}
+	EndpointStatus Endpoint = "status"
+	// EndpointResult is GET /ocr/result/{job_id}
+	EndpointResult Endpoint = "result"
+	// EndpointDelete is DELETE /ocr/delete/{job_id}
+	EndpointDelete Endpoint = "delete"
+	// EndpointModels is GET /ocr/models
+	EndpointModels Endpoint = "models"
+)
+
+// Status is the status of a job reported by the status endpoint
+type Status string
+
+const (
+	// StatusPending is reported until the upload of a job is complete
+	StatusPending Status = "pending"
+	// StatusProcessing is reported while pages are processed
+	StatusProcessing Status = "processing"
+	// StatusCompleted is reported when every page was processed
+	StatusCompleted Status = "completed"
+	// StatusPartiallyDone is reported when some pages failed
+	StatusPartiallyDone Status = "partially_done"
+	// StatusFailed is reported when the job could not be processed
+	StatusFailed Status = "failed"
+)
+
+// Job scripts the lifecycle of a job
+type Job struct {
+	// Statuses are reported by successive status requests once the upload is
+	// complete; the last one repeats (default: processing, completed)
+	Statuses []Status
+	// Error is the error message reported with StatusFailed
+	Error string
+	// Pages are the page results: strings for markdown, maps for structured
+	// output (default: one page, "# <file name>" or an empty object)
+	Pages []any
+	// Credits is the number of credits charged (default: one per page)
+	Credits int
+}
+
+// Submission is a job as submitted by the client
+type Submission struct {
+	JobID    string
+	FileName string
+	// URL is the source of remote URL uploads
+	URL string
+	// Content is the file of direct uploads, assembled from its parts once the upload is complete
+	Content      []byte
+	Format       string
+	Model        string
+	Instructions string
+	Schema       map[string]any
+	TemplateSlug string
+	Deleted      bool
+}
+
+// Failure makes requests of an endpoint fail
+type Failure struct {
+	Endpoint Endpoint
+	// StatusCode is the status of the failed responses (default: 500)
+	StatusCode int
+	// Times is the number of requests that fail; zero fails every request
+	Times int
+	// RetryAfter sets the Retry-After header of the failed responses
+	RetryAfter time.Duration
+	// Message is the error message of the response body
+	Message string
+}
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithAPIKey sets the API key accepted by the server (default: DefaultAPIKey)
+func WithAPIKey(key string) Option {
+	return func(s *Server) {
+		s.apiKey = key
+	}
+}
+
+// WithJob scripts every job submitted to the server
+func WithJob(job Job) Option {
+	return func(s *Server) {
+		s.script = func(Submission) Job { return job }
+	}
+}
+
+// WithJobFunc scripts each job from its submission, e.g. by file name
+func WithJobFunc(fn func(Submission) Job) Option {
+	return func(s *Server) {
+		s.script = fn
+	}
+}
+
+// WithPartSize sets the size of upload parts (default: DefaultPartSize)
+func WithPartSize(n int) Option {
+	return func(s *Server) {
+		s.partSize = n
+	}
+}
+
+// WithLatency delays every response of endpoint by d
+func WithLatency(endpoint Endpoint, d time.Duration) Option {
+	return func(s *Server) {
+		s.latency[endpoint] = d
+	}
+}
+
+// WithFailure makes requests fail as described by f
+func WithFailure(f Failure) Option {
+	return func(s *Server) {
+		s.failures = append(s.failures, &f)
+	}
+}
+
+// WithRateLimit answers API requests beyond limit per window with 429 and a
+// Retry-After header; part uploads are not limited
+func WithRateLimit(limit int, window time.Duration) Option {
+	return func(s *Server) {
+		s.rateLimit = limit
+		s.rateWindow = window
+	}
+}
+
+// WithModels sets the models returned by the model list (default: standard-v2 and pro-v2)
+func WithModels(models []ocr.ModelInfo) Option {
+	return func(s *Server) {
+		s.models = models
+	}
+}
+
+// Server is a fake LeapOCR API served by an httptest.Server
+type Server struct {
+	*httptest.Server
+
+	apiKey     string
+	script     func(Submission) Job
+	partSize   int
+	latency    map[Endpoint]time.Duration
+	rateLimit  int
+	rateWindow time.Duration
+	models     []ocr.ModelInfo
+
+	mu          sync.Mutex
+	failures    []*Failure
+	jobs        map[string]*job
+	order       []string
+	nextID      int
+	requests    map[Endpoint]int
+	windowStart time.Time
+	windowCount int
+}
+
+// job is the state of a submitted job
+type job struct {
+	submission Submission
+	script     Job
+	started    bool
+	polls      int
+	partCount  int
+	parts      map[int][]byte
+}
+
+// newJob creates a job for submission, filling in the defaults of script
+func newJob(submission Submission, script Job) *job {
+	if len(script.Statuses) == 0 {
+		script.Statuses = []Status{StatusProcessing, StatusCompleted}
+	}
+	if script.Pages == nil {
+		if submission.Format == string(ocr.FormatStructured) {
+			script.Pages = []any{map[string]any{}}
+		} else {
+			script.Pages = []any{"# " + submission.FileName}
+		}
+	}
+	if script.Credits == 0 {
+		script.Credits = len(script.Pages)
+	}
+	return &job{submission: submission, script: script, parts: make(map[int][]byte)}
+}
+
+// poll returns the status reported by a status request and advances the script
+func (j *job) poll() Status {
+	if !j.started {
+		return StatusPending
+	}
+	status := j.script.Statuses[min(j.polls, len(j.script.Statuses)-1)]
+	j.polls++
+	return status
+}
+
+// resultStatus returns the status of the result and whether it is available.
+// The result is available once the last reported status, or the first
+// scripted one before any status request, is terminal and successful.
+func (j *job) resultStatus() (Status, bool) {
+	status := j.script.Statuses[min(max(j.polls-1, 0), len(j.script.Statuses)-1)]
+	return status, j.started && (status == StatusCompleted || status == StatusPartiallyDone)
+}
+
+// errorMessage is the error reported with StatusFailed
+func (j *job) errorMessage() string {
+	if j.script.Error == "" {
+		return "processing failed"
+	}
+	return j.script.Error
+}
+
+// NewServer starts a fake API server; callers must Close it
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		apiKey:   DefaultAPIKey,
+		script:   func(Submission) Job { return Job{} },
+		partSize: DefaultPartSize,
+		latency:  make(map[Endpoint]time.Duration),
+		models:   defaultModels(),
+		jobs:     make(map[string]*job),
+		requests: make(map[Endpoint]int),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	mux := http.NewServeMux()
+	s.handle(mux, "POST /ocr/uploads/direct", EndpointDirectUpload, s.directUpload)
+	s.handle(mux, "PUT /_storage/{job_id}/{part}", EndpointUploadPart, s.uploadPart)
+	s.handle(mux, "POST /ocr/uploads/{job_id}/complete", EndpointCompleteUpload, s.completeUpload)
+	s.handle(mux, "POST /ocr/uploads/url", EndpointURLUpload, s.urlUpload)
+	s.handle(mux, "GET /ocr/status/{job_id}", EndpointStatus, s.status)
+	s.handle(mux, "GET /ocr/result/{job_id}", EndpointResult, s.result)
+	s.handle(mux, "DELETE /ocr/delete/{job_id}", EndpointDelete, s.delete)
+	s.handle(mux, "GET /ocr/models", EndpointModels, s.listModels)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Config returns an SDK configuration for the server
+func (s *Server) Config() *ocr.Config {
+	config := ocr.DefaultConfig(s.apiKey)
+	config.BaseURL = s.URL
+	return config
+}
+
+// Fail adds a failure while the server is running
+func (s *Server) Fail(f Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, &f)
+}
+
+// Submissions returns the submitted jobs in submission order
+func (s *Server) Submissions() []Submission {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	submissions := make([]Submission, 0, len(s.order))
+	for _, id := range s.order {
+		submissions = append(submissions, s.jobs[id].submission)
+	}
+	return submissions
+}
+
+// Submission returns a submitted job by ID
+func (s *Server) Submission(jobID string) (Submission, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[jobID]
+	if !ok {
+		return Submission{}, false
+	}
+	return j.submission, true
+}
+
+// Requests returns the number of requests received by endpoint, including
+// failed ones; EndpointAll counts every request
+func (s *Server) Requests(endpoint Endpoint) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if endpoint == EndpointAll {
+		total := 0
+		for _, n := range s.requests {
+			total += n
+		}
+		return total
+	}
+	return s.requests[endpoint]
+}
+
+// handle registers fn behind the authentication, latency, rate limit and failure checks
+func (s *Server) handle(mux *http.ServeMux, pattern string, endpoint Endpoint, fn http.HandlerFunc) {
+	mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		if d := s.latency[endpoint] + s.latency[EndpointAll]; d > 0 {
+			select {
+			case <-time.After(d):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		s.mu.Lock()
+		s.requests[endpoint]++
+		failure := s.takeFailure(endpoint)
+		retryAfter, limited := s.limited(endpoint)
+		s.mu.Unlock()
+
+		switch {
+		case endpoint != EndpointUploadPart && r.Header.Get("X-API-KEY") != s.apiKey:
+			writeError(w, http.StatusUnauthorized, "invalid API key")
+		case endpoint == EndpointUploadPart && r.URL.Query().Get("X-Amz-Signature") == "":
+			writeError(w, http.StatusForbidden, "missing signature")
+		case limited:
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			writeError(w, http.StatusTooManyRequests, "rate limit exceeded")
+		case failure != nil:
+			if failure.RetryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(failure.RetryAfter.Seconds()))))
+			}
+			status, message := failure.StatusCode, failure.Message
+			if status == 0 {
+				status = http.StatusInternalServerError
+			}
+			if message == "" {
+				message = http.StatusText(status)
+			}
+			writeError(w, status, message)
+		default:
+			fn(w, r)
+		}
+	})
+}
+
+// takeFailure returns the failure for a request of endpoint, if any; callers must hold s.mu
+func (s *Server) takeFailure(endpoint Endpoint) *Failure {
+	for i, f := range s.failures {
+		if f.Endpoint != EndpointAll && f.Endpoint != endpoint {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+// limited counts a request against the rate limit and reports whether it is
+// over it and when the window resets; callers must hold s.mu
+func (s *Server) limited(endpoint Endpoint) (time.Duration, bool) {
+	if s.rateLimit <= 0 || endpoint == EndpointUploadPart {
+		return 0, false
+	}
+	now := time.Now()
+	if now.Sub(s.windowStart) >= s.rateWindow {
+		s.windowStart, s.windowCount = now, 0
+	}
+	s.windowCount++
+	return s.rateWindow - now.Sub(s.windowStart), s.windowCount > s.rateLimit
+}
+
+// submit creates a job for submission, scripted by the job function
+func (s *Server) submit(submission Submission, started bool, partCount int) *job {
+	s.mu.Lock()
+	s.nextID++
+	submission.JobID = fmt.Sprintf("job_%d", s.nextID)
+	s.mu.Unlock()
+
+	j := newJob(submission, s.script(submission))
+	j.started = started
+	j.partCount = partCount
+	s.mu.Lock()
+	s.jobs[submission.JobID] = j
+	s.order = append(s.order, submission.JobID)
+	s.mu.Unlock()
+	return j
+}
+
+// lookup returns a job that was not deleted; callers must hold s.mu
+func (s *Server) lookup(w http.ResponseWriter, r *http.Request) *job {
+	j, ok := s.jobs[r.PathValue("job_id")]
+	if !ok || j.submission.Deleted {
+		writeError(w, http.StatusNotFound, "job not found")
+		return nil
+	}
+	return j
+}
+
+func (s *Server) directUpload(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		FileName     string         `json:"file_name"`
+		ContentType  string         `json:"content_type"`
+		FileSize     int            `json:"file_size"`
+		Format       string         `json:"format"`
+		Model        string         `json:"model"`
+		Instructions string         `json:"instructions"`
+		Schema       map[string]any `json:"schema"`
+		TemplateSlug string         `json:"template_slug"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.FileName == "" || req.FileSize <= 0 {
+		writeError(w, http.StatusBadRequest, "file_name and file_size are required")
+		return
+	}
+	if req.Format == "" && req.TemplateSlug == "" {
+		writeError(w, http.StatusBadRequest, "format or template_slug is required")
+		return
+	}
+
+	partCount := (req.FileSize + s.partSize - 1) / s.partSize
+	jobID := s.submit(Submission{
+		FileName:     req.FileName,
+		Format:       req.Format,
+		Model:        req.Model,
+		Instructions: req.Instructions,
+		Schema:       req.Schema,
+		TemplateSlug: req.TemplateSlug,
+	}, false, partCount).submission.JobID
+
+	parts := make([]map[string]any, 0, partCount)
+	for i := range partCount {
+		parts = append(parts, map[string]any{
+			"part_number": i + 1,
+			"start_byte":  i * s.partSize,
+			"end_byte":    min((i+1)*s.partSize, req.FileSize) - 1,
+			"upload_url":  fmt.Sprintf("%s/_storage/%s/%d?X-Amz-Signature=fake", s.URL, jobID, i+1),
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"job_id":       jobID,
+		"upload_type":  "multipart",
+		"chunk_size":   s.partSize,
+		"total_chunks": partCount,
+		"parts":        parts,
+		"complete_url": fmt.Sprintf("%s/ocr/uploads/%s/complete", s.URL, jobID),
+		"expires_at":   time.Now().Add(time.Hour).UTC(),
+	})
+}
+
+func (s *Server) uploadPart(w http.ResponseWriter, r *http.Request) {
+	part, err := strconv.Atoi(r.PathValue("part"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "unknown part")
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "failed to read part")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j := s.lookup(w, r)
+	if j == nil {
+		return
+	}
+	if part < 1 || part > j.partCount || j.started {
+		writeError(w, http.StatusNotFound, "unknown part")
+		return
+	}
+	j.parts[part] = body
+	w.Header().Set("ETag", `"`+etag(body)+`"`)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) completeUpload(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Parts []struct {
+			PartNumber int    `json:"part_number"`
+			ETag       string `json:"etag"`
+		} `json:"parts"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j := s.lookup(w, r)
+	if j == nil {
+		return
+	}
+	if len(req.Parts) != j.partCount {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("expected %d parts, got %d", j.partCount, len(req.Parts)))
+		return
+	}
+	var content []byte
+	for i, p := range req.Parts {
+		data, ok := j.parts[p.PartNumber]
+		if p.PartNumber != i+1 || !ok || p.ETag != etag(data) {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("part %d was not uploaded or its ETag does not match", p.PartNumber))
+			return
+		}
+		content = append(content, data...)
+	}
+
+	j.submission.Content = content
+	j.started = true
+	writeJSON(w, http.StatusOK, map[string]any{
+		"job_id":     j.submission.JobID,
+		"status":     StatusPending,
+		"message":    "upload completed",
+		"created_at": time.Now().UTC(),
+	})
+}
+
+func (s *Server) urlUpload(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		URL          string         `json:"url"`
+		FileName     string         `json:"file_name"`
+		Format       string         `json:"format"`
+		Model        string         `json:"model"`
+		Instructions string         `json:"instructions"`
+		Schema       map[string]any `json:"schema"`
+		TemplateSlug string         `json:"template_slug"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.URL == "" {
+		writeError(w, http.StatusBadRequest, "url is required")
+		return
+	}
+	if req.Format == "" && req.TemplateSlug == "" {
+		writeError(w, http.StatusBadRequest, "format or template_slug is required")
+		return
+	}
+
+	jobID := s.submit(Submission{
+		FileName:     req.FileName,
+		URL:          req.URL,
+		Format:       req.Format,
+		Model:        req.Model,
+		Instructions: req.Instructions,
+		Schema:       req.Schema,
+		TemplateSlug: req.TemplateSlug,
+	}, true, 0).submission.JobID
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"job_id":     jobID,
+		"source_url": req.URL,
+		"status":     StatusPending,
+		"created_at": time.Now().UTC(),
+	})
+}
+
+func (s *Server) status(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j := s.lookup(w, r)
+	if j == nil {
+		return
+	}
+
+	status := j.poll()
+
+	total := len(j.script.Pages)
+	resp := map[string]any{
+		"job_id":      j.submission.JobID,
+		"status":      status,
+		"total_pages": total,
+	}
+	switch status {
+	case StatusCompleted, StatusPartiallyDone:
+		resp["processed_pages"] = total
+		resp["completed_at"] = time.Now().UTC().Format(time.RFC3339)
+	case StatusProcessing:
+		resp["processed_pages"] = total / 2
+	case StatusFailed:
+		resp["error_message"] = j.errorMessage()
+	default:
+		resp["processed_pages"] = 0
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) result(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j := s.lookup(w, r)
+	if j == nil {
+		return
+	}
+
+	status, ok := j.resultStatus()
+	if !ok {
+		writeError(w, http.StatusConflict, "job is not completed")
+		return
+	}
+
+	pages := make([]map[string]any, 0, len(j.script.Pages))
+	for i, page := range j.script.Pages {
+		pages = append(pages, map[string]any{
+			"id":          fmt.Sprintf("%s_page_%d", j.submission.JobID, i+1),
+			"page_number": i + 1,
+			"result":      page,
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"job_id":          j.submission.JobID,
+		"status":          status,
+		"file_name":       j.submission.FileName,
+		"model":           j.submission.Model,
+		"result_format":   j.submission.Format,
+		"credits_used":    j.script.Credits,
+		"total_pages":     len(pages),
+		"processed_pages": len(pages),
+		"pages":           pages,
+		"completed_at":    time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+func (s *Server) delete(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j := s.lookup(w, r)
+	if j == nil {
+		return
+	}
+	j.submission.Deleted = true
+	j.submission.Content = nil
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listModels(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"count": len(s.models), "models": s.models})
+}
+
+// defaultModels returns the models of the production API
+func defaultModels() []ocr.ModelInfo {
+	return []ocr.ModelInfo{
+		{Name: string(ocr.ModelStandardV2), DisplayName: "Standard", Description: "Baseline model that handles all cases", CreditsPerPage: 1, Priority: 2},
+		{Name: string(ocr.ModelProV2), DisplayName: "Pro", Description: "Highest quality model", CreditsPerPage: 3, Priority: 6},
+	}
+}
+
+// etag returns the storage ETag of data, without quotes
+func etag(data []byte) string {
+	sum := md5.Sum(data) // #nosec G401 - not used for security
+	return hex.EncodeToString(sum[:])
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v) //nolint:errcheck
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
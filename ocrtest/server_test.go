@@ -0,0 +1,184 @@
+package ocrtest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	ocr "github.com/leapocr/leapocr-go"
+)
+
+// fastWait polls without delay so that scripted lifecycles finish quickly
+var fastWait = ocr.WaitOptions{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, MaxJitter: time.Millisecond}
+
+func newSDK(t *testing.T, server *Server, configure func(*ocr.Config)) *ocr.SDK {
+	t.Helper()
+	config := server.Config()
+	if configure != nil {
+		configure(config)
+	}
+	sdk, err := ocr.NewSDK(config)
+	if err != nil {
+		t.Fatalf("failed to create SDK: %v", err)
+	}
+	return sdk
+}
+
+func TestProcessFile(t *testing.T) {
+	server := NewServer(WithPartSize(4), WithJob(Job{
+		Statuses: []Status{StatusProcessing, StatusProcessing, StatusCompleted},
+		Pages:    []any{"# Page 1", "# Page 2"},
+	}))
+	defer server.Close()
+	sdk := newSDK(t, server, nil)
+	ctx := context.Background()
+
+	content := []byte("%PDF-1.7 fake")
+	job, err := sdk.ProcessFile(ctx, bytes.NewReader(content), "doc.pdf", ocr.WithFormat(ocr.FormatMarkdown), ocr.WithModel(ocr.ModelProV2))
+	if err != nil {
+		t.Fatalf("ProcessFile failed: %v", err)
+	}
+	submission, ok := server.Submission(job.ID)
+	if !ok || !bytes.Equal(submission.Content, content) || submission.Model != "pro-v2" || submission.Format != "markdown" {
+		t.Fatalf("unexpected submission: %+v", submission)
+	}
+	if n := server.Requests(EndpointUploadPart); n != 4 {
+		t.Errorf("expected 4 part uploads, got %d", n)
+	}
+
+	if _, err := sdk.GetJobResult(ctx, job.ID); !isStatus(err, http.StatusConflict) {
+		t.Errorf("expected 409 before completion, got %v", err)
+	}
+
+	result, err := sdk.WaitUntilDoneWithOptions(ctx, job.ID, fastWait)
+	if err != nil {
+		t.Fatalf("WaitUntilDone failed: %v", err)
+	}
+	if len(result.Pages) != 2 || result.Credits != 2 || !strings.Contains(result.Text, "# Page 2") {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if n := server.Requests(EndpointStatus); n != 3 {
+		t.Errorf("expected 3 status requests, got %d", n)
+	}
+
+	if err := sdk.DeleteJob(ctx, job.ID); err != nil {
+		t.Fatalf("DeleteJob failed: %v", err)
+	}
+	if _, err := sdk.GetJobStatus(ctx, job.ID); !isStatus(err, http.StatusNotFound) {
+		t.Errorf("expected 404 after delete, got %v", err)
+	}
+}
+
+func TestJobScripts(t *testing.T) {
+	server := NewServer(WithJobFunc(func(s Submission) Job {
+		if strings.Contains(s.URL, "broken") {
+			return Job{Statuses: []Status{StatusFailed}, Error: "unreadable document"}
+		}
+		return Job{Statuses: []Status{StatusCompleted}, Pages: []any{map[string]any{"total": 42.5}}}
+	}))
+	defer server.Close()
+	sdk := newSDK(t, server, nil)
+	ctx := context.Background()
+	schema := map[string]any{"type": "object"}
+
+	job, err := sdk.ProcessURL(ctx, "https://example.com/invoice.pdf", ocr.WithFormat(ocr.FormatStructured), ocr.WithSchema(schema))
+	if err != nil {
+		t.Fatalf("ProcessURL failed: %v", err)
+	}
+	result, err := sdk.WaitUntilDoneWithOptions(ctx, job.ID, fastWait)
+	if err != nil || result.Data["total"] != 42.5 {
+		t.Errorf("unexpected structured result %+v: %v", result, err)
+	}
+
+	job, err = sdk.ProcessURL(ctx, "https://example.com/broken.pdf", ocr.WithFormat(ocr.FormatMarkdown))
+	if err != nil {
+		t.Fatalf("ProcessURL failed: %v", err)
+	}
+	_, err = sdk.WaitUntilDoneWithOptions(ctx, job.ID, fastWait)
+	var sdkErr *ocr.SDKError
+	if !errors.As(err, &sdkErr) || sdkErr.Type != ocr.ErrorTypeJobError || !strings.Contains(sdkErr.Message, "unreadable document") {
+		t.Errorf("expected job error, got %v", err)
+	}
+
+	if submissions := server.Submissions(); len(submissions) != 2 || submissions[0].Schema["type"] != "object" {
+		t.Errorf("unexpected submissions: %+v", submissions)
+	}
+}
+
+func TestFailures(t *testing.T) {
+	t.Run("transient failures are retried", func(t *testing.T) {
+		server := NewServer(WithFailure(Failure{Endpoint: EndpointStatus, StatusCode: http.StatusServiceUnavailable, Times: 2}))
+		defer server.Close()
+		sdk := newSDK(t, server, func(c *ocr.Config) {
+			c.Retry = &ocr.RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}
+		})
+
+		job, err := sdk.ProcessURL(context.Background(), "https://example.com/doc.pdf", ocr.WithFormat(ocr.FormatMarkdown))
+		if err != nil {
+			t.Fatalf("ProcessURL failed: %v", err)
+		}
+		if _, err := sdk.GetJobStatus(context.Background(), job.ID); err != nil {
+			t.Errorf("expected the retry to succeed, got %v", err)
+		}
+		if n := server.Requests(EndpointStatus); n != 3 {
+			t.Errorf("expected 3 status requests, got %d", n)
+		}
+	})
+
+	t.Run("rate limit", func(t *testing.T) {
+		server := NewServer(WithRateLimit(1, time.Hour))
+		defer server.Close()
+		sdk := newSDK(t, server, nil)
+
+		if _, err := sdk.ListModels(context.Background()); err != nil {
+			t.Fatalf("first request failed: %v", err)
+		}
+		if _, err := sdk.ListModels(context.Background()); !isStatus(err, http.StatusTooManyRequests) {
+			t.Errorf("expected 429, got %v", err)
+		}
+	})
+
+	t.Run("runtime failure", func(t *testing.T) {
+		server := NewServer()
+		defer server.Close()
+		sdk := newSDK(t, server, nil)
+
+		server.Fail(Failure{Endpoint: EndpointUploadPart, StatusCode: http.StatusForbidden, Times: 1})
+		_, err := sdk.ProcessFile(context.Background(), strings.NewReader("%PDF"), "doc.pdf", ocr.WithFormat(ocr.FormatMarkdown))
+		var sdkErr *ocr.SDKError
+		if !errors.As(err, &sdkErr) || sdkErr.Type != ocr.ErrorTypeUploadError {
+			t.Errorf("expected upload error, got %v", err)
+		}
+	})
+
+	t.Run("latency", func(t *testing.T) {
+		server := NewServer(WithLatency(EndpointModels, time.Second))
+		defer server.Close()
+		sdk := newSDK(t, server, func(c *ocr.Config) { c.Timeout = 20 * time.Millisecond })
+
+		_, err := sdk.ListModels(context.Background())
+		var sdkErr *ocr.SDKError
+		if !errors.As(err, &sdkErr) || sdkErr.Type != ocr.ErrorTypeTimeout {
+			t.Errorf("expected timeout, got %v", err)
+		}
+	})
+
+	t.Run("wrong API key", func(t *testing.T) {
+		server := NewServer(WithAPIKey("right-key"))
+		defer server.Close()
+		sdk := newSDK(t, server, func(c *ocr.Config) { c.APIKey = "wrong-key" })
+
+		if _, err := sdk.ListModels(context.Background()); !isStatus(err, http.StatusUnauthorized) {
+			t.Errorf("expected 401, got %v", err)
+		}
+	})
+}
+
+func isStatus(err error, status int) bool {
+	var sdkErr *ocr.SDKError
+	return errors.As(err, &sdkErr) && sdkErr.StatusCode == status
+}
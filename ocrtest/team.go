@@ -0,0 +1,570 @@
+package ocrtest
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	ocr "github.com/leapocr/leapocr-go"
+	"github.com/leapocr/leapocr-go/webhook"
+)
+
+// defaultPageSize is the page size of list calls without a limit
+const defaultPageSize = 20
+
+func defaultEventTypes() []string {
+	return []string{
+		string(webhook.EventJobCreated),
+		string(webhook.EventJobCompleted),
+		string(webhook.EventJobPartiallyDone),
+		string(webhook.EventJobFailed),
+	}
+}
+
+// ListTemplates implements ocr.Client; cursors are offsets into the filtered templates
+func (c *Client) ListTemplates(_ context.Context, opts ocr.TemplateListOptions) (*ocr.TemplateList, error) {
+	call := Call{Method: "ListTemplates"}
+	if err := c.injected(call.Method); err != nil {
+		return nil, c.record(call, err)
+	}
+
+	c.mu.Lock()
+	var matches []ocr.Template
+	for _, t := range c.templates {
+		if (opts.Format == "" || t.Format == opts.Format) &&
+			(opts.Favorite == nil || t.Favorite == *opts.Favorite) &&
+			(opts.Search == "" || strings.Contains(strings.ToLower(t.Name), strings.ToLower(opts.Search))) {
+			matches = append(matches, cloneTemplate(t))
+		}
+	}
+	c.mu.Unlock()
+
+	page, next, err := paginate(matches, opts.Cursor, opts.Limit)
+	if err != nil {
+		return nil, c.record(call, err)
+	}
+	return &ocr.TemplateList{Templates: page, NextCursor: next, HasMore: next != ""}, c.record(call, nil)
+}
+
+// Templates implements ocr.Client
+func (c *Client) Templates(ctx context.Context, opts ocr.TemplateListOptions) iter.Seq2[ocr.Template, error] {
+	return func(yield func(ocr.Template, error) bool) {
+		for {
+			page, err := c.ListTemplates(ctx, opts)
+			if err != nil {
+				yield(ocr.Template{}, err)
+				return
+			}
+			for _, t := range page.Templates {
+				if !yield(t, nil) {
+					return
+				}
+			}
+			if !page.HasMore {
+				return
+			}
+			opts.Cursor = page.NextCursor
+		}
+	}
+}
+
+// GetTemplate implements ocr.Client
+func (c *Client) GetTemplate(_ context.Context, templateID string) (*ocr.Template, error) {
+	call := Call{Method: "GetTemplate", ID: templateID}
+	if err := c.injected(call.Method); err != nil {
+		return nil, c.record(call, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i := c.templateIndex(templateID)
+	if i < 0 {
+		return nil, c.recordLocked(call, apiError(http.StatusNotFound, "template not found"))
+	}
+	t := cloneTemplate(c.templates[i])
+	return &t, c.recordLocked(call, nil)
+}
+
+// CreateTemplate implements ocr.Client; the slug is derived from the name
+func (c *Client) CreateTemplate(_ context.Context, params ocr.TemplateParams) (*ocr.Template, error) {
+	call := Call{Method: "CreateTemplate"}
+	if err := c.injected(call.Method); err != nil {
+		return nil, c.record(call, err)
+	}
+	if err := ocr.ValidateTemplateParams(params); err != nil {
+		return nil, c.record(call, ocr.NewSDKError(ocr.ErrorTypeValidationError, "invalid template parameters", err))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	slug := strings.ToLower(strings.Join(strings.Fields(params.Name), "-"))
+	for _, t := range c.templates {
+		if t.Slug == slug {
+			return nil, c.recordLocked(call, apiError(http.StatusConflict, "template slug already exists"))
+		}
+	}
+
+	now := c.now()
+	id := len(c.templates) + 1
+	for c.templateIndex(fmt.Sprintf("tpl_%d", id)) >= 0 {
+		id++
+	}
+	t := ocr.Template{
+		ID:        fmt.Sprintf("tpl_%d", id),
+		Slug:      slug,
+		Model:     params.Model,
+		Enabled:   true,
+		CreatedAt: now,
+	}
+	if t.Model == "" {
+		t.Model = string(ocr.ModelStandardV2)
+	}
+	applyTemplateParams(&t, params, now)
+	c.templates = append(c.templates, t)
+
+	call.ID = t.ID
+	created := cloneTemplate(t)
+	return &created, c.recordLocked(call, nil)
+}
+
+// UpdateTemplate implements ocr.Client; like the API it replaces every field but the model
+func (c *Client) UpdateTemplate(_ context.Context, templateID string, params ocr.TemplateParams) (*ocr.Template, error) {
+	call := Call{Method: "UpdateTemplate", ID: templateID}
+	if err := c.injected(call.Method); err != nil {
+		return nil, c.record(call, err)
+	}
+	if err := ocr.ValidateTemplateParams(params); err != nil {
+		return nil, c.record(call, ocr.NewSDKError(ocr.ErrorTypeValidationError, "invalid template parameters", err))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i := c.templateIndex(templateID)
+	if i < 0 {
+		return nil, c.recordLocked(call, apiError(http.StatusNotFound, "template not found"))
+	}
+	applyTemplateParams(&c.templates[i], params, c.now())
+	updated := cloneTemplate(c.templates[i])
+	return &updated, c.recordLocked(call, nil)
+}
+
+// DeleteTemplate implements ocr.Client
+func (c *Client) DeleteTemplate(_ context.Context, templateID string) error {
+	call := Call{Method: "DeleteTemplate", ID: templateID}
+	if err := c.injected(call.Method); err != nil {
+		return c.record(call, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i := c.templateIndex(templateID)
+	if i < 0 {
+		return c.recordLocked(call, apiError(http.StatusNotFound, "template not found"))
+	}
+	c.templates = slices.Delete(c.templates, i, i+1)
+	return c.recordLocked(call, nil)
+}
+
+// GetWebhookEventTypes implements ocr.Client
+func (c *Client) GetWebhookEventTypes(context.Context) ([]string, error) {
+	call := Call{Method: "GetWebhookEventTypes"}
+	if err := c.injected(call.Method); err != nil {
+		return nil, c.record(call, err)
+	}
+	return slices.Clone(c.EventTypes), c.record(call, nil)
+}
+
+// CreateWebhook implements ocr.Client
+func (c *Client) CreateWebhook(_ context.Context, params ocr.WebhookParams) (*ocr.WebhookSubscription, error) {
+	call := Call{Method: "CreateWebhook", URL: params.URL}
+	if err := c.injected(call.Method); err != nil {
+		return nil, c.record(call, err)
+	}
+	if err := c.validateWebhookParams(params, true); err != nil {
+		return nil, c.record(call, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	id := len(c.webhooks) + 1
+	for c.webhookIndex(fmt.Sprintf("wh_%d", id)) >= 0 {
+		id++
+	}
+	w := ocr.WebhookSubscription{
+		ID:        fmt.Sprintf("wh_%d", id),
+		Enabled:   true,
+		Secret:    fmt.Sprintf("whsec_%d", id),
+		CreatedAt: now,
+	}
+	applyWebhookParams(&w, params, now)
+	c.webhooks = append(c.webhooks, w)
+
+	call.ID = w.ID
+	created := cloneWebhook(w)
+	return &created, c.recordLocked(call, nil)
+}
+
+// GetWebhook implements ocr.Client
+func (c *Client) GetWebhook(_ context.Context, webhookID string) (*ocr.WebhookSubscription, error) {
+	call := Call{Method: "GetWebhook", ID: webhookID}
+	if err := c.injected(call.Method); err != nil {
+		return nil, c.record(call, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i := c.webhookIndex(webhookID)
+	if i < 0 {
+		return nil, c.recordLocked(call, apiError(http.StatusNotFound, "webhook not found"))
+	}
+	w := cloneWebhook(c.webhooks[i])
+	return &w, c.recordLocked(call, nil)
+}
+
+// UpdateWebhook implements ocr.Client; an empty URL keeps the current value
+func (c *Client) UpdateWebhook(_ context.Context, webhookID string, params ocr.WebhookParams) (*ocr.WebhookSubscription, error) {
+	call := Call{Method: "UpdateWebhook", ID: webhookID, URL: params.URL}
+	if err := c.injected(call.Method); err != nil {
+		return nil, c.record(call, err)
+	}
+	if err := c.validateWebhookParams(params, false); err != nil {
+		return nil, c.record(call, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i := c.webhookIndex(webhookID)
+	if i < 0 {
+		return nil, c.recordLocked(call, apiError(http.StatusNotFound, "webhook not found"))
+	}
+	applyWebhookParams(&c.webhooks[i], params, c.now())
+	updated := cloneWebhook(c.webhooks[i])
+	return &updated, c.recordLocked(call, nil)
+}
+
+// DeleteWebhook implements ocr.Client; the subscription's delivery log is deleted with it
+func (c *Client) DeleteWebhook(_ context.Context, webhookID string) error {
+	call := Call{Method: "DeleteWebhook", ID: webhookID}
+	if err := c.injected(call.Method); err != nil {
+		return c.record(call, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i := c.webhookIndex(webhookID)
+	if i < 0 {
+		return c.recordLocked(call, apiError(http.StatusNotFound, "webhook not found"))
+	}
+	c.webhooks = slices.Delete(c.webhooks, i, i+1)
+	delete(c.events, webhookID)
+	return c.recordLocked(call, nil)
+}
+
+// ListWebhooks implements ocr.Client; cursors are offsets into the filtered subscriptions
+func (c *Client) ListWebhooks(_ context.Context, opts ocr.WebhookListOptions) (*ocr.WebhookSubscriptionList, error) {
+	call := Call{Method: "ListWebhooks"}
+	if err := c.injected(call.Method); err != nil {
+		return nil, c.record(call, err)
+	}
+
+	c.mu.Lock()
+	var matches []ocr.WebhookSubscription
+	for _, w := range c.webhooks {
+		if opts.Enabled == nil || w.Enabled == *opts.Enabled {
+			matches = append(matches, cloneWebhook(w))
+		}
+	}
+	c.mu.Unlock()
+
+	page, next, err := paginate(matches, opts.Cursor, opts.Limit)
+	if err != nil {
+		return nil, c.record(call, err)
+	}
+	return &ocr.WebhookSubscriptionList{Subscriptions: page, NextCursor: next, HasMore: next != ""}, c.record(call, nil)
+}
+
+// TestWebhook implements ocr.Client. It logs a successful webhook.test
+// delivery for the subscription instead of sending one.
+func (c *Client) TestWebhook(_ context.Context, webhookID string) (map[string]any, error) {
+	call := Call{Method: "TestWebhook", ID: webhookID}
+	if err := c.injected(call.Method); err != nil {
+		return nil, c.record(call, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i := c.webhookIndex(webhookID)
+	if i < 0 {
+		return nil, c.recordLocked(call, apiError(http.StatusNotFound, "webhook not found"))
+	}
+	event := c.addEventLocked(webhookID, ocr.WebhookEvent{
+		EventType:  string(webhook.EventWebhookTest),
+		Status:     "delivered",
+		HTTPStatus: http.StatusOK,
+		Attempts:   1,
+		Payload:    map[string]any{"webhook_id": webhookID},
+	})
+	c.webhooks[i].LastTriggeredAt = event.CreatedAt
+	return map[string]any{"success": true, "status_code": http.StatusOK, "event_id": event.EventID}, c.recordLocked(call, nil)
+}
+
+// CheckWebhookHealth implements ocr.Client; a subscription is healthy while
+// it is enabled and has no failures
+func (c *Client) CheckWebhookHealth(_ context.Context, webhookID string) (map[string]any, error) {
+	call := Call{Method: "CheckWebhookHealth", ID: webhookID}
+	if err := c.injected(call.Method); err != nil {
+		return nil, c.record(call, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i := c.webhookIndex(webhookID)
+	if i < 0 {
+		return nil, c.recordLocked(call, apiError(http.StatusNotFound, "webhook not found"))
+	}
+	w := c.webhooks[i]
+	return map[string]any{
+		"healthy":       w.Enabled && w.FailureCount == 0,
+		"enabled":       w.Enabled,
+		"failure_count": w.FailureCount,
+	}, c.recordLocked(call, nil)
+}
+
+// RotateWebhookSecret implements ocr.Client
+func (c *Client) RotateWebhookSecret(_ context.Context, webhookID string) (*ocr.WebhookSecretRotation, error) {
+	call := Call{Method: "RotateWebhookSecret", ID: webhookID}
+	if err := c.injected(call.Method); err != nil {
+		return nil, c.record(call, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i := c.webhookIndex(webhookID)
+	if i < 0 {
+		return nil, c.recordLocked(call, apiError(http.StatusNotFound, "webhook not found"))
+	}
+	w := &c.webhooks[i]
+	previous := w.Secret
+	w.Secret = fmt.Sprintf("whsec_%s_r%d", strings.TrimPrefix(w.ID, "wh_"), len(c.calls)+1)
+	w.UpdatedAt = c.now()
+	subscription := cloneWebhook(*w)
+	return &ocr.WebhookSecretRotation{
+		Subscription:   &subscription,
+		Secret:         w.Secret,
+		PreviousSecret: previous,
+	}, c.recordLocked(call, nil)
+}
+
+// AddWebhookEvent appends an entry to the delivery log of a subscription and
+// returns it. Empty IDs, event type and times are filled in.
+func (c *Client) AddWebhookEvent(subscriptionID string, event ocr.WebhookEvent) ocr.WebhookEvent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.addEventLocked(subscriptionID, event)
+}
+
+// addEventLocked appends an entry to a delivery log; callers must hold c.mu
+func (c *Client) addEventLocked(subscriptionID string, event ocr.WebhookEvent) ocr.WebhookEvent {
+	n := 1
+	for _, events := range c.events {
+		n += len(events)
+	}
+	if event.ID == "" {
+		event.ID = fmt.Sprintf("log_%d", n)
+	}
+	if event.EventID == "" {
+		event.EventID = fmt.Sprintf("evt_%d", n)
+	}
+	if event.EventType == "" {
+		event.EventType = string(webhook.EventJobCompleted)
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = c.now()
+	}
+	if event.UpdatedAt.IsZero() {
+		event.UpdatedAt = event.CreatedAt
+	}
+	if i := c.webhookIndex(subscriptionID); i >= 0 && event.WebhookURL == "" {
+		event.WebhookURL = c.webhooks[i].URL
+	}
+	c.events[subscriptionID] = append(c.events[subscriptionID], event)
+	return event
+}
+
+// ListWebhookEvents implements ocr.Client; entries are listed newest first
+// and cursors are offsets into the filtered entries
+func (c *Client) ListWebhookEvents(_ context.Context, filter ocr.WebhookEventFilter, cursor string) (*ocr.WebhookEventList, error) {
+	call := Call{Method: "ListWebhookEvents", ID: filter.SubscriptionID}
+	if err := c.injected(call.Method); err != nil {
+		return nil, c.record(call, err)
+	}
+	if filter.SubscriptionID == "" {
+		return nil, c.record(call, ocr.NewSDKError(ocr.ErrorTypeValidationError, "subscription ID is required", nil))
+	}
+
+	c.mu.Lock()
+	if c.webhookIndex(filter.SubscriptionID) < 0 {
+		c.mu.Unlock()
+		return nil, c.record(call, apiError(http.StatusNotFound, "webhook not found"))
+	}
+	var matches []ocr.WebhookEvent
+	for _, event := range c.events[filter.SubscriptionID] {
+		if (filter.EventType == "" || event.EventType == filter.EventType) &&
+			(filter.Status == "" || event.Status == filter.Status) &&
+			(filter.Since.IsZero() || !event.CreatedAt.Before(filter.Since)) &&
+			(filter.Until.IsZero() || !event.CreatedAt.After(filter.Until)) {
+			matches = append(matches, event)
+		}
+	}
+	c.mu.Unlock()
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].CreatedAt.After(matches[j].CreatedAt) })
+	page, next, err := paginate(matches, cursor, filter.PageSize)
+	if err != nil {
+		return nil, c.record(call, err)
+	}
+	return &ocr.WebhookEventList{Events: page, NextCursor: next, HasMore: next != ""}, c.record(call, nil)
+}
+
+// WebhookEvents implements ocr.Client
+func (c *Client) WebhookEvents(ctx context.Context, filter ocr.WebhookEventFilter) iter.Seq2[ocr.WebhookEvent, error] {
+	return func(yield func(ocr.WebhookEvent, error) bool) {
+		cursor := ""
+		for {
+			page, err := c.ListWebhookEvents(ctx, filter, cursor)
+			if err != nil {
+				yield(ocr.WebhookEvent{}, err)
+				return
+			}
+			for _, event := range page.Events {
+				if !yield(event, nil) {
+					return
+				}
+			}
+			if !page.HasMore {
+				return
+			}
+			cursor = page.NextCursor
+		}
+	}
+}
+
+// GetWebhookEventPayload implements ocr.Client; payloads are never enriched
+func (c *Client) GetWebhookEventPayload(_ context.Context, id string) (*ocr.WebhookEventPayload, error) {
+	call := Call{Method: "GetWebhookEventPayload", ID: id}
+	if err := c.injected(call.Method); err != nil {
+		return nil, c.record(call, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, events := range c.events {
+		for _, event := range events {
+			if event.ID == id {
+				return &ocr.WebhookEventPayload{
+					EventID:   event.EventID,
+					EventType: event.EventType,
+					Payload:   event.Payload,
+				}, c.recordLocked(call, nil)
+			}
+		}
+	}
+	return nil, c.recordLocked(call, apiError(http.StatusNotFound, "webhook event not found"))
+}
+
+// recordLocked is record for callers holding c.mu
+func (c *Client) recordLocked(call Call, err error) error {
+	call.Err = err
+	c.calls = append(c.calls, call)
+	return err
+}
+
+// templateIndex returns the index of a template or -1; callers must hold c.mu
+func (c *Client) templateIndex(id string) int {
+	return slices.IndexFunc(c.templates, func(t ocr.Template) bool { return t.ID == id })
+}
+
+// webhookIndex returns the index of a subscription or -1; callers must hold c.mu
+func (c *Client) webhookIndex(id string) int {
+	return slices.IndexFunc(c.webhooks, func(w ocr.WebhookSubscription) bool { return w.ID == id })
+}
+
+// validateWebhookParams checks params like the SDK and the events against EventTypes
+func (c *Client) validateWebhookParams(params ocr.WebhookParams, create bool) error {
+	if err := ocr.ValidateWebhookParams(params, create); err != nil {
+		return ocr.NewSDKError(ocr.ErrorTypeValidationError, "invalid webhook parameters", err)
+	}
+	for _, event := range params.Events {
+		if !slices.Contains(c.EventTypes, event) {
+			return apiError(http.StatusBadRequest, "unknown event type: "+event)
+		}
+	}
+	return nil
+}
+
+func applyTemplateParams(t *ocr.Template, params ocr.TemplateParams, now time.Time) {
+	t.Name = params.Name
+	t.Description = params.Description
+	t.Format = params.Format
+	t.Instructions = params.Instructions
+	t.Schema = params.Schema
+	t.Tags = slices.Clone(params.Tags)
+	if params.Enabled != nil {
+		t.Enabled = *params.Enabled
+	}
+	if params.ExtractBoundingBoxes != nil {
+		t.ExtractBoundingBoxes = *params.ExtractBoundingBoxes
+	}
+	t.UpdatedAt = now
+}
+
+func applyWebhookParams(w *ocr.WebhookSubscription, params ocr.WebhookParams, now time.Time) {
+	if params.URL != "" {
+		w.URL = params.URL
+	}
+	w.Description = params.Description
+	w.Events = slices.Clone(params.Events)
+	if params.Enabled != nil {
+		w.Enabled = *params.Enabled
+	}
+	w.UpdatedAt = now
+}
+
+func cloneTemplate(t ocr.Template) ocr.Template {
+	t.Tags = slices.Clone(t.Tags)
+	return t
+}
+
+func cloneWebhook(w ocr.WebhookSubscription) ocr.WebhookSubscription {
+	w.Events = slices.Clone(w.Events)
+	return w
+}
+
+// paginate returns the page of items starting at the offset cursor and the
+// cursor of the next page, which is empty on the last page
+func paginate[T any](items []T, cursor string, limit int) ([]T, string, error) {
+	start := 0
+	if cursor != "" {
+		var err error
+		start, err = strconv.Atoi(cursor)
+		if err != nil || start < 0 || start > len(items) {
+			return nil, "", apiError(http.StatusBadRequest, "invalid cursor")
+		}
+	}
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	end := min(start+limit, len(items))
+	next := ""
+	if end < len(items) {
+		next = strconv.Itoa(end)
+	}
+	return items[start:end], next, nil
+}
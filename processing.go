@@ -3,26 +3,36 @@ package ocr
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"time"
 
 	"github.com/leapocr/leapocr-go/internal/generated"
+	"github.com/leapocr/leapocr-go/metrics"
 )
 
 // ProcessURL starts OCR processing for a file at the given URL
-func (s *SDK) ProcessURL(ctx context.Context, fileURL string, opts ...ProcessingOption) (*Job, error) {
+func (s *SDK) ProcessURL(ctx context.Context, fileURL string, opts ...ProcessingOption) (job *Job, err error) {
 	// Validate URL
 	if err := ValidateURL(fileURL); err != nil {
 		return nil, NewSDKError(ErrorTypeValidationError, "invalid URL", err)
 	}
 
-	config := applyProcessingOptions(opts)
+	config := s.processingOptions(opts)
 
 	// Validate processing configuration
 	if err := ValidateProcessingConfig(config); err != nil {
 		return nil, NewSDKError(ErrorTypeValidationError, "invalid processing configuration", err)
 	}
 
+	ctx, span := s.startSpan(ctx, "leapocr.ProcessURL", attrModel.String(config.model), attrFormat.String(string(config.format)))
+	defer func() {
+		endSpan(span, err)
+		s.recordSubmission(metrics.SourceURL, job, err)
+	}()
+
 	// Create the URL upload request
 	uploadRequest := generated.UploadRemoteURLUploadRequest{
 		Url: fileURL,
@@ -55,7 +65,7 @@ func (s *SDK) ProcessURL(ctx context.Context, fileURL string, opts ...Processing
 
 	resp, httpResp, err := apiRequest.Execute()
 	if err != nil {
-		return nil, s.handleAPIError(err, httpResp, "failed to start processing from URL")
+		return nil, s.handleAPIError(ctx, err, httpResp, "failed to start processing from URL")
 	}
 
 	// Extract job ID from response
@@ -63,6 +73,8 @@ func (s *SDK) ProcessURL(ctx context.Context, fileURL string, opts ...Processing
 	if resp.JobId != nil {
 		jobID = *resp.JobId
 	}
+	s.logger.InfoContext(ctx, "processing started from URL", "job_id", jobID, "model", config.model)
+	span.SetAttributes(attrJobID.String(jobID))
 
 	return &Job{
 		ID:     jobID,
@@ -72,7 +84,7 @@ func (s *SDK) ProcessURL(ctx context.Context, fileURL string, opts ...Processing
 
 // ProcessFile starts OCR processing for a file from an io.Reader
 func (s *SDK) ProcessFile(ctx context.Context, file io.Reader, filename string, opts ...ProcessingOption) (*Job, error) {
-	config := applyProcessingOptions(opts)
+	config := s.processingOptions(opts)
 
 	// Validate and read file
 	fileContent, fileSize32, err := s.validateAndReadFile(file, filename, config)
@@ -80,6 +92,73 @@ func (s *SDK) ProcessFile(ctx context.Context, file io.Reader, filename string,
 		return nil, err
 	}
 
+	return s.submitFileContent(ctx, fileContent, fileSize32, filename, config)
+}
+
+// ProcessFileAndWait processes a file and waits for its result. When a
+// ResultCache is configured, a byte-identical file processed earlier with the
+// same settings is answered from the cache without submitting a job.
+func (s *SDK) ProcessFileAndWait(ctx context.Context, file io.Reader, filename string, opts ...ProcessingOption) (*OCRResult, error) {
+	job, cached, cacheKey, err := s.processFileCached(ctx, file, filename, opts)
+	if err != nil {
+		return nil, err
+	}
+	if cached != nil {
+		return cached, nil
+	}
+
+	result, err := s.WaitUntilDone(ctx, job.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.storeResult(ctx, cacheKey, result)
+	return result, nil
+}
+
+// processFileCached returns the cached result of the file if there is one and
+// submits it otherwise. The returned key is empty when no cache is configured.
+func (s *SDK) processFileCached(ctx context.Context, file io.Reader, filename string, opts []ProcessingOption) (*Job, *OCRResult, string, error) {
+	config := s.processingOptions(opts)
+
+	fileContent, fileSize32, err := s.validateAndReadFile(file, filename, config)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	var cacheKey string
+	if s.config.ResultCache != nil {
+		cacheKey, err = resultCacheKey(fileContent, config)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		if cached := s.cachedResult(ctx, cacheKey); cached != nil {
+			return nil, cached, cacheKey, nil
+		}
+	}
+
+	job, err := s.submitFileContent(ctx, fileContent, fileSize32, filename, config)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return job, nil, cacheKey, nil
+}
+
+// submitFileContent uploads validated file content and starts processing
+func (s *SDK) submitFileContent(ctx context.Context, fileContent []byte, fileSize32 int32, filename string, config *processingConfig) (job *Job, err error) {
+	started := time.Now()
+
+	ctx, cancel := withTimeout(ctx, TimeoutPhaseProcessFile, s.config.OperationTimeout)
+	defer cancel()
+
+	ctx, span := s.startSpan(ctx, "leapocr.ProcessFile",
+		attrFileName.String(filename), attrFileSize.Int(int(fileSize32)),
+		attrModel.String(config.model), attrFormat.String(string(config.format)))
+	defer func() {
+		err = phaseTimeout(ctx, err)
+		endSpan(span, err)
+		s.recordSubmission(metrics.SourceFile, job, err)
+	}()
+
 	// Build initiate request
 	initiateRequest := s.buildInitiateRequest(filename, fileSize32, config)
 
@@ -93,17 +172,29 @@ func (s *SDK) ProcessFile(ctx context.Context, file io.Reader, filename string,
 	if uploadResp.JobId != nil {
 		jobID = *uploadResp.JobId
 	}
+	span.SetAttributes(attrJobID.String(jobID))
+	s.logger.InfoContext(ctx, "upload initiated",
+		"job_id", jobID, "file_name", filename, "size", fileSize32, "model", config.model, "parts", len(uploadResp.Parts))
 
 	// Upload file parts to presigned URLs and collect ETags
 	completedParts, err := s.uploadFileParts(ctx, uploadResp, io.NopCloser(bytes.NewReader(fileContent)))
 	if err != nil {
+		s.logger.ErrorContext(ctx, "upload failed", "job_id", jobID, "error", err)
+		if errorTypeOf(err) == ErrorTypeTimeout {
+			return nil, err
+		}
 		return nil, NewSDKError(ErrorTypeUploadError, "failed to upload file", err)
 	}
 
 	// Complete the multipart upload
 	if err := s.completeDirectUpload(ctx, jobID, completedParts); err != nil {
+		s.logger.ErrorContext(ctx, "upload completion failed", "job_id", jobID, "error", err)
+		if errorTypeOf(err) == ErrorTypeTimeout {
+			return nil, err
+		}
 		return nil, NewSDKError(ErrorTypeUploadError, "failed to complete upload", err)
 	}
+	s.logger.InfoContext(ctx, "upload completed", "job_id", jobID, "parts", len(completedParts), "duration", time.Since(started))
 
 	return &Job{
 		ID:     jobID,
@@ -174,7 +265,10 @@ func (s *SDK) buildInitiateRequest(filename string, fileSize32 int32, config *pr
 }
 
 // initiateDirectUpload initiates the direct upload and returns the response
-func (s *SDK) initiateDirectUpload(ctx context.Context, initiateRequest generated.UploadInitiateDirectUploadRequest) (*generated.UploadDirectUploadResponse, error) {
+func (s *SDK) initiateDirectUpload(ctx context.Context, initiateRequest generated.UploadInitiateDirectUploadRequest) (_ *generated.UploadDirectUploadResponse, err error) {
+	ctx, span := s.startSpan(ctx, "leapocr.upload.initiate")
+	defer func() { endSpan(span, err) }()
+
 	apiRequest := s.client.SDKAPI.DirectUpload(ctx)
 	apiRequest = apiRequest.DirectUploadRequest(
 		generated.UploadInitiateDirectUploadRequestAsDirectUploadRequest(&initiateRequest),
@@ -182,17 +276,35 @@ func (s *SDK) initiateDirectUpload(ctx context.Context, initiateRequest generate
 
 	resp, httpResp, err := apiRequest.Execute()
 	if err != nil {
-		return nil, s.handleAPIError(err, httpResp, "failed to initiate file upload")
+		return nil, s.handleAPIError(ctx, err, httpResp, "failed to initiate file upload")
 	}
 
 	return resp, nil
 }
 
 // handleAPIError converts generated client errors to SDK errors
-func (s *SDK) handleAPIError(err error, httpResp interface{}, message string) *SDKError {
-	// This would need to be implemented based on the actual generated error types
-	// For now, we'll create a generic API error
-	return NewSDKError(ErrorTypeAPIError, fmt.Sprintf("%s: %v", message, err), err)
+func (s *SDK) handleAPIError(ctx context.Context, err error, httpResp *http.Response, message string) *SDKError {
+	// Canceled contexts surface here too, e.g. while waiting for the rate limiter
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return NewSDKError(ErrorTypeTimeout, fmt.Sprintf("%s: %v", message, err), err)
+	}
+	// Credential provider failures keep their type rather than looking like API errors
+	var credErr *SDKError
+	if errors.As(err, &credErr) && credErr.Type == ErrorTypeInvalidConfig {
+		return NewSDKError(ErrorTypeInvalidConfig, fmt.Sprintf("%s: %v", message, err), err)
+	}
+
+	sdkErr := NewSDKError(ErrorTypeAPIError, fmt.Sprintf("%s: %v", message, err), err)
+	if httpResp != nil {
+		sdkErr.StatusCode = httpResp.StatusCode
+	}
+	// Client errors such as a missing job are usually handled by the caller
+	if sdkErr.StatusCode >= 400 && sdkErr.StatusCode < 500 {
+		s.logger.WarnContext(ctx, message, "status", sdkErr.StatusCode, "error", err)
+	} else {
+		s.logger.ErrorContext(ctx, message, "status", sdkErr.StatusCode, "error", err)
+	}
+	return sdkErr
 }
 
 // getContentType returns the content type based on filename
@@ -0,0 +1,211 @@
+package ocr
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// minAdaptiveFactor is the lowest fraction of the configured rate adaptive limiting slows down to
+	minAdaptiveFactor = 1.0 / 16
+	// adaptiveRecovery is the fraction of the configured rate regained per successful response
+	adaptiveRecovery = 0.05
+	// maxRetryAfter caps the pause requested by a Retry-After header
+	maxRetryAfter = time.Minute
+)
+
+// RateLimit configures client-side throttling of outgoing requests.
+// Each SDK instance keeps its own limiter state.
+type RateLimit struct {
+	// RequestsPerSecond is the sustained request rate; zero disables rate limiting
+	RequestsPerSecond float64
+	// Burst is the number of requests allowed at once above the sustained rate (default: 1)
+	Burst int
+	// MaxInFlight caps the number of concurrent requests; zero means unlimited
+	MaxInFlight int
+	// Adaptive halves the rate whenever the server responds with 429 Too Many
+	// Requests, pauses for its Retry-After, and recovers gradually on success
+	Adaptive bool
+}
+
+// validateRateLimit checks a RateLimit from the configuration
+func validateRateLimit(field string, limit *RateLimit) error {
+	if limit == nil {
+		return nil
+	}
+	if limit.RequestsPerSecond < 0 || limit.Burst < 0 || limit.MaxInFlight < 0 {
+		return NewSDKError(ErrorTypeInvalidConfig, field+" values must not be negative", nil)
+	}
+	return nil
+}
+
+// rateLimiter is a token bucket combined with a bound on concurrent requests
+type rateLimiter struct {
+	limit RateLimit
+	slots chan struct{}
+	now   func() time.Time
+
+	mu          sync.Mutex
+	tokens      float64
+	last        time.Time
+	factor      float64
+	pausedUntil time.Time
+}
+
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	if limit.Burst <= 0 {
+		limit.Burst = 1
+	}
+	l := &rateLimiter{
+		limit:  limit,
+		now:    time.Now,
+		tokens: float64(limit.Burst),
+		factor: 1,
+	}
+	if limit.MaxInFlight > 0 {
+		l.slots = make(chan struct{}, limit.MaxInFlight)
+	}
+	l.last = l.now()
+	return l
+}
+
+// acquire blocks until a request may be sent and returns a function releasing its in-flight slot
+func (l *rateLimiter) acquire(ctx context.Context) (func(), error) {
+	release := func() {}
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+			release = func() { <-l.slots }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	wait, reserved := l.reserve()
+	if wait <= 0 {
+		return release, nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return release, nil
+	case <-ctx.Done():
+		if reserved {
+			l.cancelReservation()
+		}
+		release()
+		return nil, ctx.Err()
+	}
+}
+
+// reserve takes a token and returns how long to wait before using it.
+// reserved reports whether a token was taken.
+func (l *rateLimiter) reserve() (wait time.Duration, reserved bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Before(l.pausedUntil) {
+		wait = l.pausedUntil.Sub(now)
+	}
+
+	rate := l.limit.RequestsPerSecond * l.factor
+	if rate <= 0 {
+		return wait, false
+	}
+
+	l.tokens = min(float64(l.limit.Burst), l.tokens+now.Sub(l.last).Seconds()*rate)
+	l.last = now
+	l.tokens--
+	if l.tokens < 0 {
+		wait = max(wait, time.Duration(-l.tokens/rate*float64(time.Second)))
+	}
+	return wait, true
+}
+
+// cancelReservation returns a token taken by a request that was never sent
+func (l *rateLimiter) cancelReservation() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens++
+}
+
+// observe adapts the rate to the server's response when adaptive limiting is enabled
+func (l *rateLimiter) observe(resp *http.Response) {
+	if !l.limit.Adaptive {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		l.factor = max(l.factor/2, minAdaptiveFactor)
+		if pause := retryAfter(resp.Header, l.now()); pause > 0 {
+			l.pausedUntil = l.now().Add(pause)
+		}
+		return
+	}
+	if resp.StatusCode < http.StatusBadRequest {
+		l.factor = min(1, l.factor+adaptiveRecovery)
+	}
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date
+func retryAfter(header http.Header, now time.Time) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+
+	var pause time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		pause = time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(value); err == nil {
+		pause = at.Sub(now)
+	}
+	return min(max(pause, 0), maxRetryAfter)
+}
+
+// limitedTransport applies a rateLimiter to every request of the wrapped transport
+type limitedTransport struct {
+	base    http.RoundTripper
+	limiter *rateLimiter
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	release, err := t.limiter.acquire(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	t.limiter.observe(resp)
+	// The request stays in flight until its body has been consumed
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: sync.OnceFunc(release)}
+	return resp, nil
+}
+
+// releasingBody releases an in-flight slot when the response body is closed
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+// Close implements io.Closer
+func (b *releasingBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,131 @@
+package ocr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterTokenBucket(t *testing.T) {
+	now := time.Now()
+	l := newRateLimiter(RateLimit{RequestsPerSecond: 10, Burst: 2})
+	l.now = func() time.Time { return now }
+	l.last = now
+
+	var waits []time.Duration
+	for range 4 {
+		wait, _ := l.reserve()
+		waits = append(waits, wait)
+	}
+	want := []time.Duration{0, 0, 100 * time.Millisecond, 200 * time.Millisecond}
+	for i := range want {
+		if waits[i] != want[i] {
+			t.Errorf("wait %d = %v, want %v", i, waits[i], want[i])
+		}
+	}
+
+	// After a second the bucket is full again but never above the burst
+	now = now.Add(time.Second)
+	if wait, _ := l.reserve(); wait != 0 {
+		t.Errorf("expected no wait after refill, got %v", wait)
+	}
+	if wait, _ := l.reserve(); wait != 0 {
+		t.Errorf("expected burst to be available, got %v", wait)
+	}
+	if wait, _ := l.reserve(); wait == 0 {
+		t.Error("expected wait beyond the burst")
+	}
+}
+
+func TestRateLimiterAdaptive(t *testing.T) {
+	now := time.Now()
+	l := newRateLimiter(RateLimit{RequestsPerSecond: 8, Adaptive: true})
+	l.now = func() time.Time { return now }
+	l.last = now
+
+	throttled := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	throttled.Header.Set("Retry-After", "3")
+	l.observe(throttled)
+	if l.factor != 0.5 {
+		t.Errorf("expected rate to be halved, got factor %v", l.factor)
+	}
+	if wait, _ := l.reserve(); wait != 3*time.Second {
+		t.Errorf("expected Retry-After pause of 3s, got %v", wait)
+	}
+
+	for range 20 {
+		l.observe(throttled)
+	}
+	if l.factor != minAdaptiveFactor {
+		t.Errorf("expected factor floor %v, got %v", minAdaptiveFactor, l.factor)
+	}
+
+	for range 100 {
+		l.observe(&http.Response{StatusCode: http.StatusOK})
+	}
+	if l.factor != 1 {
+		t.Errorf("expected full recovery, got factor %v", l.factor)
+	}
+}
+
+func TestRateLimitMaxInFlight(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /ocr/status/{id}", func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"job_id":%q,"status":"processing"}`, r.PathValue("id"))
+	})
+
+	sdk := newTestTeamSDK(t, mux)
+	sdk.client.GetConfig().HTTPClient = wrapClient(http.DefaultClient, transportOptions{limit: &RateLimit{MaxInFlight: 2}})
+
+	var wg sync.WaitGroup
+	for i := range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := sdk.GetJobStatus(context.Background(), fmt.Sprintf("job_%d", i)); err != nil {
+				t.Errorf("GetJobStatus failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := peak.Load(); got != 2 {
+		t.Errorf("expected at most 2 concurrent requests, peak was %d", got)
+	}
+
+	// Waiting for the limiter honours the context
+	slow := wrapClient(http.DefaultClient, transportOptions{limit: &RateLimit{RequestsPerSecond: 0.01}})
+	sdk.client.GetConfig().HTTPClient = slow
+	_, _ = sdk.GetJobStatus(context.Background(), "first")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := sdk.GetJobStatus(ctx, "second")
+	var sdkErr *SDKError
+	if !errors.As(err, &sdkErr) || sdkErr.Type != ErrorTypeTimeout {
+		t.Errorf("expected timeout error while throttled, got %v", err)
+	}
+}
+
+func TestRateLimitValidation(t *testing.T) {
+	config := DefaultConfig("test-key")
+	config.UploadRateLimit = &RateLimit{RequestsPerSecond: -1}
+	if _, err := NewSDK(config); err == nil {
+		t.Error("expected error for negative rate")
+	}
+}
@@ -0,0 +1,147 @@
+package ocr
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// idempotencyKeyHeader marks requests the server deduplicates, which makes
+// resending them safe whatever their method
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// RetryPolicy configures automatic retries of API requests that failed with a
+// network error or a transient status (408, 429 and 5xx). Only requests with
+// idempotent methods are retried, and POST and PATCH requests carrying an
+// Idempotency-Key header, e.g. one set with APIMiddleware.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts per request, including the first (default: 3)
+	MaxAttempts int
+	// InitialDelay is the delay before the first retry, doubled for each further retry (default: 500ms)
+	InitialDelay time.Duration
+	// MaxDelay caps the delay between attempts (default: 10 seconds)
+	MaxDelay time.Duration
+}
+
+// validateRetryPolicy checks a RetryPolicy from the configuration
+func validateRetryPolicy(policy *RetryPolicy) error {
+	if policy == nil {
+		return nil
+	}
+	if policy.MaxAttempts < 0 || policy.InitialDelay < 0 || policy.MaxDelay < 0 {
+		return NewSDKError(ErrorTypeInvalidConfig, "retry values must not be negative", nil)
+	}
+	return nil
+}
+
+func applyRetryDefaults(policy RetryPolicy) RetryPolicy {
+	if policy.MaxAttempts == 0 {
+		policy.MaxAttempts = 3
+	}
+	if policy.InitialDelay == 0 {
+		policy.InitialDelay = 500 * time.Millisecond
+	}
+	if policy.MaxDelay == 0 {
+		policy.MaxDelay = 10 * time.Second
+	}
+	return policy
+}
+
+// retryTransport resends failed requests of the wrapped transport according to a RetryPolicy
+type retryTransport struct {
+	base   http.RoundTripper
+	policy RetryPolicy
+	// logger, if set, receives one event per retry
+	logger *slog.Logger
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	delay := t.policy.InitialDelay
+	for attempt := 1; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if attempt >= t.policy.MaxAttempts || !shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		// Requests with a body can only be resent when it can be recreated
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, err
+			}
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return resp, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		wait := delay
+		if resp != nil {
+			if pause := retryAfter(resp.Header, time.Now()); pause > 0 {
+				wait = pause
+			}
+			// Drain the body so the connection can be reused
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) //nolint:errcheck
+			_ = resp.Body.Close()                                         //nolint:errcheck
+		}
+		wait = min(wait, t.policy.MaxDelay)
+		t.logRetry(req, attempt, resp, err, wait)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+		delay = min(delay*2, t.policy.MaxDelay)
+	}
+}
+
+// logRetry logs a failed attempt that is retried after delay
+func (t *retryTransport) logRetry(req *http.Request, attempt int, resp *http.Response, err error, delay time.Duration) {
+	if t.logger == nil {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("url", redactURL(req.URL)),
+		slog.Int("attempt", attempt),
+		slog.Int("max_attempts", t.policy.MaxAttempts),
+		slog.Duration("delay", delay),
+	}
+	if resp != nil {
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	} else {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	t.logger.LogAttrs(req.Context(), slog.LevelInfo, "retrying api request", attrs...)
+}
+
+// shouldRetry reports whether a failed attempt of req may be sent again
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		// Canceled requests and errors of the SDK's own layers are final
+		return req.Context().Err() == nil && errorTypeOf(err) == ErrorTypeUnknown && isIdempotent(req)
+	}
+	switch resp.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return isIdempotent(req)
+	default:
+		return resp.StatusCode >= http.StatusInternalServerError && isIdempotent(req)
+	}
+}
+
+// isIdempotent reports whether req can safely be sent more than once: a 429
+// does not guarantee that the server did not act on a request
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return req.Header.Get(idempotencyKeyHeader) != ""
+	}
+}
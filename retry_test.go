@@ -0,0 +1,108 @@
+package ocr
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryTransport(t *testing.T) {
+	var calls atomic.Int32
+	var failures atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		body, _ := io.ReadAll(r.Body) //nolint:errcheck
+		if failures.Add(-1) >= 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write(body) //nolint:errcheck
+	}))
+	t.Cleanup(server.Close)
+
+	client := wrapClient(http.DefaultClient, transportOptions{retry: &RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond}})
+
+	tests := []struct {
+		name       string
+		method     string
+		failures   int32
+		wantStatus int
+		wantCalls  int32
+	}{
+		{name: "recovers", method: http.MethodPut, failures: 2, wantStatus: http.StatusOK, wantCalls: 3},
+		{name: "gives up", method: http.MethodGet, failures: 5, wantStatus: http.StatusServiceUnavailable, wantCalls: 3},
+		{name: "post not retried", method: http.MethodPost, failures: 1, wantStatus: http.StatusServiceUnavailable, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls.Store(0)
+			failures.Store(tt.failures)
+
+			req, err := http.NewRequest(tt.method, server.URL, strings.NewReader("payload"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body) //nolint:errcheck
+			_ = resp.Body.Close()            //nolint:errcheck
+
+			if resp.StatusCode != tt.wantStatus || calls.Load() != tt.wantCalls {
+				t.Errorf("expected status %d after %d calls, got %d after %d", tt.wantStatus, tt.wantCalls, resp.StatusCode, calls.Load())
+			}
+			if resp.StatusCode == http.StatusOK && string(body) != "payload" {
+				t.Errorf("expected body to be resent, got %q", body)
+			}
+		})
+	}
+}
+
+func TestRetryTooManyRequests(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1)%2 == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(server.Close)
+
+	client := wrapClient(http.DefaultClient, transportOptions{retry: &RetryPolicy{InitialDelay: time.Millisecond}})
+	tests := []struct {
+		name       string
+		key        string
+		wantStatus int
+		wantCalls  int32
+	}{
+		{name: "post not retried", wantStatus: http.StatusTooManyRequests, wantCalls: 1},
+		{name: "post with idempotency key", key: "job-1", wantStatus: http.StatusCreated, wantCalls: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls.Store(0)
+			req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("{}"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.key != "" {
+				req.Header.Set("Idempotency-Key", tt.key)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			_ = resp.Body.Close() //nolint:errcheck
+			if resp.StatusCode != tt.wantStatus || calls.Load() != tt.wantCalls {
+				t.Errorf("expected status %d after %d calls, got %d after %d", tt.wantStatus, tt.wantCalls, resp.StatusCode, calls.Load())
+			}
+		})
+	}
+}
@@ -1,25 +1,85 @@
 package ocr
 
 import (
+	"log/slog"
 	"net/http"
+	"slices"
 	"time"
 
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/leapocr/leapocr-go/internal/generated"
+	"github.com/leapocr/leapocr-go/metrics"
 )
 
 // SDK is the main OCR API client that provides a clean, Go-native interface
 type SDK struct {
 	client *generated.APIClient
 	config *Config
+	logger *slog.Logger
+	tracer trace.Tracer
+	// metrics receives activity measurements; nil until NewSDK sets it
+	metrics metrics.Recorder
+	// submissions holds submission times of jobs while metrics are recorded
+	submissions submissionTimes
+	// uploadClient sends file parts to presigned storage URLs
+	uploadClient *http.Client
 }
 
 // Config holds the SDK configuration
 type Config struct {
+	// APIKey authenticates API requests unless Credentials is set
 	APIKey     string
 	BaseURL    string
 	HTTPClient *http.Client
 	UserAgent  string
-	Timeout    time.Duration
+	// Timeout limits each API request, including reading its response. Zero disables it.
+	Timeout time.Duration
+	// UploadPartTimeout limits the upload of each file part to storage. Zero disables it.
+	UploadPartTimeout time.Duration
+	// OperationTimeout limits a whole ProcessFile or WaitUntilDone call, in
+	// addition to the deadline of its context. Zero disables it.
+	OperationTimeout time.Duration
+	// Credentials supplies the API key for every request, allowing keys to be
+	// rotated without rebuilding the SDK (default: StaticCredentials(APIKey))
+	Credentials CredentialsProvider
+	// OrganizationID and TeamID scope team-level operations such as webhook management
+	OrganizationID string
+	TeamID         string
+	// ResultCache, when set, lets ProcessFileAndWait and Batch reuse results of
+	// byte-identical files processed with the same settings
+	ResultCache ResultCache
+	// Retry resends API requests that failed with a network error or a transient
+	// status. Nil disables retries.
+	Retry *RetryPolicy
+	// DefaultOptions apply to every job before the options passed to a call
+	DefaultOptions []ProcessingOption
+	// RateLimit throttles API requests, including job status polling
+	RateLimit *RateLimit
+	// UploadRateLimit throttles file part uploads to presigned storage URLs
+	UploadRateLimit *RateLimit
+	// UploadHTTPClient uploads file parts to presigned storage URLs. It defaults
+	// to a client without HTTPClient's transport, so that credentials added by
+	// that transport never reach the storage provider. Set it to route uploads
+	// through a proxy or custom TLS settings.
+	UploadHTTPClient *http.Client
+	// APIMiddleware intercepts every request to the LeapOCR API
+	APIMiddleware []Middleware
+	// UploadMiddleware intercepts every file part upload to presigned storage URLs
+	UploadMiddleware []Middleware
+	// Logger receives structured events about uploads, polling and API requests.
+	// API keys and presigned URL signatures are redacted. Nil disables logging.
+	Logger *slog.Logger
+	// TracerProvider creates OpenTelemetry spans for uploads, polling and result
+	// fetches. Nil disables tracing.
+	TracerProvider trace.TracerProvider
+	// Propagator injects the trace context into API requests when tracing is
+	// enabled (default: W3C trace context and baggage)
+	Propagator propagation.TextMapPropagator
+	// Metrics receives counts and latencies of submissions, uploads, polls and
+	// completions, e.g. a metrics/prometheus.Collector. Nil disables metrics.
+	Metrics metrics.Recorder
 }
 
 // DefaultConfig returns a config with sensible defaults
@@ -30,17 +90,32 @@ func DefaultConfig(apiKey string) *Config {
 		HTTPClient: &http.Client{},
 		UserAgent:  "leapocr-go/" + Version,
 		Timeout:    30 * time.Second,
+
+		UploadPartTimeout: 5 * time.Minute,
 	}
 }
 
 // NewSDK creates a new SDK instance with the given configuration
 func NewSDK(config *Config) (*SDK, error) {
-	if config.APIKey == "" {
+	credentials := config.Credentials
+	if credentials == nil && config.APIKey != "" {
+		credentials = StaticCredentials(config.APIKey)
+	}
+	if credentials == nil {
 		return nil, &SDKError{
 			Type:    ErrorTypeInvalidConfig,
 			Message: "API key is required",
 		}
 	}
+	if err := validateRetryPolicy(config.Retry); err != nil {
+		return nil, err
+	}
+	if err := validateRateLimit("rate limit", config.RateLimit); err != nil {
+		return nil, err
+	}
+	if err := validateRateLimit("upload rate limit", config.UploadRateLimit); err != nil {
+		return nil, err
+	}
 
 	// Create the generated client configuration
 	genConfig := generated.NewConfiguration()
@@ -50,12 +125,39 @@ func NewSDK(config *Config) (*SDK, error) {
 		},
 	}
 
-	// Set up authentication
-	genConfig.DefaultHeader["X-API-KEY"] = config.APIKey
+	// Configure HTTP clients; storage uploads never go through the API client's transport
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	// Keys seen by the credentials transport are redacted from logs as well
+	secrets := newSecretSet(config.APIKey)
+	logger := newLogger(config.Logger, secrets)
+	var requestLogger *slog.Logger
+	if config.Logger != nil {
+		requestLogger = logger
+	}
+	var propagator propagation.TextMapPropagator
+	if config.TracerProvider != nil {
+		propagator = config.Propagator
+		if propagator == nil {
+			propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+		}
+	}
+	genConfig.HTTPClient = wrapClient(httpClient, transportOptions{
+		credentials: credentials,
+		secrets:     secrets,
+		retry:       config.Retry,
+		limit:       config.RateLimit,
+		timeout:     config.Timeout,
+		chain:       config.APIMiddleware,
+		logger:      requestLogger,
+		propagator:  propagator,
+	})
 
-	// Configure HTTP client
-	if config.HTTPClient != nil {
-		genConfig.HTTPClient = config.HTTPClient
+	uploadClient := config.UploadHTTPClient
+	if uploadClient == nil {
+		uploadClient = &http.Client{Timeout: httpClient.Timeout}
 	}
 	if config.UserAgent != "" {
 		genConfig.UserAgent = config.UserAgent
@@ -65,8 +167,12 @@ func NewSDK(config *Config) (*SDK, error) {
 	client := generated.NewAPIClient(genConfig)
 
 	return &SDK{
-		client: client,
-		config: config,
+		client:       client,
+		config:       config,
+		logger:       logger,
+		tracer:       newTracer(config.TracerProvider),
+		metrics:      config.Metrics,
+		uploadClient: wrapClient(uploadClient, transportOptions{limit: config.UploadRateLimit, chain: config.UploadMiddleware}),
 	}, nil
 }
 
@@ -80,3 +186,31 @@ type Job struct {
 	ID     string
 	Status string
 }
+
+// processingOptions returns the configured default options followed by opts
+func (s *SDK) processingOptions(opts []ProcessingOption) *processingConfig {
+	if len(s.config.DefaultOptions) == 0 {
+		return applyProcessingOptions(opts)
+	}
+	return applyProcessingOptions(append(slices.Clip(s.config.DefaultOptions), opts...))
+}
+
+// recorder returns the configured metrics recorder or one discarding all measurements
+func (s *SDK) recorder() metrics.Recorder {
+	if s.metrics == nil {
+		return metrics.Nop{}
+	}
+	return s.metrics
+}
+
+// recordSubmission reports the outcome of a job submission from the given source
+func (s *SDK) recordSubmission(source string, job *Job, err error) {
+	if err != nil {
+		s.recorder().JobFailed(string(errorTypeOf(err)))
+		return
+	}
+	if s.metrics != nil {
+		s.submissions.add(job.ID, time.Now())
+	}
+	s.recorder().JobSubmitted(source)
+}
@@ -0,0 +1,267 @@
+package ocr
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/leapocr/leapocr-go/internal/pdf"
+)
+
+const (
+	// textLayerFont is the base font and resource name of the text layer
+	textLayerFont = "LeapOCRText"
+	// textLayerGlyphWidth is the width of every glyph of the text layer font in
+	// thousandths of the font size
+	textLayerGlyphWidth = 500
+)
+
+// WriteSearchablePDF writes a copy of the original PDF with an invisible text
+// layer to w, which makes scanned pages searchable and their text selectable.
+// The text of each block is placed over its bounding box, so result needs page
+// dimensions and bounding boxes as for WriteHOCR; pages are matched by page
+// number and pages missing from result are left as they are.
+//
+// The original is kept byte for byte and the text layer is appended to it as
+// an incremental update. Encrypted PDFs are not supported.
+func WriteSearchablePDF(original io.ReadSeeker, result *OCRResult, w io.Writer) error {
+	if original == nil {
+		return NewSDKError(ErrorTypeValidationError, "original PDF is required", nil)
+	}
+	if err := checkLayout(result); err != nil {
+		return err
+	}
+	if _, err := original.Seek(0, io.SeekStart); err != nil {
+		return NewSDKError(ErrorTypeUnknown, "failed to read original PDF", err)
+	}
+	data, err := io.ReadAll(original)
+	if err != nil {
+		return NewSDKError(ErrorTypeUnknown, "failed to read original PDF", err)
+	}
+	doc, err := pdf.Open(data)
+	if err != nil {
+		return NewSDKError(ErrorTypeValidationError, "original is not a supported PDF", err)
+	}
+	pages, err := doc.Pages()
+	if err != nil {
+		return NewSDKError(ErrorTypeValidationError, "original is not a supported PDF", err)
+	}
+
+	update := doc.NewUpdate()
+	var font, save pdf.Ref
+	for _, page := range result.Pages {
+		if page.PageNumber < 1 || page.PageNumber > len(pages) {
+			return NewSDKError(ErrorTypeValidationError,
+				fmt.Sprintf("page %d is not in the original PDF of %d pages", page.PageNumber, len(pages)), nil)
+		}
+		target := pages[page.PageNumber-1]
+		dict, fonts, fontName, err := textLayerPage(doc, target)
+		if err != nil {
+			return NewSDKError(ErrorTypeValidationError, "original is not a supported PDF", err)
+		}
+		content := textLayerContent(page, target, fontName)
+		if content == nil {
+			continue
+		}
+
+		if font == (pdf.Ref{}) {
+			font = addTextLayerFont(update)
+			// Saving the graphics state before the original content lets the
+			// text layer start from the default state
+			save = update.Add(&pdf.Stream{Dict: pdf.Dict{}, Data: []byte("q\n")})
+		}
+		fonts[fontName] = font
+		contents, err := contentsOf(doc, target.Dict)
+		if err != nil {
+			return NewSDKError(ErrorTypeValidationError, "original is not a supported PDF", err)
+		}
+		layer := update.Add(pdf.NewFlateStream(pdf.Dict{}, content))
+		dict["Contents"] = append(append(pdf.Array{save}, contents...), layer)
+		update.Replace(target.Ref, dict)
+	}
+
+	if _, err := update.WriteTo(w); err != nil {
+		return NewSDKError(ErrorTypeUnknown, "failed to write searchable PDF", err)
+	}
+	return nil
+}
+
+// textLayerPage returns a copy of the dictionary of page with its resources
+// inlined and copied, its font resources, and the name the text layer font
+// should have among them
+func textLayerPage(doc *pdf.Document, page pdf.Page) (pdf.Dict, pdf.Dict, pdf.Name, error) {
+	res, err := doc.Resolve(page.Resources)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	resources, _ := res.(pdf.Dict)
+	resources = resources.Copy()
+	fonts, err := doc.Resolve(resources["Font"])
+	if err != nil {
+		return nil, nil, "", err
+	}
+	fontDict, _ := fonts.(pdf.Dict)
+	fontDict = fontDict.Copy()
+	resources["Font"] = fontDict
+
+	name := pdf.Name(textLayerFont)
+	for i := 1; fontDict[name] != nil; i++ {
+		name = pdf.Name(textLayerFont + strconv.Itoa(i))
+	}
+	dict := page.Dict.Copy()
+	dict["Resources"] = resources
+	return dict, fontDict, name, nil
+}
+
+// contentsOf returns the content streams of a page
+func contentsOf(doc *pdf.Document, page pdf.Dict) (pdf.Array, error) {
+	switch c := page["Contents"].(type) {
+	case pdf.Ref:
+		// Contents is a stream or, rarely, an indirect array of streams
+		obj, err := doc.Object(c)
+		if err != nil {
+			return nil, err
+		}
+		if arr, ok := obj.(pdf.Array); ok {
+			return arr, nil
+		}
+		return pdf.Array{c}, nil
+	case pdf.Array:
+		return c, nil
+	}
+	return nil, nil
+}
+
+// textLayerContent returns the content stream that draws the text of page
+// invisibly over target, or nil when the page has no text. It restores the
+// graphics state saved before the original content first.
+func textLayerContent(page PageResult, target pdf.Page, font pdf.Name) []byte {
+	box := target.Box
+	width, height := box[2]-box[0], box[3]-box[1]
+	// The matrix maps the page as displayed, with its origin at the bottom
+	// left, to default user space
+	matrix := [6]float64{1, 0, 0, 1, box[0], box[1]}
+	switch target.Rotate {
+	case 90:
+		width, height = height, width
+		matrix = [6]float64{0, 1, -1, 0, box[2], box[1]}
+	case 180:
+		matrix = [6]float64{-1, 0, 0, -1, box[2], box[3]}
+	case 270:
+		width, height = height, width
+		matrix = [6]float64{0, -1, 1, 0, box[0], box[3]}
+	}
+	sx, sy := width/float64(page.Width), height/float64(page.Height)
+
+	var text bytes.Buffer
+	for _, block := range page.Blocks {
+		for _, line := range blockLines(block) {
+			runes := []rune(line.Text)
+			size := float64(line.Bounds.Height) * sy
+			lineWidth := float64(line.Bounds.Width) * sx
+			if size <= 0 || lineWidth <= 0 {
+				continue
+			}
+			// Horizontal scaling stretches the glyphs of the line over its
+			// width, which also places its words where blockLines does
+			scale := lineWidth / (float64(len(runes)) * size * textLayerGlyphWidth / 1000) * 100
+			x := float64(line.Bounds.X) * sx
+			y := height - float64(line.Bounds.Y+line.Bounds.Height)*sy
+			fmt.Fprintf(&text, "/%s %s Tf %s Tz 1 0 0 1 %s %s Tm <%s> Tj\n",
+				font, pdf.FormatNumber(size), pdf.FormatNumber(scale), pdf.FormatNumber(x), pdf.FormatNumber(y), textLayerCodes(runes))
+		}
+	}
+	if text.Len() == 0 {
+		return nil
+	}
+
+	var content bytes.Buffer
+	content.WriteString("Q\nq\n")
+	for _, v := range matrix {
+		content.WriteString(pdf.FormatNumber(v))
+		content.WriteByte(' ')
+	}
+	// Rendering mode 3 neither fills nor strokes the glyphs
+	content.WriteString("cm\nBT\n3 Tr\n")
+	content.Write(text.Bytes())
+	content.WriteString("ET\nQ\n")
+	return content.Bytes()
+}
+
+// textLayerCodes returns runes as hexadecimal two-byte character codes; the
+// code of a character is its UTF-16 code unit, and characters outside the
+// Basic Multilingual Plane are replaced
+func textLayerCodes(runes []rune) string {
+	var b bytes.Buffer
+	for _, r := range runes {
+		if r > 0xFFFF || utf16.IsSurrogate(r) {
+			r = unicode.ReplacementChar
+		}
+		fmt.Fprintf(&b, "%04X", r)
+	}
+	return b.String()
+}
+
+// addTextLayerFont adds the text layer font to update. It is a composite font
+// whose glyphs all have the same width, and its ToUnicode map makes the text
+// extractable. The embedded font program has no outlines, since the text is
+// never drawn, but lets readers and validators requiring embedded fonts, such
+// as those of PDF/A, load the font.
+func addTextLayerFont(update *pdf.Update) pdf.Ref {
+	program := textLayerFontProgram()
+	fontFile := update.Add(pdf.NewFlateStream(pdf.Dict{"Length1": len(program)}, program))
+	descriptor := update.Add(pdf.Dict{
+		"Type":        pdf.Name("FontDescriptor"),
+		"FontName":    pdf.Name(textLayerFont),
+		"Flags":       4,
+		"FontBBox":    pdf.Array{0, 0, textLayerGlyphWidth, 1000},
+		"ItalicAngle": 0,
+		"Ascent":      1000,
+		"Descent":     0,
+		"CapHeight":   1000,
+		"StemV":       80,
+		"FontFile2":   fontFile,
+	})
+	cidFont := update.Add(pdf.Dict{
+		"Type":           pdf.Name("Font"),
+		"Subtype":        pdf.Name("CIDFontType2"),
+		"BaseFont":       pdf.Name(textLayerFont),
+		"CIDSystemInfo":  pdf.Dict{"Registry": pdf.String("Adobe"), "Ordering": pdf.String("Identity"), "Supplement": 0},
+		"FontDescriptor": descriptor,
+		"DW":             textLayerGlyphWidth,
+		"CIDToGIDMap":    update.Add(pdf.NewFlateStream(pdf.Dict{}, textLayerCIDToGIDMap())),
+	})
+	toUnicode := update.Add(pdf.NewFlateStream(pdf.Dict{}, textLayerToUnicode()))
+	return update.Add(pdf.Dict{
+		"Type":            pdf.Name("Font"),
+		"Subtype":         pdf.Name("Type0"),
+		"BaseFont":        pdf.Name(textLayerFont),
+		"Encoding":        pdf.Name("Identity-H"),
+		"DescendantFonts": pdf.Array{cidFont},
+		"ToUnicode":       toUnicode,
+	})
+}
+
+// textLayerToUnicode returns a CMap mapping every two-byte code to the same
+// UTF-16 code unit. A range may only vary in its last byte, and a section may
+// hold at most 100 ranges.
+func textLayerToUnicode() []byte {
+	var b bytes.Buffer
+	b.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	for start := 0; start < 256; start += 100 {
+		n := min(100, 256-start)
+		fmt.Fprintf(&b, "%d beginbfrange\n", n)
+		for hi := start; hi < start+n; hi++ {
+			fmt.Fprintf(&b, "<%02X00> <%02XFF> <%02X00>\n", hi, hi, hi)
+		}
+		b.WriteString("endbfrange\n")
+	}
+	b.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return b.Bytes()
+}
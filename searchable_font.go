@@ -0,0 +1,137 @@
+package ocr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"unicode/utf16"
+)
+
+// textLayerUnitsPerEm is the em size of the text layer font program, chosen
+// so that glyph advances equal their PDF widths
+const textLayerUnitsPerEm = 1000
+
+// sfntTable is a table of a TrueType font program
+type sfntTable struct {
+	tag  string
+	data []byte
+}
+
+// textLayerFontProgram returns the TrueType program embedded with the text
+// layer font. It has two glyphs without outlines, .notdef and the glyph every
+// character is mapped to, both textLayerGlyphWidth wide.
+func textLayerFontProgram() []byte {
+	const numGlyphs = 2
+
+	// Tables are listed in tag order, as the table directory requires
+	tables := []sfntTable{
+		// A format 4 subtable holding only the mandatory final segment
+		{"cmap", sfntWords(0, 1, 3, 1, 0, 12, 4, 24, 0, 2, 2, 0, 0, 0xFFFF, 0, 0xFFFF, 1, 0)},
+		// Glyphs without outlines have no data
+		{"glyf", nil},
+		{"head", sfntWords(
+			1, 0, // version
+			1, 0, // font revision
+			0, 0, // checksum adjustment, set below
+			0x5F0F, 0x3CF5, // magic number
+			0x000B, // flags: baseline and left sidebearing at 0, integer scaling
+			textLayerUnitsPerEm,
+			0, 0, 0, 0, // created
+			0, 0, 0, 0, // modified
+			0, 0, textLayerGlyphWidth, textLayerUnitsPerEm, // bounding box
+			0, // mac style
+			3, // lowest readable size
+			2, // font direction hint
+			0, // short loca offsets
+			0, // glyph data format
+		)},
+		{"hhea", sfntWords(
+			1, 0, // version
+			textLayerUnitsPerEm, 0, 0, // ascender, descender, line gap
+			textLayerGlyphWidth,       // maximum advance
+			0, 0, textLayerGlyphWidth, // minimum sidebearings, maximum extent
+			1, 0, 0, // caret slope rise and run, caret offset
+			0, 0, 0, 0, 0, // reserved, metric data format
+			1, // number of advances, the last one repeating for later glyphs
+		)},
+		// The advance and left sidebearing of .notdef, then the left
+		// sidebearing of the other glyph
+		{"hmtx", sfntWords(textLayerGlyphWidth, 0, 0)},
+		{"loca", sfntWords(0, 0, 0)},
+		{"maxp", sfntWords(1, 0, numGlyphs, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0)},
+		{"name", sfntNames(textLayerFont, "Regular", textLayerFont, textLayerFont)},
+		{"post", sfntWords(
+			3, 0, // version 3, without glyph names
+			0, 0, // italic angle
+			0xFF9C, 50, // underline position and thickness
+			0, 1, // fixed pitch
+			0, 0, 0, 0, 0, 0, 0, 0, // memory usage
+		)},
+	}
+
+	numTables := len(tables)
+	entrySelector := 0
+	for 2<<entrySelector <= numTables {
+		entrySelector++
+	}
+	searchRange := (1 << entrySelector) * 16
+
+	var font bytes.Buffer
+	font.Write(sfntWords(1, 0, uint16(numTables), uint16(searchRange), uint16(entrySelector), uint16(numTables*16-searchRange))) // #nosec G115 - small constants
+	offset := font.Len() + numTables*16
+	var headOffset int
+	for _, table := range tables {
+		if table.tag == "head" {
+			headOffset = offset
+		}
+		font.WriteString(table.tag)
+		_ = binary.Write(&font, binary.BigEndian, []uint32{sfntChecksum(table.data), uint32(offset), uint32(len(table.data))}) //nolint:errcheck // #nosec G115 - small sizes
+		offset += (len(table.data) + 3) &^ 3
+	}
+	for _, table := range tables {
+		font.Write(table.data)
+		font.Write(make([]byte, (4-len(table.data)%4)%4))
+	}
+
+	data := font.Bytes()
+	binary.BigEndian.PutUint32(data[headOffset+8:], 0xB1B0AFBA-sfntChecksum(data))
+	return data
+}
+
+// textLayerCIDToGIDMap maps every CID of the text layer font to its glyph 1
+func textLayerCIDToGIDMap() []byte {
+	return bytes.Repeat([]byte{0, 1}, 1<<16)
+}
+
+// sfntWords encodes big-endian 16-bit values
+func sfntWords(words ...uint16) []byte {
+	data := make([]byte, 2*len(words))
+	for i, w := range words {
+		binary.BigEndian.PutUint16(data[2*i:], w)
+	}
+	return data
+}
+
+// sfntNames returns a name table with the family, subfamily, full and
+// PostScript names in UTF-16 for Windows
+func sfntNames(names ...string) []byte {
+	ids := []uint16{1, 2, 4, 6}
+	var strs []byte
+	records := sfntWords(0, uint16(len(names)), uint16(6+12*len(names))) // #nosec G115 - four names
+	for i, name := range names {
+		str := sfntWords(utf16.Encode([]rune(name))...)
+		records = append(records, sfntWords(3, 1, 0x0409, ids[i], uint16(len(str)), uint16(len(strs)))...) // #nosec G115 - short names
+		strs = append(strs, str...)
+	}
+	return append(records, strs...)
+}
+
+// sfntChecksum sums data as big-endian 32-bit values, padded with zeros
+func sfntChecksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		var word [4]byte
+		copy(word[:], data[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
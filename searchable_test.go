@@ -0,0 +1,209 @@
+package ocr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/leapocr/leapocr-go/internal/pdf"
+)
+
+// scannedPDF returns a two page PDF with a classic cross-reference table; the
+// second page is rotated and both share resources that already name a font
+// LeapOCRText
+func scannedPDF() []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /MediaBox [0 0 500 700] /Resources 5 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 6 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /Contents [6 0 R] /MediaBox [0 0 700 500] /Rotate 90 >>",
+		"<< /Font << /F1 7 0 R /LeapOCRText 7 0 R >> >>",
+		"<< /Length 24 >>\nstream\n2 0 0 2 0 0 cm 0 0 1 rg\nendstream",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	start := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f\r\n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n\r\n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, start)
+	return buf.Bytes()
+}
+
+func TestWriteSearchablePDF(t *testing.T) {
+	original := scannedPDF()
+	result := &OCRResult{Pages: []PageResult{
+		{PageNumber: 1, Width: 1000, Height: 1400, Blocks: []Block{
+			{Type: "paragraph", Text: "Hello world\n\nsecond line", Bounds: BoundingBox{X: 100, Y: 100, Width: 500, Height: 100}},
+			{Type: "figure", Bounds: BoundingBox{X: 100, Y: 300, Width: 200, Height: 200}},
+		}},
+		{PageNumber: 2, Width: 500, Height: 700, Blocks: []Block{
+			{Type: "heading", Text: "Größe 😀", Bounds: BoundingBox{X: 0, Y: 0, Width: 350, Height: 50}},
+		}},
+	}}
+
+	var out bytes.Buffer
+	if err := WriteSearchablePDF(bytes.NewReader(original), result, &out); err != nil {
+		t.Fatalf("WriteSearchablePDF failed: %v", err)
+	}
+	if !bytes.HasPrefix(out.Bytes(), original) {
+		t.Fatal("the original PDF is not preserved")
+	}
+	doc, err := pdf.Open(out.Bytes())
+	if err != nil {
+		t.Fatalf("output is not a readable PDF: %v", err)
+	}
+	if prev, _ := pdf.Int(doc.Trailer["Prev"]); prev != bytes.Index(original, []byte("\nxref"))+1 {
+		t.Errorf("unexpected trailer: %v", doc.Trailer)
+	}
+	pages, err := doc.Pages()
+	if err != nil || len(pages) != 2 {
+		t.Fatalf("unexpected pages: %+v, %v", pages, err)
+	}
+
+	// layer returns the decoded text layer of a page and checks its resources
+	layer := func(page pdf.Page) string {
+		t.Helper()
+		contents, _ := page.Dict["Contents"].(pdf.Array)
+		if len(contents) != 3 || contents[1] != (pdf.Ref{Num: 6}) {
+			t.Fatalf("expected the original content between the text layer streams, got %v", contents)
+		}
+		fonts, _ := page.Dict["Resources"].(pdf.Dict)["Font"].(pdf.Dict)
+		if fonts["F1"] != (pdf.Ref{Num: 7}) || fonts["LeapOCRText"] != (pdf.Ref{Num: 7}) || fonts["LeapOCRText1"] == nil {
+			t.Errorf("unexpected fonts: %v", fonts)
+		}
+		var streams []string
+		for _, ref := range []any{contents[0], contents[2]} {
+			obj, err := doc.Resolve(ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := doc.Decode(obj.(*pdf.Stream))
+			if err != nil {
+				t.Fatal(err)
+			}
+			streams = append(streams, string(data))
+		}
+		if streams[0] != "q\n" || !strings.HasPrefix(streams[1], "Q\nq\n") || !strings.Contains(streams[1], "BT\n3 Tr\n") {
+			t.Errorf("the text layer is not isolated and invisible: %q", streams)
+		}
+		return streams[1]
+	}
+
+	// Pixels map to half points; the glyphs of each line stretch over its width
+	first := layer(pages[0])
+	for _, line := range []string{
+		"1 0 0 1 0 0 cm\n",
+		"/LeapOCRText1 25 Tf 181.8182 Tz 1 0 0 1 50 625 Tm <00480065006C006C006F00200077006F0072006C0064> Tj\n",
+		"/LeapOCRText1 25 Tf 181.8182 Tz 1 0 0 1 50 600 Tm <007300650063006F006E00640020006C0069006E0065> Tj\n",
+	} {
+		if !strings.Contains(first, line) {
+			t.Errorf("page 1 layer lacks %q:\n%s", line, first)
+		}
+	}
+	if strings.Count(first, "Tj") != 2 {
+		t.Errorf("expected two lines on page 1:\n%s", first)
+	}
+
+	// The rotated page is laid out as displayed, and characters outside the
+	// Basic Multilingual Plane are replaced
+	second := layer(pages[1])
+	for _, line := range []string{
+		"0 1 -1 0 700 0 cm\n",
+		"/LeapOCRText1 50 Tf 200 Tz 1 0 0 1 0 650 Tm <0047007200F600DF00650020FFFD> Tj\n",
+	} {
+		if !strings.Contains(second, line) {
+			t.Errorf("page 2 layer lacks %q:\n%s", line, second)
+		}
+	}
+
+	font, err := doc.Resolve(pages[0].Dict["Resources"].(pdf.Dict)["Font"].(pdf.Dict)["LeapOCRText1"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, _ := font.(pdf.Dict); f["Subtype"] != pdf.Name("Type0") || f["Encoding"] != pdf.Name("Identity-H") || f["ToUnicode"] == nil {
+		t.Errorf("unexpected text layer font: %v", font)
+	}
+
+	// The font program is embedded, with glyph widths matching the font's
+	resolve := func(v any) any {
+		t.Helper()
+		obj, err := doc.Resolve(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return obj
+	}
+	cidFont := resolve(resolve(font.(pdf.Dict)["DescendantFonts"]).(pdf.Array)[0]).(pdf.Dict)
+	fontFile, ok := resolve(resolve(cidFont["FontDescriptor"]).(pdf.Dict)["FontFile2"]).(*pdf.Stream)
+	if !ok {
+		t.Fatalf("no embedded font program: %v", cidFont)
+	}
+	program, err := doc.Decode(fontFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if length, _ := pdf.Int(fontFile.Dict["Length1"]); length != len(program) {
+		t.Errorf("Length1 = %d, want %d", length, len(program))
+	}
+	if sum := sfntChecksum(program); sum != 0xB1B0AFBA {
+		t.Errorf("font program checksum = %#x", sum)
+	}
+	tables := make(map[string][]byte)
+	for i := range int(binary.BigEndian.Uint16(program[4:])) {
+		record := program[12+16*i:]
+		offset, length := binary.BigEndian.Uint32(record[8:]), binary.BigEndian.Uint32(record[12:])
+		tables[string(record[:4])] = program[offset : offset+length]
+	}
+	for _, tag := range []string{"glyf", "head", "hhea", "hmtx", "loca", "maxp"} {
+		if _, ok := tables[tag]; !ok {
+			t.Errorf("font program lacks the %s table", tag)
+		}
+	}
+	if advance, _ := pdf.Int(cidFont["DW"]); int(binary.BigEndian.Uint16(tables["hmtx"])) != advance {
+		t.Errorf("glyph advance %d differs from width %d", binary.BigEndian.Uint16(tables["hmtx"]), advance)
+	}
+	gids, err := doc.Decode(resolve(cidFont["CIDToGIDMap"]).(*pdf.Stream))
+	if err != nil || len(gids) != 1<<17 || binary.BigEndian.Uint16(gids[2*'A':]) != 1 {
+		t.Errorf("unexpected CIDToGIDMap of %d bytes: %v", len(gids), err)
+	}
+
+	// Pages missing from the result are left as they are
+	out.Reset()
+	result.Pages = result.Pages[1:]
+	if err := WriteSearchablePDF(bytes.NewReader(original), result, &out); err != nil {
+		t.Fatalf("WriteSearchablePDF failed: %v", err)
+	}
+	if doc, err = pdf.Open(out.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	if pages, err = doc.Pages(); err != nil || pages[0].Dict["Contents"] != (pdf.Ref{Num: 6}) {
+		t.Errorf("page 1 changed: %v, %v", pages, err)
+	}
+
+	for name, tt := range map[string]struct {
+		original io.ReadSeeker
+		page     PageResult
+	}{
+		"not a PDF":      {strings.NewReader("<html></html>"), result.Pages[0]},
+		"missing page":   {bytes.NewReader(original), PageResult{PageNumber: 3, Width: 10, Height: 10}},
+		"no dimensions":  {bytes.NewReader(original), PageResult{PageNumber: 1}},
+		"no original":    {nil, result.Pages[0]},
+		"encrypted file": {bytes.NewReader(bytes.Replace(original, []byte("/Root"), []byte("/Encrypt 9 0 R /Root"), 1)), result.Pages[0]},
+	} {
+		result := &OCRResult{Pages: []PageResult{tt.page}}
+		if err := WriteSearchablePDF(tt.original, result, io.Discard); errorTypeOf(err) != ErrorTypeValidationError {
+			t.Errorf("%s: expected validation error, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package ocr
+
+import (
+	"context"
+	"iter"
+	"time"
+
+	"github.com/leapocr/leapocr-go/internal/generated"
+)
+
+// Template is a saved processing configuration of a team, used with WithTemplateSlug
+type Template struct {
+	ID                   string         `json:"id"`
+	Slug                 string         `json:"slug"`
+	Name                 string         `json:"name"`
+	Description          string         `json:"description,omitempty"`
+	Format               Format         `json:"format"`
+	Model                string         `json:"model,omitempty"`
+	Instructions         string         `json:"instructions,omitempty"`
+	Schema               map[string]any `json:"schema,omitempty"`
+	Tags                 []string       `json:"tags,omitempty"`
+	Enabled              bool           `json:"enabled"`
+	Favorite             bool           `json:"favorite"`
+	ExtractBoundingBoxes bool           `json:"extract_bounding_boxes"`
+	UsageCount           int            `json:"usage_count"`
+	CreatedAt            time.Time      `json:"created_at"`
+	UpdatedAt            time.Time      `json:"updated_at"`
+	LastUsed             time.Time      `json:"last_used"`
+}
+
+// TemplateParams describes a template to create or update. Updates replace
+// every field, so empty fields clear the template's current values; the model
+// can only be set on create.
+type TemplateParams struct {
+	// Name is the display name of the template (required)
+	Name        string
+	Description string
+	// Format is the output format (required)
+	Format Format
+	// Model is the OCR model (default: ModelStandardV2)
+	Model        string
+	Instructions string
+	// Schema is the JSON schema of the extracted data for structured output
+	Schema map[string]any
+	Tags   []string
+	// Enabled and ExtractBoundingBoxes keep the server default (create) or current value (update) when nil
+	Enabled              *bool
+	ExtractBoundingBoxes *bool
+}
+
+// TemplateListOptions configures ListTemplates
+type TemplateListOptions struct {
+	Cursor string
+	Limit  int
+	// Format filters templates by output format when set
+	Format Format
+	// Favorite filters templates by favorite state when set
+	Favorite *bool
+	// Search filters templates by name
+	Search string
+}
+
+// TemplateList is a page of templates
+type TemplateList struct {
+	Templates  []Template
+	NextCursor string
+	HasMore    bool
+}
+
+// ListTemplates returns a page of the team's templates
+func (s *SDK) ListTemplates(ctx context.Context, opts TemplateListOptions) (*TemplateList, error) {
+	orgID, teamID, err := s.teamScope()
+	if err != nil {
+		return nil, err
+	}
+
+	apiRequest := s.client.TemplatesAPI.ListTemplatesCursor(ctx, orgID, teamID)
+	if opts.Cursor != "" {
+		apiRequest = apiRequest.Cursor(opts.Cursor)
+	}
+	if opts.Limit > 0 {
+		apiRequest = apiRequest.Limit(int32(min(opts.Limit, maxPageLimit))) // #nosec G115 - bounded above
+	}
+	if opts.Format != "" {
+		apiRequest = apiRequest.Format(string(opts.Format))
+	}
+	if opts.Favorite != nil {
+		apiRequest = apiRequest.Favorite(*opts.Favorite)
+	}
+	if opts.Search != "" {
+		apiRequest = apiRequest.Search(opts.Search)
+	}
+
+	resp, httpResp, err := apiRequest.Execute()
+	if err != nil {
+		return nil, s.handleAPIError(ctx, err, httpResp, "failed to list templates")
+	}
+
+	list := &TemplateList{
+		Templates: make([]Template, 0, len(resp.Data)),
+	}
+	for i := range resp.Data {
+		list.Templates = append(list.Templates, *convertTemplate(&resp.Data[i]))
+	}
+	if resp.NextCursor != nil {
+		list.NextCursor = *resp.NextCursor
+	}
+	if resp.HasMore != nil {
+		list.HasMore = *resp.HasMore
+	}
+
+	return list, nil
+}
+
+// Templates iterates over every template matching opts, fetching pages as needed.
+// Iteration stops after the first error.
+func (s *SDK) Templates(ctx context.Context, opts TemplateListOptions) iter.Seq2[Template, error] {
+	return func(yield func(Template, error) bool) {
+		for {
+			page, err := s.ListTemplates(ctx, opts)
+			if err != nil {
+				yield(Template{}, err)
+				return
+			}
+
+			for _, template := range page.Templates {
+				if !yield(template, nil) {
+					return
+				}
+			}
+
+			if !page.HasMore || page.NextCursor == "" {
+				return
+			}
+			opts.Cursor = page.NextCursor
+		}
+	}
+}
+
+// GetTemplate returns a template by ID
+func (s *SDK) GetTemplate(ctx context.Context, templateID string) (*Template, error) {
+	orgID, teamID, err := s.teamScope()
+	if err != nil {
+		return nil, err
+	}
+	if templateID == "" {
+		return nil, NewSDKError(ErrorTypeValidationError, "template ID is required", nil)
+	}
+
+	resp, httpResp, err := s.client.TemplatesAPI.GetTemplate(ctx, orgID, teamID, templateID).Execute()
+	if err != nil {
+		return nil, s.handleAPIError(ctx, err, httpResp, "failed to get template")
+	}
+
+	return convertTemplate(resp), nil
+}
+
+// CreateTemplate creates a template
+func (s *SDK) CreateTemplate(ctx context.Context, params TemplateParams) (*Template, error) {
+	orgID, teamID, err := s.teamScope()
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateTemplateParams(params); err != nil {
+		return nil, NewSDKError(ErrorTypeValidationError, "invalid template parameters", err)
+	}
+
+	model := params.Model
+	if model == "" {
+		model = string(ModelStandardV2)
+	}
+	payload := generated.TemplatesCreateTemplateRequest{
+		Name:                 params.Name,
+		Format:               generated.SqlcResultFormatEnum(params.Format),
+		Model:                model,
+		Schema:               params.Schema,
+		Tags:                 params.Tags,
+		Enabled:              params.Enabled,
+		ExtractBoundingBoxes: params.ExtractBoundingBoxes,
+	}
+	if params.Description != "" {
+		payload.Description = &params.Description
+	}
+	if params.Instructions != "" {
+		payload.Instructions = &params.Instructions
+	}
+
+	resp, httpResp, err := s.client.TemplatesAPI.CreateTemplate(ctx, orgID, teamID).
+		CreateTemplateRequest(generated.TemplatesCreateTemplateRequestAsCreateTemplateRequest(&payload)).
+		Execute()
+	if err != nil {
+		return nil, s.handleAPIError(ctx, err, httpResp, "failed to create template")
+	}
+
+	return convertTemplate(resp), nil
+}
+
+// UpdateTemplate replaces the settings of a template
+func (s *SDK) UpdateTemplate(ctx context.Context, templateID string, params TemplateParams) (*Template, error) {
+	orgID, teamID, err := s.teamScope()
+	if err != nil {
+		return nil, err
+	}
+	if templateID == "" {
+		return nil, NewSDKError(ErrorTypeValidationError, "template ID is required", nil)
+	}
+	if err := ValidateTemplateParams(params); err != nil {
+		return nil, NewSDKError(ErrorTypeValidationError, "invalid template parameters", err)
+	}
+
+	// Empty values are sent explicitly, since omitted fields keep their current value
+	payload := generated.TemplatesUpdateTemplateRequest{
+		Name:                 params.Name,
+		Description:          &params.Description,
+		Format:               generated.SqlcResultFormatEnum(params.Format),
+		Instructions:         &params.Instructions,
+		Schema:               params.Schema,
+		Tags:                 params.Tags,
+		Enabled:              params.Enabled,
+		ExtractBoundingBoxes: params.ExtractBoundingBoxes,
+	}
+	if payload.Schema == nil {
+		payload.Schema = map[string]any{}
+	}
+	if payload.Tags == nil {
+		payload.Tags = []string{}
+	}
+
+	resp, httpResp, err := s.client.TemplatesAPI.UpdateTemplate(ctx, orgID, teamID, templateID).
+		UpdateTemplateRequest(generated.TemplatesUpdateTemplateRequestAsUpdateTemplateRequest(&payload)).
+		Execute()
+	if err != nil {
+		return nil, s.handleAPIError(ctx, err, httpResp, "failed to update template")
+	}
+
+	return convertTemplate(resp), nil
+}
+
+// DeleteTemplate deletes a template; jobs referencing its slug fail afterwards
+func (s *SDK) DeleteTemplate(ctx context.Context, templateID string) error {
+	orgID, teamID, err := s.teamScope()
+	if err != nil {
+		return err
+	}
+	if templateID == "" {
+		return NewSDKError(ErrorTypeValidationError, "template ID is required", nil)
+	}
+
+	httpResp, err := s.client.TemplatesAPI.DeleteTemplate(ctx, orgID, teamID, templateID).Execute()
+	if err != nil {
+		return s.handleAPIError(ctx, err, httpResp, "failed to delete template")
+	}
+
+	return nil
+}
+
+// ValidateTemplateParams checks template parameters like CreateTemplate and UpdateTemplate do
+func ValidateTemplateParams(params TemplateParams) error {
+	if params.Name == "" {
+		return NewValidationError("name", "template name cannot be empty")
+	}
+	if err := ValidateFormat(params.Format); err != nil {
+		return err
+	}
+	if err := ValidateModel(params.Model); err != nil {
+		return err
+	}
+	if err := ValidateSchema(params.Schema, params.Format); err != nil {
+		return err
+	}
+	return ValidateInstructions(params.Instructions)
+}
+
+func convertTemplate(resp *generated.TemplatesTemplateResponse) *Template {
+	return &Template{
+		ID:                   resp.GetId(),
+		Slug:                 resp.GetSlug(),
+		Name:                 resp.GetName(),
+		Description:          resp.GetDescription(),
+		Format:               Format(resp.GetFormat()),
+		Model:                resp.GetModel(),
+		Instructions:         resp.GetInstructions(),
+		Schema:               resp.Schema,
+		Tags:                 resp.Tags,
+		Enabled:              resp.GetEnabled(),
+		Favorite:             resp.GetFavorite(),
+		ExtractBoundingBoxes: resp.GetExtractBoundingBoxes(),
+		UsageCount:           int(resp.GetUsageCount()),
+		CreatedAt:            resp.GetCreatedAt(),
+		UpdatedAt:            resp.GetUpdatedAt(),
+		LastUsed:             resp.GetLastUsed(),
+	}
+}
@@ -0,0 +1,64 @@
+package ocr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestTemplates(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /organizations/org_1/teams/team_1/templates", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("cursor") == "" {
+			fmt.Fprint(w, `{"data":[{"id":"tpl_1","slug":"invoices","name":"Invoices","format":"structured"}],"has_more":true,"next_cursor":"c2"}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":[{"id":"tpl_2","slug":"receipts","name":"Receipts","format":"markdown"}],"has_more":false}`)
+	})
+	mux.HandleFunc("POST /organizations/org_1/teams/team_1/templates", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		body["id"] = "tpl_3"
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(body) //nolint:errcheck
+	})
+
+	sdk := newTestTeamSDK(t, mux)
+	ctx := context.Background()
+
+	var slugs []string
+	for template, err := range sdk.Templates(ctx, TemplateListOptions{}) {
+		if err != nil {
+			t.Fatalf("Templates failed: %v", err)
+		}
+		slugs = append(slugs, template.Slug)
+	}
+	if len(slugs) != 2 || slugs[0] != "invoices" || slugs[1] != "receipts" {
+		t.Errorf("expected both pages, got %v", slugs)
+	}
+
+	created, err := sdk.CreateTemplate(ctx, TemplateParams{Name: "Notes", Format: FormatMarkdown, Tags: []string{"misc"}})
+	if err != nil {
+		t.Fatalf("CreateTemplate failed: %v", err)
+	}
+	if created.ID != "tpl_3" || created.Model != string(ModelStandardV2) || len(created.Tags) != 1 {
+		t.Errorf("unexpected template: %+v", created)
+	}
+
+	invalid := []TemplateParams{
+		{Format: FormatMarkdown},
+		{Name: "No format"},
+		{Name: "No schema", Format: FormatStructured},
+	}
+	for _, params := range invalid {
+		if _, err := sdk.CreateTemplate(ctx, params); errorTypeOf(err) != ErrorTypeValidationError {
+			t.Errorf("expected validation error for %+v, got %v", params, err)
+		}
+	}
+}
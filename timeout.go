@@ -0,0 +1,111 @@
+package ocr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// TimeoutPhase names the part of an operation that exceeded its time limit
+type TimeoutPhase string
+
+const (
+	// TimeoutPhaseRequest is a single API request, limited by Config.Timeout
+	TimeoutPhaseRequest TimeoutPhase = "api request"
+	// TimeoutPhaseUploadPart is the upload of one file part, limited by Config.UploadPartTimeout
+	TimeoutPhaseUploadPart TimeoutPhase = "part upload"
+	// TimeoutPhaseProcessFile is a whole ProcessFile call, limited by Config.OperationTimeout
+	TimeoutPhaseProcessFile TimeoutPhase = "process file"
+	// TimeoutPhaseWait is a whole WaitUntilDone call, limited by Config.OperationTimeout
+	TimeoutPhaseWait TimeoutPhase = "wait until done"
+)
+
+// TimeoutError reports which phase exceeded a configured timeout. It is the
+// cause of SDKErrors of type ErrorTypeTimeout raised by the SDK's own limits;
+// deadlines of the caller's context are reported without it.
+type TimeoutError struct {
+	Phase   TimeoutPhase
+	Timeout time.Duration
+}
+
+// Error implements error
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s timed out after %s", e.Phase, e.Timeout)
+}
+
+// Unwrap makes errors.Is(err, context.DeadlineExceeded) hold for timeouts
+func (e *TimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// withTimeout returns a context expiring after timeout with a TimeoutError for
+// phase as its cause; a zero timeout leaves ctx without a deadline
+func withTimeout(ctx context.Context, phase TimeoutPhase, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeoutCause(ctx, timeout, &TimeoutError{Phase: phase, Timeout: timeout})
+}
+
+// timeoutCause returns the TimeoutError that ended ctx, or nil if ctx is live
+// or ended for another reason
+func timeoutCause(ctx context.Context) *TimeoutError {
+	if ctx.Err() == nil {
+		return nil
+	}
+	var timeoutErr *TimeoutError
+	if errors.As(context.Cause(ctx), &timeoutErr) {
+		return timeoutErr
+	}
+	return nil
+}
+
+// phaseTimeout replaces err with a timeout error naming the phase when ctx
+// ended because of one of the SDK's timeouts
+func phaseTimeout(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if timeoutErr := timeoutCause(ctx); timeoutErr != nil {
+		return NewSDKError(ErrorTypeTimeout, timeoutErr.Error(), timeoutErr)
+	}
+	return err
+}
+
+// timeoutTransport limits every request of the wrapped transport to a fixed duration
+type timeoutTransport struct {
+	base    http.RoundTripper
+	timeout time.Duration
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := withTimeout(req.Context(), TimeoutPhaseRequest, t.timeout)
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		if timeoutErr := timeoutCause(ctx); timeoutErr != nil && req.Context().Err() == nil {
+			return nil, timeoutErr
+		}
+		return nil, err
+	}
+
+	// The deadline covers reading the body, so it is released on Close
+	resp.Body = &cancelingBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelingBody cancels a request's context when its response body is closed
+type cancelingBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close implements io.Closer
+func (b *cancelingBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
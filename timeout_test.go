@@ -0,0 +1,119 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// slowHandler delays requests matching pattern until the client gives up
+func slowHandler(pattern string, next http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/", next)
+	mux.HandleFunc(pattern, func(_ http.ResponseWriter, r *http.Request) {
+		// Disconnects are only noticed once the body has been consumed
+		_, _ = io.Copy(io.Discard, r.Body) //nolint:errcheck
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+	return mux
+}
+
+func TestTimeouts(t *testing.T) {
+	var uploads atomic.Int32
+	processing := http.NewServeMux()
+	processing.HandleFunc("GET /ocr/status/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"job_id":%q,"status":"processing"}`, r.PathValue("id"))
+	})
+
+	tests := []struct {
+		name      string
+		handler   http.Handler
+		configure func(*Config)
+		run       func(context.Context, *SDK) error
+		wantPhase TimeoutPhase
+	}{
+		{
+			name:      "api request",
+			handler:   slowHandler("GET /ocr/status/{id}", newFileProcessingMux(&uploads)),
+			configure: func(c *Config) { c.Timeout = 20 * time.Millisecond },
+			run: func(ctx context.Context, sdk *SDK) error {
+				_, err := sdk.GetJobStatus(ctx, "job_1")
+				return err
+			},
+			wantPhase: TimeoutPhaseRequest,
+		},
+		{
+			name:      "part upload",
+			handler:   slowHandler("PUT /upload", newFileProcessingMux(&uploads)),
+			configure: func(c *Config) { c.UploadPartTimeout = 20 * time.Millisecond },
+			run: func(ctx context.Context, sdk *SDK) error {
+				_, err := sdk.ProcessFile(ctx, bytes.NewReader([]byte("%PDF")), "doc.pdf", WithFormat(FormatMarkdown))
+				return err
+			},
+			wantPhase: TimeoutPhaseUploadPart,
+		},
+		{
+			name:      "process file",
+			handler:   slowHandler("POST /ocr/uploads/{id}/complete", newFileProcessingMux(&uploads)),
+			configure: func(c *Config) { c.OperationTimeout = 50 * time.Millisecond },
+			run: func(ctx context.Context, sdk *SDK) error {
+				_, err := sdk.ProcessFile(ctx, bytes.NewReader([]byte("%PDF")), "doc.pdf", WithFormat(FormatMarkdown))
+				return err
+			},
+			wantPhase: TimeoutPhaseProcessFile,
+		},
+		{
+			name:      "wait until done",
+			handler:   processing,
+			configure: func(c *Config) { c.OperationTimeout = 50 * time.Millisecond },
+			run: func(ctx context.Context, sdk *SDK) error {
+				_, err := sdk.WaitUntilDoneWithOptions(ctx, "job_1", WaitOptions{InitialDelay: 5 * time.Millisecond, MaxJitter: time.Millisecond})
+				return err
+			},
+			wantPhase: TimeoutPhaseWait,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sdk := newTestTeamSDKWithConfig(t, tt.handler, tt.configure)
+
+			err := tt.run(context.Background(), sdk)
+			var sdkErr *SDKError
+			var timeoutErr *TimeoutError
+			if !errors.As(err, &sdkErr) || sdkErr.Type != ErrorTypeTimeout {
+				t.Fatalf("expected timeout error, got %v", err)
+			}
+			if !errors.As(err, &timeoutErr) || timeoutErr.Phase != tt.wantPhase {
+				t.Errorf("expected %q phase, got %v", tt.wantPhase, err)
+			}
+		})
+	}
+}
+
+func TestTimeoutCallerDeadline(t *testing.T) {
+	var uploads atomic.Int32
+	sdk := newTestTeamSDKWithConfig(t, slowHandler("GET /ocr/status/{id}", newFileProcessingMux(&uploads)), nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := sdk.GetJobStatus(ctx, "job_1")
+
+	var sdkErr *SDKError
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &sdkErr) || sdkErr.Type != ErrorTypeTimeout {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+	if errors.As(err, &timeoutErr) {
+		t.Errorf("caller deadlines must not be reported as SDK timeouts, got %v", err)
+	}
+}
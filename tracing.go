@@ -0,0 +1,83 @@
+package ocr
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// tracerName is the instrumentation scope of the SDK's spans
+const tracerName = "github.com/leapocr/leapocr-go"
+
+// Span attribute keys
+const (
+	attrJobID     = attribute.Key("leapocr.job_id")
+	attrModel     = attribute.Key("leapocr.model")
+	attrFormat    = attribute.Key("leapocr.format")
+	attrFileName  = attribute.Key("leapocr.file.name")
+	attrFileSize  = attribute.Key("leapocr.file.size")
+	attrPartNum   = attribute.Key("leapocr.upload.part_number")
+	attrPartSize  = attribute.Key("leapocr.upload.part_size")
+	attrStatus    = attribute.Key("leapocr.job.status")
+	attrProgress  = attribute.Key("leapocr.job.progress")
+	attrAttempt   = attribute.Key("leapocr.poll.attempt")
+	attrPageCount = attribute.Key("leapocr.page_count")
+	attrCredits   = attribute.Key("leapocr.credits")
+	attrHTTPCode  = attribute.Key("http.response.status_code")
+)
+
+// newTracer returns the SDK's tracer; without a provider spans are not recorded
+func newTracer(provider trace.TracerProvider) trace.Tracer {
+	if provider == nil {
+		provider = noop.NewTracerProvider()
+	}
+	return provider.Tracer(tracerName, trace.WithInstrumentationVersion(Version))
+}
+
+// startSpan starts a client span as a child of the span in ctx
+func (s *SDK) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	tracer := s.tracer
+	if tracer == nil {
+		tracer = newTracer(nil)
+	}
+	return tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// endSpan records err, if any, and ends the span
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// resultAttributes describes a job result on a span
+func resultAttributes(result *OCRResult) []attribute.KeyValue {
+	if result == nil {
+		return nil
+	}
+	return []attribute.KeyValue{
+		attrJobID.String(result.JobID),
+		attrPageCount.Int(len(result.Pages)),
+		attrCredits.Int(result.Credits),
+	}
+}
+
+// propagatingTransport injects the trace context of each request's context into its headers
+type propagatingTransport struct {
+	base       http.RoundTripper
+	propagator propagation.TextMapPropagator
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *propagatingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	t.propagator.Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+	return t.base.RoundTrip(req)
+}
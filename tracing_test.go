@@ -0,0 +1,145 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// spanRecorder is a minimal TracerProvider recording ended spans, which keeps
+// the OpenTelemetry SDK out of the module's requirements
+type spanRecorder struct {
+	embedded.TracerProvider
+
+	mu     sync.Mutex
+	nextID uint64
+	ended  []*recordedSpan
+}
+
+func (r *spanRecorder) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return recordingTracer{recorder: r}
+}
+
+type recordingTracer struct {
+	embedded.Tracer
+	recorder *spanRecorder
+}
+
+func (t recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	parent := trace.SpanContextFromContext(ctx)
+	t.recorder.mu.Lock()
+	t.recorder.nextID++
+	var spanID trace.SpanID
+	binary.BigEndian.PutUint64(spanID[:], t.recorder.nextID)
+	t.recorder.mu.Unlock()
+
+	traceID := parent.TraceID()
+	if !traceID.IsValid() {
+		traceID = trace.TraceID{0: 1}
+	}
+	config := trace.NewSpanStartConfig(opts...)
+	span := &recordedSpan{
+		recorder: t.recorder,
+		name:     name,
+		parent:   parent.SpanID(),
+		context:  trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled}),
+		attrs:    config.Attributes(),
+	}
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+type recordedSpan struct {
+	noop.Span
+	recorder *spanRecorder
+	name     string
+	parent   trace.SpanID
+	context  trace.SpanContext
+	attrs    []attribute.KeyValue
+}
+
+func (s *recordedSpan) SpanContext() trace.SpanContext { return s.context }
+
+func (s *recordedSpan) IsRecording() bool { return true }
+
+func (s *recordedSpan) SetAttributes(kv ...attribute.KeyValue) {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+	s.attrs = append(s.attrs, kv...)
+}
+
+func (s *recordedSpan) End(...trace.SpanEndOption) {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+	s.recorder.ended = append(s.recorder.ended, s)
+}
+
+func TestTracing(t *testing.T) {
+	provider := &spanRecorder{}
+
+	var uploads atomic.Int32
+	var mu sync.Mutex
+	traceparents := make(map[string]string)
+	files := newFileProcessingMux(&uploads)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		traceparents[r.Method+" "+r.URL.Path] = r.Header.Get("Traceparent")
+		mu.Unlock()
+		files.ServeHTTP(w, r)
+	})
+
+	sdk := newTestTeamSDKWithConfig(t, handler, func(c *Config) {
+		c.TracerProvider = provider
+	})
+
+	ctx := context.Background()
+	if _, err := sdk.ProcessFileAndWait(ctx, bytes.NewReader([]byte("%PDF")), "doc.pdf", WithFormat(FormatMarkdown)); err != nil {
+		t.Fatalf("ProcessFileAndWait failed: %v", err)
+	}
+
+	spans := make(map[string]*recordedSpan)
+	for _, span := range provider.ended {
+		spans[span.name] = span
+	}
+
+	parents := map[string]string{
+		"leapocr.upload.initiate": "leapocr.ProcessFile",
+		"leapocr.upload.part":     "leapocr.ProcessFile",
+		"leapocr.upload.complete": "leapocr.ProcessFile",
+		"leapocr.poll":            "leapocr.WaitUntilDone",
+		"leapocr.GetJobResult":    "leapocr.poll",
+	}
+	for child, parent := range parents {
+		c, ok := spans[child]
+		if !ok {
+			t.Errorf("missing span %s", child)
+			continue
+		}
+		if p, ok := spans[parent]; !ok || c.parent != p.context.SpanID() {
+			t.Errorf("expected %s to be a child of %s", child, parent)
+		}
+	}
+
+	attrs := make(map[string]any)
+	for _, kv := range spans["leapocr.WaitUntilDone"].attrs {
+		attrs[string(kv.Key)] = kv.Value.AsInterface()
+	}
+	if attrs["leapocr.job_id"] != "job_1" || attrs["leapocr.credits"] != int64(2) || attrs["leapocr.page_count"] != int64(1) {
+		t.Errorf("unexpected WaitUntilDone attributes: %v", attrs)
+	}
+
+	if traceparents["POST /ocr/uploads/direct"] == "" || traceparents["GET /ocr/status/job_1"] == "" {
+		t.Errorf("expected trace context on API requests, got %v", traceparents)
+	}
+	if traceparents["PUT /upload"] != "" {
+		t.Error("expected no trace context on storage uploads")
+	}
+}
@@ -35,6 +35,8 @@ type OCRResult struct {
 	Duration time.Duration  `json:"duration"`
 	JobID    string         `json:"job_id"`
 	Status   string         `json:"status"`
+	// Cached is set when the result was served from the ResultCache without submitting a job
+	Cached bool `json:"cached,omitempty"`
 }
 
 // PageResult represents a single page result
@@ -43,6 +45,27 @@ type PageResult struct {
 	Text       string         `json:"text"`
 	Data       map[string]any `json:"data"`
 	Confidence *float64       `json:"confidence,omitempty"`
+	// Width and Height are the page dimensions in pixels, set when bounding boxes were extracted
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+	// Blocks are the semantic blocks of the page with their locations
+	Blocks []Block `json:"blocks,omitempty"`
+}
+
+// Block is a semantic block of a page, such as a paragraph or a table
+type Block struct {
+	// Type is paragraph, heading, table, figure, caption, list, footer or header
+	Type   string      `json:"type"`
+	Text   string      `json:"text"`
+	Bounds BoundingBox `json:"bounds"`
+}
+
+// BoundingBox is a rectangle in pixels, with the origin at the top left of the page
+type BoundingBox struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
 }
 
 // ProcessingOption configures OCR processing
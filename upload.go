@@ -5,8 +5,10 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/leapocr/leapocr-go/internal/generated"
 )
@@ -23,16 +25,17 @@ func (s *SDK) uploadFileParts(ctx context.Context, resp *generated.UploadDirectU
 		return nil, NewSDKError(ErrorTypeUploadError, "failed to read file content", err)
 	}
 
-	client := s.config.HTTPClient
+	client := s.uploadClient
 	if client == nil {
 		client = http.DefaultClient
 	}
 
 	completedParts := make([]generated.UploadCompletedPart, 0, len(resp.Parts))
+	logger := s.logger.With("job_id", resp.GetJobId())
 
 	// Upload each part
 	for _, part := range resp.Parts {
-		completedPart, err := s.uploadSinglePart(ctx, client, part, fileContent)
+		completedPart, err := s.uploadSinglePart(ctx, client, logger, part, fileContent)
 		if err != nil {
 			return nil, err
 		}
@@ -43,7 +46,7 @@ func (s *SDK) uploadFileParts(ctx context.Context, resp *generated.UploadDirectU
 }
 
 // uploadSinglePart uploads a single file part to a presigned URL
-func (s *SDK) uploadSinglePart(ctx context.Context, client *http.Client, part generated.UploadMultipartPart, fileContent []byte) (generated.UploadCompletedPart, error) {
+func (s *SDK) uploadSinglePart(ctx context.Context, client *http.Client, logger *slog.Logger, part generated.UploadMultipartPart, fileContent []byte) (_ generated.UploadCompletedPart, err error) {
 	if part.UploadUrl == nil || part.StartByte == nil || part.EndByte == nil || part.PartNumber == nil {
 		return generated.UploadCompletedPart{}, NewSDKError(ErrorTypeUploadError, "invalid part configuration", nil)
 	}
@@ -64,25 +67,41 @@ func (s *SDK) uploadSinglePart(ctx context.Context, client *http.Client, part ge
 	// Extract chunk data
 	chunk := fileContent[startByte : endByte+1]
 
+	ctx, span := s.startSpan(ctx, "leapocr.upload.part", attrPartNum.Int(int(*part.PartNumber)), attrPartSize.Int(len(chunk)))
+	defer func() { endSpan(span, err) }()
+
+	partCtx, cancel := withTimeout(ctx, TimeoutPhaseUploadPart, s.config.UploadPartTimeout)
+	defer cancel()
+
 	// Create PUT request to upload the chunk
-	req, err := http.NewRequestWithContext(ctx, "PUT", *part.UploadUrl, bytes.NewReader(chunk))
+	req, err := http.NewRequestWithContext(partCtx, "PUT", *part.UploadUrl, bytes.NewReader(chunk))
 	if err != nil {
 		return generated.UploadCompletedPart{}, NewSDKError(ErrorTypeUploadError, "failed to create upload request", err)
 	}
 
 	// Upload the chunk
+	started := time.Now()
 	uploadResp, err := client.Do(req)
 	if err != nil {
-		return generated.UploadCompletedPart{}, NewSDKError(ErrorTypeUploadError, "failed to upload chunk", err)
+		// Errors from http.Client embed the presigned URL including its signature
+		err = redactURLError(err)
+		logger.ErrorContext(ctx, "part upload failed", "part_number", *part.PartNumber, "size", len(chunk), "error", err)
+		return generated.UploadCompletedPart{}, phaseTimeout(partCtx, NewSDKError(ErrorTypeUploadError, "failed to upload chunk", err))
 	}
 	defer func() { _ = uploadResp.Body.Close() }() //nolint:errcheck
 
+	span.SetAttributes(attrHTTPCode.Int(uploadResp.StatusCode))
+	logger.DebugContext(ctx, "part uploaded",
+		"part_number", *part.PartNumber, "size", len(chunk), "duration", time.Since(started), "status", uploadResp.StatusCode)
+
 	// Check response status
 	if uploadResp.StatusCode < 200 || uploadResp.StatusCode >= 300 {
 		return generated.UploadCompletedPart{}, NewSDKError(ErrorTypeUploadError,
 			fmt.Sprintf("upload failed with status %d", uploadResp.StatusCode), nil)
 	}
 
+	s.recorder().PartUploaded(len(chunk), time.Since(started))
+
 	// Extract ETag from response header
 	etag := uploadResp.Header.Get("ETag")
 	if etag == "" {
@@ -106,11 +125,14 @@ func (s *SDK) uploadSinglePart(ctx context.Context, client *http.Client, part ge
 }
 
 // completeDirectUpload completes the multipart upload by sending ETags
-func (s *SDK) completeDirectUpload(ctx context.Context, jobID string, completedParts []generated.UploadCompletedPart) error {
+func (s *SDK) completeDirectUpload(ctx context.Context, jobID string, completedParts []generated.UploadCompletedPart) (err error) {
 	if len(completedParts) == 0 {
 		return NewSDKError(ErrorTypeUploadError, "no upload parts to complete", nil)
 	}
 
+	ctx, span := s.startSpan(ctx, "leapocr.upload.complete", attrJobID.String(jobID))
+	defer func() { endSpan(span, err) }()
+
 	// Create completion request
 	completePayload := generated.UploadDirectUploadCompleteRequest{
 		Parts: completedParts,
@@ -124,7 +146,7 @@ func (s *SDK) completeDirectUpload(ctx context.Context, jobID string, completedP
 
 	_, httpResp, err := apiRequest.Execute()
 	if err != nil {
-		return s.handleAPIError(err, httpResp, "failed to complete direct upload")
+		return s.handleAPIError(ctx, err, httpResp, "failed to complete direct upload")
 	}
 
 	return nil
@@ -96,6 +96,24 @@ func ValidateURL(fileURL string) error {
 	return nil
 }
 
+// ValidateWebhookURL validates that a webhook endpoint URL is absolute and uses http or https
+func ValidateWebhookURL(webhookURL string) error {
+	parsedURL, err := url.Parse(webhookURL)
+	if err != nil {
+		return NewValidationError("url", fmt.Sprintf("invalid URL format: %v", err))
+	}
+
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return NewValidationError("url", "webhook URL must use http or https scheme")
+	}
+
+	if parsedURL.Host == "" {
+		return NewValidationError("url", "webhook URL must include a host")
+	}
+
+	return nil
+}
+
 // ValidateFormat validates the OCR format
 func ValidateFormat(format Format) error {
 	switch format {
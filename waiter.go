@@ -8,6 +8,12 @@ import (
 	"time"
 )
 
+// JobWaiter waits for a job to finish and returns its result.
+// *SDK implements it by polling; webhook.Waiter implements it with webhook deliveries.
+type JobWaiter interface {
+	WaitUntilDone(ctx context.Context, jobID string) (*OCRResult, error)
+}
+
 // WaitUntilDone waits for a job to complete with exponential backoff
 func (s *SDK) WaitUntilDone(ctx context.Context, jobID string) (*OCRResult, error) {
 	return s.WaitUntilDoneWithOptions(ctx, jobID, WaitOptions{})
@@ -39,9 +45,20 @@ func DefaultWaitOptions() WaitOptions {
 }
 
 // WaitUntilDoneWithOptions waits for job completion with custom options
-func (s *SDK) WaitUntilDoneWithOptions(ctx context.Context, jobID string, opts WaitOptions) (*OCRResult, error) {
+func (s *SDK) WaitUntilDoneWithOptions(ctx context.Context, jobID string, opts WaitOptions) (result *OCRResult, err error) {
 	opts = applyWaitDefaults(opts)
 
+	ctx, cancel := withTimeout(ctx, TimeoutPhaseWait, s.config.OperationTimeout)
+	defer cancel()
+
+	started := time.Now()
+	ctx, span := s.startSpan(ctx, "leapocr.WaitUntilDone", attrJobID.String(jobID))
+	defer func() {
+		err = phaseTimeout(ctx, err)
+		endSpan(span, err)
+		s.recordWait(ctx, jobID, started, result, err)
+	}()
+
 	currentDelay := opts.InitialDelay
 	attempts := 0
 
@@ -56,13 +73,24 @@ func (s *SDK) WaitUntilDoneWithOptions(ctx context.Context, jobID string, opts W
 
 		attempts++
 
-		result, shouldContinue, err := s.pollJobStatus(ctx, jobID)
+		pollCtx, pollSpan := s.startSpan(ctx, "leapocr.poll", attrJobID.String(jobID), attrAttempt.Int(attempts))
+		result, status, shouldContinue, err := s.pollJobStatus(pollCtx, jobID)
+		if status != nil {
+			pollSpan.SetAttributes(attrStatus.String(status.Status), attrProgress.Float64(status.Progress))
+			s.recorder().PollAttempt(status.Status)
+		}
+		endSpan(pollSpan, err)
 		if err != nil {
+			s.logger.WarnContext(ctx, "waiting for job failed", "job_id", jobID, "attempt", attempts, "error", err)
 			return nil, err
 		}
 		if !shouldContinue {
+			s.logger.InfoContext(ctx, "job completed", "job_id", jobID, "attempts", attempts, "credits", result.Credits)
+			span.SetAttributes(resultAttributes(result)...)
 			return result, nil
 		}
+		s.logger.DebugContext(ctx, "polled job status",
+			"job_id", jobID, "attempt", attempts, "status", status.Status, "progress", status.Progress, "next_delay", currentDelay)
 
 		if err := s.waitWithBackoff(ctx, currentDelay, opts.MaxJitter); err != nil {
 			return nil, err
@@ -104,27 +132,27 @@ func checkContext(ctx context.Context) error {
 	}
 }
 
-func (s *SDK) pollJobStatus(ctx context.Context, jobID string) (*OCRResult, bool, error) {
+func (s *SDK) pollJobStatus(ctx context.Context, jobID string) (*OCRResult, *JobStatusInfo, bool, error) {
 	status, err := s.getJobStatus(ctx, jobID)
 	if err != nil {
-		return nil, false, err
+		return nil, nil, false, err
 	}
 
 	switch status.Status {
 	case "completed":
 		result, err := s.getJobResult(ctx, jobID)
-		return result, false, err
+		return result, status, false, err
 	case "failed", "error":
 		errorMsg := "job failed"
 		if status.Error != "" {
 			errorMsg = "job failed: " + status.Error
 		}
-		return nil, false, NewSDKError(ErrorTypeJobError, errorMsg, nil)
+		return nil, status, false, NewSDKError(ErrorTypeJobError, errorMsg, nil)
 	case "canceled":
-		return nil, false, NewSDKError(ErrorTypeJobError, "job was canceled", nil)
+		return nil, status, false, NewSDKError(ErrorTypeJobError, "job was canceled", nil)
 	}
 
-	return nil, true, nil
+	return nil, status, true, nil
 }
 
 func (s *SDK) waitWithBackoff(ctx context.Context, delay, maxJitter time.Duration) error {
@@ -165,7 +193,7 @@ func (s *SDK) getJobStatus(ctx context.Context, jobID string) (*JobStatusInfo, e
 
 	resp, httpResp, err := apiRequest.Execute()
 	if err != nil {
-		return nil, s.handleAPIError(err, httpResp, "failed to get job status")
+		return nil, s.handleAPIError(ctx, err, httpResp, "failed to get job status")
 	}
 
 	// Convert generated response to our status info
@@ -192,13 +220,16 @@ func (s *SDK) getJobStatus(ctx context.Context, jobID string) (*JobStatusInfo, e
 }
 
 // getJobResult gets the final result of a completed job
-func (s *SDK) getJobResult(ctx context.Context, jobID string) (*OCRResult, error) {
+func (s *SDK) getJobResult(ctx context.Context, jobID string) (_ *OCRResult, err error) {
+	ctx, span := s.startSpan(ctx, "leapocr.GetJobResult", attrJobID.String(jobID))
+	defer func() { endSpan(span, err) }()
+
 	// Make API call to get job result using generated client
 	apiRequest := s.client.SDKAPI.GetJobResult(ctx, jobID)
 
 	resp, httpResp, err := apiRequest.Execute()
 	if err != nil {
-		return nil, s.handleAPIError(err, httpResp, "failed to get job result")
+		return nil, s.handleAPIError(ctx, err, httpResp, "failed to get job result")
 	}
 
 	// Convert generated response to our result type
@@ -251,6 +282,23 @@ func (s *SDK) getJobResult(ctx context.Context, jobID string) (*OCRResult, error
 				confidence := float64(*page.Confidence)
 				pageResult.Confidence = &confidence
 			}
+			if page.Dimensions != nil {
+				pageResult.Width = int(page.Dimensions.GetWidth())
+				pageResult.Height = int(page.Dimensions.GetHeight())
+			}
+			for _, box := range page.BoundingBoxes {
+				bounds := box.GetBounds()
+				pageResult.Blocks = append(pageResult.Blocks, Block{
+					Type: box.GetType(),
+					Text: box.GetText(),
+					Bounds: BoundingBox{
+						X:      int(bounds.GetX()),
+						Y:      int(bounds.GetY()),
+						Width:  int(bounds.GetWidth()),
+						Height: int(bounds.GetHeight()),
+					},
+				})
+			}
 
 			result.Pages[i] = pageResult
 		}
@@ -261,6 +309,7 @@ func (s *SDK) getJobResult(ctx context.Context, jobID string) (*OCRResult, error
 	if resp.CreditsUsed != nil {
 		result.Credits = int(*resp.CreditsUsed)
 	}
+	span.SetAttributes(resultAttributes(result)...)
 
 	return result, nil
 }
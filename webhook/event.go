@@ -0,0 +1,161 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	ocr "github.com/leapocr/leapocr-go"
+)
+
+// EventType identifies the kind of a webhook event
+type EventType string
+
+const (
+	// EventJobCreated is sent when a job has been accepted for processing
+	EventJobCreated EventType = "job.created"
+	// EventJobCompleted is sent when every page of a job has been processed
+	EventJobCompleted EventType = "job.completed"
+	// EventJobPartiallyDone is sent when a job finished with some pages failing
+	EventJobPartiallyDone EventType = "job.partially_done"
+	// EventJobFailed is sent when a job could not be processed
+	EventJobFailed EventType = "job.failed"
+	// EventWebhookTest is sent when a test delivery is triggered for a subscription
+	EventWebhookTest EventType = "webhook.test"
+)
+
+// Event is implemented by every typed webhook event returned from Parse
+type Event interface {
+	// EventID returns the unique identifier of the event, stable across delivery attempts
+	EventID() string
+	// EventType returns the type of the event
+	EventType() EventType
+}
+
+// Envelope holds the fields common to every webhook delivery
+type Envelope struct {
+	ID        string          `json:"event_id"`
+	Type      EventType       `json:"event_type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// EventID returns the unique identifier of the event
+func (e Envelope) EventID() string {
+	return e.ID
+}
+
+// EventType returns the type of the event
+func (e Envelope) EventType() EventType {
+	return e.Type
+}
+
+// JobPayload describes the job an event refers to
+type JobPayload struct {
+	JobID          string     `json:"job_id"`
+	Status         string     `json:"status"`
+	FileName       string     `json:"file_name,omitempty"`
+	Model          string     `json:"model,omitempty"`
+	ResultFormat   string     `json:"result_format,omitempty"`
+	TotalPages     int        `json:"total_pages,omitempty"`
+	ProcessedPages int        `json:"processed_pages,omitempty"`
+	CreditsUsed    int        `json:"credits_used,omitempty"`
+	ErrorMessage   string     `json:"error_message,omitempty"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+}
+
+// JobCreatedEvent is delivered for EventJobCreated
+type JobCreatedEvent struct {
+	Envelope
+	Job JobPayload
+}
+
+// JobCompletedEvent is delivered for EventJobCompleted
+type JobCompletedEvent struct {
+	Envelope
+	Job JobPayload
+}
+
+// JobPartiallyDoneEvent is delivered for EventJobPartiallyDone
+type JobPartiallyDoneEvent struct {
+	Envelope
+	Job JobPayload
+}
+
+// JobFailedEvent is delivered for EventJobFailed
+type JobFailedEvent struct {
+	Envelope
+	Job JobPayload
+}
+
+// TestEvent is delivered for EventWebhookTest
+type TestEvent struct {
+	Envelope
+}
+
+// UnknownEvent is returned for event types this version of the SDK does not know about.
+// The raw payload is available in Envelope.Payload.
+type UnknownEvent struct {
+	Envelope
+}
+
+// Parse decodes a delivery body into its typed event.
+// Parse does not verify the signature; call Verify first for untrusted input.
+func Parse(body []byte) (Event, error) {
+	var envelope Envelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, ocr.NewSDKError(ocr.ErrorTypeValidationError, "invalid webhook payload", err)
+	}
+	if envelope.ID == "" {
+		return nil, ocr.NewSDKError(ocr.ErrorTypeValidationError, "invalid webhook payload: missing event_id", nil)
+	}
+	if envelope.Type == "" {
+		return nil, ocr.NewSDKError(ocr.ErrorTypeValidationError, "invalid webhook payload: missing event_type", nil)
+	}
+
+	var job JobPayload
+	switch envelope.Type {
+	case EventJobCreated, EventJobCompleted, EventJobPartiallyDone, EventJobFailed:
+		var err error
+		if job, err = parseJobPayload(envelope); err != nil {
+			return nil, err
+		}
+	}
+
+	switch envelope.Type {
+	case EventJobCreated:
+		return JobCreatedEvent{Envelope: envelope, Job: job}, nil
+	case EventJobCompleted:
+		return JobCompletedEvent{Envelope: envelope, Job: job}, nil
+	case EventJobPartiallyDone:
+		return JobPartiallyDoneEvent{Envelope: envelope, Job: job}, nil
+	case EventJobFailed:
+		return JobFailedEvent{Envelope: envelope, Job: job}, nil
+	case EventWebhookTest:
+		return TestEvent{Envelope: envelope}, nil
+	default:
+		return UnknownEvent{Envelope: envelope}, nil
+	}
+}
+
+// VerifyAndParse verifies a delivery with secret and decodes it
+func VerifyAndParse(secret string, headers http.Header, body []byte) (Event, error) {
+	if err := Verify(secret, headers, body); err != nil {
+		return nil, err
+	}
+	return Parse(body)
+}
+
+func parseJobPayload(envelope Envelope) (JobPayload, error) {
+	var job JobPayload
+	if len(envelope.Payload) == 0 {
+		return job, ocr.NewSDKError(ocr.ErrorTypeValidationError, "invalid webhook payload: missing payload for "+string(envelope.Type), nil)
+	}
+	if err := json.Unmarshal(envelope.Payload, &job); err != nil {
+		return job, ocr.NewSDKError(ocr.ErrorTypeValidationError, "invalid webhook payload for "+string(envelope.Type), err)
+	}
+	if job.JobID == "" {
+		return job, ocr.NewSDKError(ocr.ErrorTypeValidationError, "invalid webhook payload: missing job_id", nil)
+	}
+	return job, nil
+}
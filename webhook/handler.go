@@ -0,0 +1,246 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultMaxBodyBytes is the largest delivery body accepted by Handler
+const DefaultMaxBodyBytes = 1 << 20
+
+// Handler is an http.Handler that verifies webhook deliveries, decodes them
+// and dispatches them to the registered callbacks.
+//
+// Responses are chosen so that the platform retries deliveries that were not
+// processed: callback errors return 500, while duplicates of already processed
+// events are acknowledged with 200 without invoking callbacks again.
+type Handler struct {
+	verifier     *Verifier
+	store        IdempotencyStore
+	maxBodyBytes int64
+
+	mu                 sync.RWMutex
+	onJobCreated       func(context.Context, JobCreatedEvent) error
+	onJobCompleted     func(context.Context, JobCompletedEvent) error
+	onJobPartiallyDone func(context.Context, JobPartiallyDoneEvent) error
+	onJobFailed        func(context.Context, JobFailedEvent) error
+	onTest             func(context.Context, TestEvent) error
+	onEvent            func(context.Context, Event) error
+}
+
+// HandlerOption configures a Handler
+type HandlerOption func(*Handler)
+
+// WithSecrets adds secrets accepted in addition to the primary secret,
+// e.g. the previous secret during a rotation grace window
+func WithSecrets(secrets ...string) HandlerOption {
+	return func(h *Handler) {
+		h.verifier.Secrets = append(h.verifier.Secrets, secrets...)
+	}
+}
+
+// WithTolerance sets the maximum accepted age of a delivery (default: DefaultTolerance)
+func WithTolerance(tolerance time.Duration) HandlerOption {
+	return func(h *Handler) {
+		h.verifier.Tolerance = tolerance
+	}
+}
+
+// WithIdempotencyStore sets the store used to skip duplicate deliveries.
+// Passing nil disables deduplication.
+func WithIdempotencyStore(store IdempotencyStore) HandlerOption {
+	return func(h *Handler) {
+		h.store = store
+	}
+}
+
+// WithMaxBodyBytes limits the size of accepted delivery bodies (default: DefaultMaxBodyBytes)
+func WithMaxBodyBytes(n int64) HandlerOption {
+	return func(h *Handler) {
+		h.maxBodyBytes = n
+	}
+}
+
+// NewHandler creates a Handler that verifies deliveries signed with secret.
+// By default duplicate deliveries are detected with an in-memory store.
+func NewHandler(secret string, opts ...HandlerOption) *Handler {
+	h := &Handler{
+		verifier:     &Verifier{Secrets: []string{secret}},
+		store:        NewMemoryStore(DefaultIdempotencyTTL),
+		maxBodyBytes: DefaultMaxBodyBytes,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// OnJobCreated registers the callback for EventJobCreated
+func (h *Handler) OnJobCreated(fn func(context.Context, JobCreatedEvent) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onJobCreated = fn
+}
+
+// OnJobCompleted registers the callback for EventJobCompleted
+func (h *Handler) OnJobCompleted(fn func(context.Context, JobCompletedEvent) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onJobCompleted = fn
+}
+
+// OnJobPartiallyDone registers the callback for EventJobPartiallyDone
+func (h *Handler) OnJobPartiallyDone(fn func(context.Context, JobPartiallyDoneEvent) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onJobPartiallyDone = fn
+}
+
+// OnJobFailed registers the callback for EventJobFailed
+func (h *Handler) OnJobFailed(fn func(context.Context, JobFailedEvent) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onJobFailed = fn
+}
+
+// OnTest registers the callback for EventWebhookTest
+func (h *Handler) OnTest(fn func(context.Context, TestEvent) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onTest = fn
+}
+
+// OnEvent registers a fallback callback for events without a typed callback,
+// including event types unknown to this version of the SDK
+func (h *Handler) OnEvent(fn func(context.Context, Event) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onEvent = fn
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.maxBodyBytes))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			http.Error(w, "payload too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.verifier.Verify(r.Header, body); err != nil {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	event, err := Parse(body)
+	if err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	switch err := h.Dispatch(r.Context(), event); {
+	case err == nil, errors.Is(err, ErrDuplicateEvent):
+		w.WriteHeader(http.StatusOK)
+	case errors.Is(err, ErrEventInProgress):
+		http.Error(w, "event is being processed", http.StatusConflict)
+	default:
+		http.Error(w, "failed to process event", http.StatusInternalServerError)
+	}
+}
+
+// Dispatch delivers an already verified event to its callback, skipping events
+// that were already processed. It returns ErrDuplicateEvent or ErrEventInProgress
+// when the event is skipped, and the callback error otherwise. If the callback
+// panics, the event is released for redelivery before the panic continues.
+func (h *Handler) Dispatch(ctx context.Context, event Event) error {
+	if h.store == nil {
+		return h.invoke(ctx, event)
+	}
+
+	id := event.EventID()
+	if err := h.store.Reserve(ctx, id); err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = h.store.Release(context.WithoutCancel(ctx), id) //nolint:errcheck // the panic takes precedence
+			panic(p)
+		}
+	}()
+
+	if err := h.invoke(ctx, event); err != nil {
+		if releaseErr := h.store.Release(ctx, id); releaseErr != nil {
+			return errors.Join(err, releaseErr)
+		}
+		return err
+	}
+
+	return h.store.Complete(ctx, id)
+}
+
+// invoke calls the callback registered for the event type
+func (h *Handler) invoke(ctx context.Context, event Event) error {
+	h.mu.RLock()
+	callback := h.callbackFor(event)
+	h.mu.RUnlock()
+
+	if callback == nil {
+		// Events nobody subscribed to are acknowledged so they are not redelivered
+		return nil
+	}
+	return callback(ctx)
+}
+
+// callbackFor returns the callback bound to event; callers must hold h.mu
+func (h *Handler) callbackFor(event Event) func(context.Context) error {
+	switch e := event.(type) {
+	case JobCreatedEvent:
+		if h.onJobCreated != nil {
+			fn := h.onJobCreated
+			return func(ctx context.Context) error { return fn(ctx, e) }
+		}
+	case JobCompletedEvent:
+		if h.onJobCompleted != nil {
+			fn := h.onJobCompleted
+			return func(ctx context.Context) error { return fn(ctx, e) }
+		}
+	case JobPartiallyDoneEvent:
+		if h.onJobPartiallyDone != nil {
+			fn := h.onJobPartiallyDone
+			return func(ctx context.Context) error { return fn(ctx, e) }
+		}
+	case JobFailedEvent:
+		if h.onJobFailed != nil {
+			fn := h.onJobFailed
+			return func(ctx context.Context) error { return fn(ctx, e) }
+		}
+	case TestEvent:
+		if h.onTest != nil {
+			fn := h.onTest
+			return func(ctx context.Context) error { return fn(ctx, e) }
+		}
+	}
+
+	if h.onEvent != nil {
+		fn := h.onEvent
+		return func(ctx context.Context) error { return fn(ctx, event) }
+	}
+
+	return nil
+}
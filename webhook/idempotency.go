@@ -0,0 +1,106 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultIdempotencyTTL is how long processed event IDs are remembered by MemoryStore
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+var (
+	// ErrDuplicateEvent is returned by IdempotencyStore.Reserve for events that were already processed
+	ErrDuplicateEvent = errors.New("event already processed")
+	// ErrEventInProgress is returned by IdempotencyStore.Reserve for events currently being processed
+	ErrEventInProgress = errors.New("event is being processed")
+)
+
+// IdempotencyStore records which event IDs have been processed so that
+// repeated deliveries of the same event are handled only once.
+// Implementations must be safe for concurrent use.
+type IdempotencyStore interface {
+	// Reserve claims eventID for processing. It returns ErrDuplicateEvent if the
+	// event was already processed and ErrEventInProgress if another delivery holds it.
+	Reserve(ctx context.Context, eventID string) error
+	// Complete marks a reserved event as processed
+	Complete(ctx context.Context, eventID string) error
+	// Release drops a reservation so that a later delivery can retry the event
+	Release(ctx context.Context, eventID string) error
+}
+
+// MemoryStore is an in-process IdempotencyStore.
+// It only deduplicates deliveries received by the same process.
+type MemoryStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]memoryEntry
+	ops     int
+}
+
+type memoryEntry struct {
+	done      bool
+	expiresAt time.Time
+}
+
+// NewMemoryStore creates a MemoryStore that remembers events for ttl (default: DefaultIdempotencyTTL)
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	return &MemoryStore{
+		ttl:     ttl,
+		entries: make(map[string]memoryEntry),
+	}
+}
+
+// Reserve implements IdempotencyStore
+func (m *MemoryStore) Reserve(_ context.Context, eventID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.sweep(now)
+
+	if entry, ok := m.entries[eventID]; ok && now.Before(entry.expiresAt) {
+		if entry.done {
+			return ErrDuplicateEvent
+		}
+		return ErrEventInProgress
+	}
+
+	m.entries[eventID] = memoryEntry{expiresAt: now.Add(m.ttl)}
+	return nil
+}
+
+// Complete implements IdempotencyStore
+func (m *MemoryStore) Complete(_ context.Context, eventID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[eventID] = memoryEntry{done: true, expiresAt: time.Now().Add(m.ttl)}
+	return nil
+}
+
+// Release implements IdempotencyStore
+func (m *MemoryStore) Release(_ context.Context, eventID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, eventID)
+	return nil
+}
+
+// sweep periodically drops expired entries; callers must hold m.mu
+func (m *MemoryStore) sweep(now time.Time) {
+	m.ops++
+	if m.ops%1000 != 0 {
+		return
+	}
+	for id, entry := range m.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(m.entries, id)
+		}
+	}
+}
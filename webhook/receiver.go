@@ -0,0 +1,226 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultForwardTimeout limits forwarding a delivery with the default client,
+// so that a hung application does not block the receiver
+const DefaultForwardTimeout = 30 * time.Second
+
+// defaultForwardClient forwards and redelivers deliveries unless a client is set
+var defaultForwardClient = &http.Client{Timeout: DefaultForwardTimeout}
+
+// Delivery is a webhook delivery seen by a Receiver
+type Delivery struct {
+	ReceivedAt time.Time `json:"received_at"`
+	EventID    string    `json:"event_id,omitempty"`
+	EventType  EventType `json:"event_type,omitempty"`
+	// Body is the delivery body as received
+	Body json.RawMessage `json:"body"`
+	// Error reports why the delivery was rejected; rejected deliveries are not recorded or forwarded
+	Error string `json:"error,omitempty"`
+	// ForwardStatus is the status code returned by the forward URL
+	ForwardStatus int    `json:"forward_status,omitempty"`
+	ForwardError  string `json:"forward_error,omitempty"`
+	// RecordError reports a failure to append the delivery to the record
+	RecordError string `json:"-"`
+}
+
+// Verified reports whether the delivery passed signature verification and decoding
+func (d *Delivery) Verified() bool {
+	return d.Error == ""
+}
+
+// Receiver is an http.Handler for developing webhook integrations locally. It
+// verifies deliveries, appends verified events to a JSON Lines record, forwards
+// them with their signature headers to a local application and reports every
+// delivery to a callback.
+//
+// Without a forward URL verified deliveries are acknowledged with 200;
+// otherwise the status code of the application is returned, so that the
+// platform retries deliveries the application failed to process.
+type Receiver struct {
+	verifier     *Verifier
+	maxBodyBytes int64
+	forwardURL   string
+	client       *http.Client
+	onDelivery   func(Delivery)
+
+	// mu serializes writes to the record and calls of onDelivery
+	mu     sync.Mutex
+	record io.Writer
+}
+
+// ReceiverOption configures a Receiver
+type ReceiverOption func(*Receiver)
+
+// WithRecord appends each verified delivery to w as one line of JSON
+func WithRecord(w io.Writer) ReceiverOption {
+	return func(r *Receiver) {
+		r.record = w
+	}
+}
+
+// WithForwardURL forwards verified deliveries, with their signature headers, to url
+func WithForwardURL(url string) ReceiverOption {
+	return func(r *Receiver) {
+		r.forwardURL = url
+	}
+}
+
+// WithForwardClient sets the HTTP client used to forward deliveries (default: a
+// client with DefaultForwardTimeout)
+func WithForwardClient(client *http.Client) ReceiverOption {
+	return func(r *Receiver) {
+		r.client = client
+	}
+}
+
+// WithDeliveryCallback sets a function called after each delivery, including
+// rejected ones. Calls are serialized.
+func WithDeliveryCallback(fn func(Delivery)) ReceiverOption {
+	return func(r *Receiver) {
+		r.onDelivery = fn
+	}
+}
+
+// WithReceiverMaxBodyBytes limits the size of accepted delivery bodies (default: DefaultMaxBodyBytes)
+func WithReceiverMaxBodyBytes(n int64) ReceiverOption {
+	return func(r *Receiver) {
+		r.maxBodyBytes = n
+	}
+}
+
+// NewReceiver creates a Receiver checking deliveries with verifier
+func NewReceiver(verifier *Verifier, opts ...ReceiverOption) *Receiver {
+	r := &Receiver{
+		verifier:     verifier,
+		maxBodyBytes: DefaultMaxBodyBytes,
+		client:       defaultForwardClient,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// ServeHTTP implements http.Handler
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, r.maxBodyBytes))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			http.Error(w, "payload too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	delivery := r.Receive(req.Context(), req.Header, body)
+	switch {
+	case !delivery.Verified():
+		http.Error(w, delivery.Error, http.StatusUnauthorized)
+	case delivery.RecordError != "":
+		http.Error(w, "failed to record delivery", http.StatusInternalServerError)
+	case delivery.ForwardError != "":
+		http.Error(w, "failed to forward delivery", http.StatusBadGateway)
+	case delivery.ForwardStatus != 0:
+		w.WriteHeader(delivery.ForwardStatus)
+	default:
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// Receive handles a delivery read by other means than ServeHTTP, such as an
+// event fetched from the delivery log and signed with Sign
+func (r *Receiver) Receive(ctx context.Context, headers http.Header, body []byte) Delivery {
+	delivery := Delivery{ReceivedAt: time.Now().UTC()}
+	if json.Valid(body) {
+		delivery.Body = body
+	} else {
+		// Keep the record valid JSON for bodies that are not
+		delivery.Body, _ = json.Marshal(string(body)) //nolint:errcheck
+	}
+
+	event, err := r.verify(headers, body)
+	if err != nil {
+		delivery.Error = err.Error()
+		return r.finish(delivery)
+	}
+	delivery.EventID = event.EventID()
+	delivery.EventType = event.EventType()
+
+	if r.forwardURL != "" {
+		status, err := r.forward(ctx, headers, body)
+		delivery.ForwardStatus = status
+		if err != nil {
+			delivery.ForwardError = err.Error()
+		}
+	}
+
+	return r.finish(delivery)
+}
+
+func (r *Receiver) verify(headers http.Header, body []byte) (Event, error) {
+	if err := r.verifier.Verify(headers, body); err != nil {
+		return nil, err
+	}
+	return Parse(body)
+}
+
+// forward posts the delivery to the forward URL with its original signature headers
+func (r *Receiver) forward(ctx context.Context, headers http.Header, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(context.WithoutCancel(ctx), http.MethodPost, r.forwardURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for _, name := range []string{SignatureHeader, TimestampHeader} {
+		if value := headers.Get(name); value != "" {
+			req.Header.Set(name, value)
+		}
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:errcheck
+	_, _ = io.Copy(io.Discard, resp.Body)    //nolint:errcheck
+	return resp.StatusCode, nil
+}
+
+// finish records a verified delivery and reports it to the callback
+func (r *Receiver) finish(delivery Delivery) Delivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if delivery.Verified() && r.record != nil {
+		line, err := json.Marshal(delivery)
+		if err == nil {
+			_, err = r.record.Write(append(line, '\n'))
+		}
+		if err != nil {
+			delivery.RecordError = err.Error()
+		}
+	}
+	if r.onDelivery != nil {
+		r.onDelivery(delivery)
+	}
+	return delivery
+}
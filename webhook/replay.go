@@ -0,0 +1,133 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	ocr "github.com/leapocr/leapocr-go"
+)
+
+// BackfillResult summarizes a Backfill run
+type BackfillResult struct {
+	// Replayed counts events whose callback ran successfully
+	Replayed int
+	// Duplicates counts events skipped because they were already processed
+	Duplicates int
+}
+
+// FromLog converts a delivery log entry into a typed event.
+// A non-nil payload (such as the enriched payload from GetWebhookEventPayload)
+// replaces the payload recorded in the log.
+func FromLog(entry ocr.WebhookEvent, payload map[string]any) (Event, error) {
+	body, err := EncodeLog(entry, payload)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(body)
+}
+
+// EncodeLog returns the delivery body LeapOCR sends for a delivery log entry.
+// A non-nil payload replaces the payload recorded in the log.
+func EncodeLog(entry ocr.WebhookEvent, payload map[string]any) ([]byte, error) {
+	if payload == nil {
+		payload = entry.Payload
+	}
+
+	rawPayload, err := json.Marshal(payload)
+	if err != nil {
+		return nil, ocr.NewSDKError(ocr.ErrorTypeValidationError, "failed to encode logged payload", err)
+	}
+
+	id := entry.EventID
+	if id == "" {
+		id = entry.ID
+	}
+
+	body, err := json.Marshal(Envelope{
+		ID:        id,
+		Type:      EventType(entry.EventType),
+		CreatedAt: entry.CreatedAt,
+		Payload:   rawPayload,
+	})
+	if err != nil {
+		return nil, ocr.NewSDKError(ocr.ErrorTypeValidationError, "failed to encode logged event", err)
+	}
+
+	return body, nil
+}
+
+// Redeliver signs body with secret and posts it to url the way LeapOCR
+// delivers webhooks. Responses outside the 2xx range are returned as errors.
+// A nil client uses one with DefaultForwardTimeout.
+func Redeliver(ctx context.Context, client *http.Client, url, secret string, body []byte) error {
+	if secret == "" {
+		return verificationError("no secret configured", ErrMissingSecret)
+	}
+	if client == nil {
+		client = defaultForwardClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return ocr.NewSDKError(ocr.ErrorTypeValidationError, "invalid delivery URL", err)
+	}
+	for name, values := range Sign(secret, time.Now(), body) {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return ocr.NewSDKError(ocr.ErrorTypeHTTPError, "failed to deliver webhook", err)
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:errcheck
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return ocr.NewHTTPError(resp.StatusCode, fmt.Sprintf("webhook endpoint responded with %s", resp.Status), nil)
+	}
+	return nil
+}
+
+// Replay dispatches a delivery log entry into h as if it had just been delivered
+func (h *Handler) Replay(ctx context.Context, entry ocr.WebhookEvent, payload map[string]any) error {
+	event, err := FromLog(entry, payload)
+	if err != nil {
+		return err
+	}
+	return h.Dispatch(ctx, event)
+}
+
+// Backfill replays every delivery log entry matching filter into h, fetching
+// the enriched payload of each event first. Events h has already processed are
+// skipped by its idempotency store, so an interrupted backfill can simply be
+// run again. Backfill stops at the first error.
+func Backfill(ctx context.Context, sdk *ocr.SDK, h *Handler, filter ocr.WebhookEventFilter) (BackfillResult, error) {
+	var result BackfillResult
+
+	for entry, err := range sdk.WebhookEvents(ctx, filter) {
+		if err != nil {
+			return result, err
+		}
+
+		payload, err := sdk.GetWebhookEventPayload(ctx, entry.ID)
+		if err != nil {
+			return result, err
+		}
+
+		switch err := h.Replay(ctx, entry, payload.Payload); {
+		case err == nil:
+			result.Replayed++
+		case errors.Is(err, ErrDuplicateEvent):
+			result.Duplicates++
+		default:
+			return result, fmt.Errorf("replaying event %s: %w", entry.EventID, err)
+		}
+	}
+
+	return result, nil
+}
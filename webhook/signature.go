@@ -0,0 +1,169 @@
+// Package webhook verifies and decodes webhook deliveries sent by LeapOCR.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	ocr "github.com/leapocr/leapocr-go"
+)
+
+const (
+	// SignatureHeader carries one or more "v1=<hex>" HMAC-SHA256 signatures
+	SignatureHeader = "X-LeapOCR-Signature"
+	// TimestampHeader carries the Unix time (in seconds) the delivery was signed at
+	TimestampHeader = "X-LeapOCR-Timestamp"
+	// DefaultTolerance is the maximum allowed clock skew between signing and verification
+	DefaultTolerance = 5 * time.Minute
+
+	signatureScheme = "v1"
+)
+
+var (
+	// ErrMissingSecret is returned when no signing secret is configured
+	ErrMissingSecret = errors.New("webhook secret is required")
+	// ErrMissingSignature is returned when the signature header is absent
+	ErrMissingSignature = errors.New("missing signature header")
+	// ErrMissingTimestamp is returned when the timestamp header is absent
+	ErrMissingTimestamp = errors.New("missing timestamp header")
+	// ErrInvalidTimestamp is returned when the timestamp header cannot be parsed
+	ErrInvalidTimestamp = errors.New("invalid timestamp header")
+	// ErrTimestampOutOfRange is returned when the delivery is older or newer than the tolerance allows
+	ErrTimestampOutOfRange = errors.New("timestamp outside of tolerance")
+	// ErrInvalidSignature is returned when no signature matches any configured secret
+	ErrInvalidSignature = errors.New("signature mismatch")
+)
+
+// Verifier checks webhook signatures against one or more secrets.
+// Multiple secrets allow accepting deliveries signed with either the old or the
+// new secret while a rotation is in progress.
+type Verifier struct {
+	// Secrets are the signing secrets accepted by the verifier
+	Secrets []string
+	// Tolerance is the maximum age of a delivery (default: DefaultTolerance)
+	Tolerance time.Duration
+	// Now returns the current time (default: time.Now)
+	Now func() time.Time
+}
+
+// Verify checks that body was signed with secret and that the delivery is recent
+func Verify(secret string, headers http.Header, body []byte) error {
+	v := &Verifier{Secrets: []string{secret}}
+	return v.Verify(headers, body)
+}
+
+// Verify checks the signature and timestamp headers of a delivery.
+// Signatures are compared in constant time.
+func (v *Verifier) Verify(headers http.Header, body []byte) error {
+	secrets := make([]string, 0, len(v.Secrets))
+	for _, secret := range v.Secrets {
+		if secret != "" {
+			secrets = append(secrets, secret)
+		}
+	}
+	if len(secrets) == 0 {
+		return verificationError("no secret configured", ErrMissingSecret)
+	}
+
+	rawSignature := headers.Get(SignatureHeader)
+	if rawSignature == "" {
+		return verificationError("signature header not present", ErrMissingSignature)
+	}
+
+	rawTimestamp := headers.Get(TimestampHeader)
+	if rawTimestamp == "" {
+		return verificationError("timestamp header not present", ErrMissingTimestamp)
+	}
+
+	unix, err := strconv.ParseInt(rawTimestamp, 10, 64)
+	if err != nil {
+		return verificationError(fmt.Sprintf("timestamp %q is not a Unix time", rawTimestamp), ErrInvalidTimestamp)
+	}
+
+	if err := v.checkTimestamp(time.Unix(unix, 0)); err != nil {
+		return err
+	}
+
+	signatures := parseSignatures(rawSignature)
+	for _, secret := range secrets {
+		expected := computeSignature(secret, rawTimestamp, body)
+		for _, signature := range signatures {
+			if hmac.Equal(expected, signature) {
+				return nil
+			}
+		}
+	}
+
+	return verificationError("no signature matches the configured secrets", ErrInvalidSignature)
+}
+
+func (v *Verifier) checkTimestamp(signedAt time.Time) error {
+	tolerance := v.Tolerance
+	if tolerance == 0 {
+		tolerance = DefaultTolerance
+	}
+
+	now := time.Now()
+	if v.Now != nil {
+		now = v.Now()
+	}
+
+	skew := now.Sub(signedAt)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > tolerance {
+		return verificationError(fmt.Sprintf("delivery signed at %s is outside the %s tolerance", signedAt.UTC().Format(time.RFC3339), tolerance), ErrTimestampOutOfRange)
+	}
+
+	return nil
+}
+
+// Sign returns the headers LeapOCR attaches to a delivery of body signed at timestamp.
+// It is useful for tests and for replaying stored deliveries into a handler.
+func Sign(secret string, timestamp time.Time, body []byte) http.Header {
+	rawTimestamp := strconv.FormatInt(timestamp.Unix(), 10)
+	signature := hex.EncodeToString(computeSignature(secret, rawTimestamp, body))
+
+	headers := make(http.Header)
+	headers.Set(TimestampHeader, rawTimestamp)
+	headers.Set(SignatureHeader, signatureScheme+"="+signature)
+	return headers
+}
+
+// computeSignature computes HMAC-SHA256(secret, timestamp + "." + body)
+func computeSignature(secret, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// parseSignatures extracts the decoded v1 signatures from a header value such as "v1=abc,v1=def"
+func parseSignatures(header string) [][]byte {
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		scheme, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || scheme != signatureScheme {
+			continue
+		}
+		decoded, err := hex.DecodeString(value)
+		if err != nil {
+			continue
+		}
+		signatures = append(signatures, decoded)
+	}
+	return signatures
+}
+
+func verificationError(message string, cause error) *ocr.SDKError {
+	return ocr.NewSDKError(ocr.ErrorTypeValidationError, "webhook verification failed: "+message, cause)
+}
@@ -0,0 +1,253 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	ocr "github.com/leapocr/leapocr-go"
+)
+
+const (
+	// DefaultFallbackAfter is how long Waiter waits for a delivery before it starts polling
+	DefaultFallbackAfter = 2 * time.Minute
+	// DefaultOutcomeRetention is how long Waiter remembers outcomes nobody was waiting for yet
+	DefaultOutcomeRetention = 10 * time.Minute
+)
+
+// DefaultFallbackWaitOptions returns the slow polling used once a delivery is overdue
+func DefaultFallbackWaitOptions() ocr.WaitOptions {
+	return ocr.WaitOptions{
+		InitialDelay: 10 * time.Second,
+		MaxDelay:     1 * time.Minute,
+		Multiplier:   1.5,
+		MaxJitter:    5 * time.Second,
+	}
+}
+
+// Waiter waits for jobs to finish by listening for webhook deliveries instead
+// of polling the job status. Mount it as the http.Handler of the webhook
+// endpoint; when no delivery arrives within the fallback delay it falls back to
+// slow polling, racing the poller against a late delivery.
+//
+// Waiter implements ocr.JobWaiter.
+type Waiter struct {
+	sdk         *ocr.SDK
+	handler     *Handler
+	fallback    time.Duration
+	pollOptions ocr.WaitOptions
+	retention   time.Duration
+
+	mu       sync.Mutex
+	pending  map[string][]chan jobOutcome
+	outcomes map[string]jobOutcome
+}
+
+// jobOutcome is the terminal state of a job reported by a delivery
+type jobOutcome struct {
+	failed     bool
+	message    string
+	receivedAt time.Time
+}
+
+// WaiterOption configures a Waiter
+type WaiterOption func(*Waiter)
+
+// WithFallbackAfter sets how long to wait for a delivery before polling (default: DefaultFallbackAfter)
+func WithFallbackAfter(d time.Duration) WaiterOption {
+	return func(w *Waiter) {
+		w.fallback = d
+	}
+}
+
+// WithFallbackWaitOptions sets the polling options used after the fallback delay
+func WithFallbackWaitOptions(opts ocr.WaitOptions) WaiterOption {
+	return func(w *Waiter) {
+		w.pollOptions = opts
+	}
+}
+
+// WithOutcomeRetention sets how long outcomes delivered before WaitUntilDone is called are kept
+func WithOutcomeRetention(d time.Duration) WaiterOption {
+	return func(w *Waiter) {
+		w.retention = d
+	}
+}
+
+// WithHandler sets the Handler receiving deliveries. Its job completion and
+// failure callbacks are replaced by the waiter; other callbacks are kept.
+func WithHandler(h *Handler) WaiterOption {
+	return func(w *Waiter) {
+		w.handler = h
+	}
+}
+
+// NewWaiter creates a Waiter that verifies deliveries with secret and uses sdk
+// to fetch results and to poll after the fallback delay
+func NewWaiter(sdk *ocr.SDK, secret string, opts ...WaiterOption) *Waiter {
+	w := &Waiter{
+		sdk:         sdk,
+		fallback:    DefaultFallbackAfter,
+		pollOptions: DefaultFallbackWaitOptions(),
+		retention:   DefaultOutcomeRetention,
+		pending:     make(map[string][]chan jobOutcome),
+		outcomes:    make(map[string]jobOutcome),
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	if w.handler == nil {
+		w.handler = NewHandler(secret)
+	}
+
+	w.handler.OnJobCompleted(func(_ context.Context, e JobCompletedEvent) error {
+		w.resolve(e.Job.JobID, jobOutcome{})
+		return nil
+	})
+	w.handler.OnJobPartiallyDone(func(_ context.Context, e JobPartiallyDoneEvent) error {
+		w.resolve(e.Job.JobID, jobOutcome{})
+		return nil
+	})
+	w.handler.OnJobFailed(func(_ context.Context, e JobFailedEvent) error {
+		w.resolve(e.Job.JobID, jobOutcome{failed: true, message: e.Job.ErrorMessage})
+		return nil
+	})
+
+	return w
+}
+
+// ServeHTTP implements http.Handler by delegating to the underlying Handler
+func (w *Waiter) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	w.handler.ServeHTTP(rw, r)
+}
+
+// Handler returns the Handler receiving deliveries, e.g. to register further callbacks
+func (w *Waiter) Handler() *Handler {
+	return w.handler
+}
+
+// WaitUntilDone waits for a delivery reporting that the job finished and
+// returns its result. The outcome is reported to the SDK's metrics recorder
+// like that of ocr.SDK.WaitUntilDone.
+func (w *Waiter) WaitUntilDone(ctx context.Context, jobID string) (result *ocr.OCRResult, err error) {
+	if jobID == "" {
+		return nil, ocr.NewSDKError(ocr.ErrorTypeValidationError, "job ID is required", nil)
+	}
+
+	started := time.Now()
+	defer func() { w.sdk.RecordWait(jobID, started, result, err) }()
+
+	ch := w.register(jobID)
+	defer w.unregister(jobID, ch)
+
+	timer := time.NewTimer(w.fallback)
+	defer timer.Stop()
+
+	select {
+	case outcome := <-ch:
+		return w.result(ctx, jobID, outcome)
+	case <-ctx.Done():
+		return nil, ocr.NewSDKError(ocr.ErrorTypeTimeout, "context canceled while waiting for webhook delivery", ctx.Err())
+	case <-timer.C:
+	}
+
+	// The delivery is overdue: poll slowly, but still accept a late delivery.
+	// The poller's outcome is recorded above, and not as abandoned if it loses.
+	pollCtx, cancel := context.WithCancel(ocr.WithoutWaitMetrics(ctx))
+	defer cancel()
+
+	type pollResult struct {
+		result *ocr.OCRResult
+		err    error
+	}
+	polled := make(chan pollResult, 1)
+	go func() {
+		result, err := w.sdk.WaitUntilDoneWithOptions(pollCtx, jobID, w.pollOptions)
+		polled <- pollResult{result: result, err: err}
+	}()
+
+	select {
+	case outcome := <-ch:
+		cancel()
+		return w.result(ctx, jobID, outcome)
+	case res := <-polled:
+		return res.result, res.err
+	}
+}
+
+// register returns a channel receiving the job's outcome, pre-filled if it was already delivered
+func (w *Waiter) register(jobID string) chan jobOutcome {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	ch := make(chan jobOutcome, 1)
+	if outcome, ok := w.outcomes[jobID]; ok {
+		delete(w.outcomes, jobID)
+		ch <- outcome
+		return ch
+	}
+
+	w.pending[jobID] = append(w.pending[jobID], ch)
+	return ch
+}
+
+func (w *Waiter) unregister(jobID string, ch chan jobOutcome) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	waiting := w.pending[jobID]
+	for i, c := range waiting {
+		if c == ch {
+			waiting = append(waiting[:i], waiting[i+1:]...)
+			break
+		}
+	}
+	if len(waiting) == 0 {
+		delete(w.pending, jobID)
+		return
+	}
+	w.pending[jobID] = waiting
+}
+
+// resolve hands an outcome to every waiter of the job, or keeps it for a waiter yet to come
+func (w *Waiter) resolve(jobID string, outcome jobOutcome) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	outcome.receivedAt = time.Now()
+
+	waiting := w.pending[jobID]
+	if len(waiting) == 0 {
+		w.expireOutcomes(outcome.receivedAt)
+		w.outcomes[jobID] = outcome
+		return
+	}
+
+	delete(w.pending, jobID)
+	for _, ch := range waiting {
+		ch <- outcome
+	}
+}
+
+// expireOutcomes drops outcomes older than the retention period; callers must hold w.mu
+func (w *Waiter) expireOutcomes(now time.Time) {
+	for id, outcome := range w.outcomes {
+		if now.Sub(outcome.receivedAt) > w.retention {
+			delete(w.outcomes, id)
+		}
+	}
+}
+
+func (w *Waiter) result(ctx context.Context, jobID string, outcome jobOutcome) (*ocr.OCRResult, error) {
+	if outcome.failed {
+		message := "job failed"
+		if outcome.message != "" {
+			message = "job failed: " + outcome.message
+		}
+		return nil, ocr.NewSDKError(ocr.ErrorTypeJobError, message, nil)
+	}
+	return w.sdk.GetJobResult(ctx, jobID)
+}
@@ -0,0 +1,405 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ocr "github.com/leapocr/leapocr-go"
+	"github.com/leapocr/leapocr-go/metrics"
+)
+
+const testBody = `{"event_id":"evt_1","event_type":"job.completed","created_at":"2026-01-02T03:04:05Z","payload":{"job_id":"job_1","status":"completed","total_pages":2,"credits_used":2}}`
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	signed := Sign("secret", now, []byte(testBody))
+
+	tests := []struct {
+		name      string
+		secrets   []string
+		headers   http.Header
+		body      string
+		now       time.Time
+		expectErr error
+	}{
+		{"valid", []string{"secret"}, signed, testBody, now, nil},
+		{"valid with rotated secret", []string{"new-secret", "secret"}, signed, testBody, now, nil},
+		{"wrong secret", []string{"other"}, signed, testBody, now, ErrInvalidSignature},
+		{"tampered body", []string{"secret"}, signed, testBody + " ", now, ErrInvalidSignature},
+		{"replayed delivery", []string{"secret"}, signed, testBody, now.Add(DefaultTolerance + time.Second), ErrTimestampOutOfRange},
+		{"no secret", nil, signed, testBody, now, ErrMissingSecret},
+		{"missing signature", []string{"secret"}, headers(TimestampHeader, signed.Get(TimestampHeader)), testBody, now, ErrMissingSignature},
+		{"missing timestamp", []string{"secret"}, headers(SignatureHeader, signed.Get(SignatureHeader)), testBody, now, ErrMissingTimestamp},
+		{"invalid timestamp", []string{"secret"}, headers(SignatureHeader, signed.Get(SignatureHeader), TimestampHeader, "yesterday"), testBody, now, ErrInvalidTimestamp},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &Verifier{Secrets: tt.secrets, Now: func() time.Time { return tt.now }}
+			err := v.Verify(tt.headers, []byte(tt.body))
+			if tt.expectErr == nil {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.expectErr) {
+				t.Fatalf("expected %v, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func headers(kv ...string) http.Header {
+	h := make(http.Header)
+	for i := 0; i+1 < len(kv); i += 2 {
+		h.Set(kv[i], kv[i+1])
+	}
+	return h
+}
+
+func TestParse(t *testing.T) {
+	event, err := Parse([]byte(testBody))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	completed, ok := event.(JobCompletedEvent)
+	if !ok {
+		t.Fatalf("expected JobCompletedEvent, got %T", event)
+	}
+	if completed.EventID() != "evt_1" || completed.EventType() != EventJobCompleted {
+		t.Errorf("unexpected envelope: %+v", completed.Envelope)
+	}
+	if completed.Job.JobID != "job_1" || completed.Job.TotalPages != 2 || completed.Job.CreditsUsed != 2 {
+		t.Errorf("unexpected job payload: %+v", completed.Job)
+	}
+
+	unknown, err := Parse([]byte(`{"event_id":"evt_2","event_type":"template.updated","created_at":"2026-01-02T03:04:05Z","payload":{"id":"t"}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := unknown.(UnknownEvent); !ok {
+		t.Errorf("expected UnknownEvent, got %T", unknown)
+	}
+
+	if _, err := Parse([]byte(`{"event_id":"evt_3","event_type":"job.failed","created_at":"2026-01-02T03:04:05Z","payload":{}}`)); err == nil {
+		t.Error("expected error for job event without job_id")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	h := NewHandler("secret")
+
+	var calls int
+	failNext := true
+	h.OnJobCompleted(func(_ context.Context, e JobCompletedEvent) error {
+		calls++
+		if failNext {
+			failNext = false
+			return errors.New("downstream unavailable")
+		}
+		return nil
+	})
+
+	deliver := func(method, secret, body string) int {
+		req := httptest.NewRequest(method, "/webhooks", strings.NewReader(body))
+		for k, v := range Sign(secret, time.Now(), []byte(body)) {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	steps := []struct {
+		name       string
+		method     string
+		secret     string
+		body       string
+		wantStatus int
+		wantCalls  int
+	}{
+		{"wrong method", http.MethodGet, "secret", testBody, http.StatusMethodNotAllowed, 0},
+		{"bad signature", http.MethodPost, "other", testBody, http.StatusUnauthorized, 0},
+		{"malformed payload", http.MethodPost, "secret", `{"event_id":`, http.StatusBadRequest, 0},
+		{"callback error is retried", http.MethodPost, "secret", testBody, http.StatusInternalServerError, 1},
+		{"retry succeeds", http.MethodPost, "secret", testBody, http.StatusOK, 2},
+		{"duplicate is skipped", http.MethodPost, "secret", testBody, http.StatusOK, 2},
+	}
+
+	for _, step := range steps {
+		if got := deliver(step.method, step.secret, step.body); got != step.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", step.name, step.wantStatus, got)
+		}
+		if calls != step.wantCalls {
+			t.Errorf("%s: expected %d callback calls, got %d", step.name, step.wantCalls, calls)
+		}
+	}
+}
+
+func TestHandlerPanicReleasesEvent(t *testing.T) {
+	h := NewHandler("secret")
+	panicNext := true
+	h.OnJobCompleted(func(context.Context, JobCompletedEvent) error {
+		if panicNext {
+			panicNext = false
+			panic("callback bug")
+		}
+		return nil
+	})
+	event, err := Parse([]byte(testBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	func() {
+		defer func() {
+			if p := recover(); p != "callback bug" {
+				t.Errorf("expected the callback panic to propagate, got %v", p)
+			}
+		}()
+		_ = h.Dispatch(context.Background(), event) //nolint:errcheck // panics
+	}()
+
+	// The redelivery is processed rather than rejected as in progress
+	if err := h.Dispatch(context.Background(), event); err != nil {
+		t.Errorf("expected the redelivery to succeed, got %v", err)
+	}
+}
+
+func TestBackfill(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /organizations/org_1/teams/team_1/webhooks/events", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("subscription_id") != "wh_1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.URL.Query().Get("cursor") == "" {
+			fmt.Fprint(w, `{"data":[{"id":"log_1","event_id":"evt_1","event_type":"job.completed","created_at":"2026-01-02T03:04:05Z"}],"has_more":true,"next_cursor":"c2"}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":[{"id":"log_2","event_id":"evt_2","event_type":"job.failed","created_at":"2026-01-03T03:04:05Z"}],"has_more":false}`)
+	})
+	mux.HandleFunc("GET /organizations/org_1/teams/team_1/webhooks/events/{id}/payload", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"enriched":true,"payload":{"job_id":"job_for_%s","status":"done"}}`, r.PathValue("id"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	config := ocr.DefaultConfig("test-key")
+	config.BaseURL = server.URL
+	config.OrganizationID = "org_1"
+	config.TeamID = "team_1"
+	sdk, err := ocr.NewSDK(config)
+	if err != nil {
+		t.Fatalf("failed to create SDK: %v", err)
+	}
+
+	h := NewHandler("secret")
+	var jobs []string
+	h.OnJobCompleted(func(_ context.Context, e JobCompletedEvent) error {
+		jobs = append(jobs, e.Job.JobID)
+		return nil
+	})
+	h.OnJobFailed(func(_ context.Context, e JobFailedEvent) error {
+		jobs = append(jobs, e.Job.JobID)
+		return nil
+	})
+
+	result, err := Backfill(context.Background(), sdk, h, ocr.WebhookEventFilter{SubscriptionID: "wh_1"})
+	if err != nil {
+		t.Fatalf("Backfill failed: %v", err)
+	}
+	if result.Replayed != 2 || len(jobs) != 2 || jobs[0] != "job_for_log_1" || jobs[1] != "job_for_log_2" {
+		t.Errorf("unexpected backfill: %+v, jobs %v", result, jobs)
+	}
+
+	result, err = Backfill(context.Background(), sdk, h, ocr.WebhookEventFilter{SubscriptionID: "wh_1"})
+	if err != nil {
+		t.Fatalf("second Backfill failed: %v", err)
+	}
+	if result.Replayed != 0 || result.Duplicates != 2 {
+		t.Errorf("expected already processed events to be skipped, got %+v", result)
+	}
+}
+
+// outcomeMetrics counts the wait outcomes reported to a metrics.Recorder
+type outcomeMetrics struct {
+	metrics.Nop
+	mu                           sync.Mutex
+	completed, failed, abandoned int
+}
+
+func (m *outcomeMetrics) JobCompleted(time.Duration, int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.completed++
+}
+
+func (m *outcomeMetrics) JobFailed(string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failed++
+}
+
+func (m *outcomeMetrics) WaitAbandoned(string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.abandoned++
+}
+
+func TestWaiter(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /ocr/result/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"job_id":%q,"status":"completed","credits_used":3,"pages":[{"page_number":1,"result":"hello"}]}`, r.PathValue("id"))
+	})
+	mux.HandleFunc("GET /ocr/status/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"job_id":%q,"status":"completed"}`, r.PathValue("id"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	recorder := &outcomeMetrics{}
+	config := ocr.DefaultConfig("test-key")
+	config.BaseURL = server.URL
+	config.Metrics = recorder
+	sdk, err := ocr.NewSDK(config)
+	if err != nil {
+		t.Fatalf("failed to create SDK: %v", err)
+	}
+
+	deliver := func(h http.Handler, eventID, eventType, jobID string) {
+		body := fmt.Sprintf(`{"event_id":%q,"event_type":%q,"created_at":"2026-01-02T03:04:05Z","payload":{"job_id":%q,"status":"x","error_message":"bad scan"}}`, eventID, eventType, jobID)
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		for k, v := range Sign("secret", time.Now(), []byte(body)) {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("delivery rejected with status %d", rec.Code)
+		}
+	}
+
+	t.Run("resolves on delivery", func(t *testing.T) {
+		var waiter ocr.JobWaiter = NewWaiter(sdk, "secret", WithFallbackAfter(time.Minute))
+		done := make(chan *ocr.OCRResult, 1)
+		go func() {
+			result, err := waiter.WaitUntilDone(context.Background(), "job_1")
+			if err != nil {
+				t.Errorf("WaitUntilDone failed: %v", err)
+			}
+			done <- result
+		}()
+
+		time.Sleep(20 * time.Millisecond)
+		deliver(waiter.(*Waiter), "evt_1", "job.completed", "job_1")
+
+		select {
+		case result := <-done:
+			if result == nil || result.JobID != "job_1" || result.Credits != 3 {
+				t.Errorf("unexpected result: %+v", result)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("WaitUntilDone did not return after delivery")
+		}
+	})
+
+	t.Run("delivery before wait", func(t *testing.T) {
+		waiter := NewWaiter(sdk, "secret", WithFallbackAfter(time.Minute))
+		deliver(waiter, "evt_2", "job.failed", "job_2")
+
+		_, err := waiter.WaitUntilDone(context.Background(), "job_2")
+		var sdkErr *ocr.SDKError
+		if !errors.As(err, &sdkErr) || sdkErr.Type != ocr.ErrorTypeJobError || !strings.Contains(sdkErr.Message, "bad scan") {
+			t.Errorf("expected job error, got %v", err)
+		}
+	})
+
+	t.Run("falls back to polling", func(t *testing.T) {
+		waiter := NewWaiter(sdk, "secret",
+			WithFallbackAfter(10*time.Millisecond),
+			WithFallbackWaitOptions(ocr.WaitOptions{InitialDelay: time.Millisecond, MaxJitter: time.Millisecond}),
+		)
+		result, err := waiter.WaitUntilDone(context.Background(), "job_3")
+		if err != nil {
+			t.Fatalf("WaitUntilDone failed: %v", err)
+		}
+		if result.JobID != "job_3" {
+			t.Errorf("unexpected result: %+v", result)
+		}
+	})
+
+	// Deliveries and the fallback poller report each outcome exactly once
+	if recorder.completed != 2 || recorder.failed != 1 || recorder.abandoned != 0 {
+		t.Errorf("expected 2 completions and 1 failure, got %+v", recorder)
+	}
+}
+
+func TestReceiver(t *testing.T) {
+	var forwarded []string
+	app := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body) //nolint:errcheck
+		if err := Verify("secret", r.Header, body); err != nil {
+			t.Errorf("forwarded delivery lost its signature: %v", err)
+		}
+		forwarded = append(forwarded, string(body))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer app.Close()
+
+	var record strings.Builder
+	var deliveries []Delivery
+	receiver := NewReceiver(&Verifier{Secrets: []string{"secret"}},
+		WithRecord(&record),
+		WithForwardURL(app.URL),
+		WithDeliveryCallback(func(d Delivery) { deliveries = append(deliveries, d) }),
+	)
+
+	post := func(headers http.Header) int {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(testBody))
+		for name, values := range headers {
+			req.Header[name] = values
+		}
+		rec := httptest.NewRecorder()
+		receiver.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := post(Sign("secret", time.Now(), []byte(testBody))); code != http.StatusAccepted {
+		t.Errorf("expected the status of the application, got %d", code)
+	}
+	if code := post(Sign("other", time.Now(), []byte(testBody))); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a bad signature, got %d", code)
+	}
+
+	if len(forwarded) != 1 || forwarded[0] != testBody {
+		t.Errorf("expected one forwarded delivery, got %q", forwarded)
+	}
+	if len(deliveries) != 2 || !deliveries[0].Verified() || deliveries[1].Verified() {
+		t.Fatalf("expected a verified and a rejected delivery, got %+v", deliveries)
+	}
+	if deliveries[0].EventID != "evt_1" || deliveries[0].ForwardStatus != http.StatusAccepted {
+		t.Errorf("unexpected delivery: %+v", deliveries[0])
+	}
+
+	lines := strings.Split(strings.TrimSpace(record.String()), "\n")
+	var recorded Delivery
+	if len(lines) != 1 || json.Unmarshal([]byte(lines[0]), &recorded) != nil || recorded.EventType != EventJobCompleted {
+		t.Errorf("expected one recorded delivery, got %q", record.String())
+	}
+}
@@ -0,0 +1,217 @@
+package ocr
+
+import (
+	"context"
+	"iter"
+	"time"
+
+	"github.com/leapocr/leapocr-go/internal/generated"
+)
+
+// WebhookEvent is an entry of the webhook delivery log
+type WebhookEvent struct {
+	// ID identifies the log entry; pass it to GetWebhookEventPayload
+	ID string `json:"id"`
+	// EventID identifies the event and is stable across delivery attempts
+	EventID    string         `json:"event_id"`
+	EventType  string         `json:"event_type"`
+	Status     string         `json:"status"`
+	HTTPStatus int            `json:"http_status,omitempty"`
+	Attempts   int            `json:"attempts"`
+	Error      string         `json:"error,omitempty"`
+	WebhookURL string         `json:"webhook_url"`
+	Payload    map[string]any `json:"payload,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
+
+// WebhookEventPayload is the payload of a logged event, enriched with current job data when available
+type WebhookEventPayload struct {
+	EventID   string
+	EventType string
+	Payload   map[string]any
+	Enriched  bool
+	Note      string
+}
+
+// WebhookEventFilter selects entries of the webhook delivery log
+type WebhookEventFilter struct {
+	// SubscriptionID selects the subscription whose deliveries are listed (required)
+	SubscriptionID string
+	EventType      string
+	// Status filters by processing status, e.g. "failed"
+	Status string
+	// Since and Until bound the event creation time; zero values are unbounded
+	Since time.Time
+	Until time.Time
+	// PageSize is the number of entries fetched per request (default: server default)
+	PageSize int
+}
+
+// WebhookEventList is a page of the webhook delivery log
+type WebhookEventList struct {
+	Events     []WebhookEvent
+	NextCursor string
+	HasMore    bool
+}
+
+// ListWebhookEvents returns a page of the team's webhook delivery log.
+// Since and Until are applied to the returned page on the client side; as the
+// log is listed newest first, HasMore is false once a page reaches entries
+// older than Since.
+func (s *SDK) ListWebhookEvents(ctx context.Context, filter WebhookEventFilter, cursor string) (*WebhookEventList, error) {
+	orgID, teamID, err := s.teamScope()
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.SubscriptionID == "" {
+		return nil, NewSDKError(ErrorTypeValidationError, "webhook subscription ID is required", nil)
+	}
+
+	apiRequest := s.client.WebhooksAPI.ListWebhookEventsCursor(ctx, orgID, teamID).
+		SubscriptionId(filter.SubscriptionID)
+	if filter.EventType != "" {
+		apiRequest = apiRequest.EventType(filter.EventType)
+	}
+	if filter.Status != "" {
+		apiRequest = apiRequest.ProcessingStatus(filter.Status)
+	}
+	if filter.PageSize > 0 {
+		apiRequest = apiRequest.Limit(int32(min(filter.PageSize, maxPageLimit))) // #nosec G115 - bounded above
+	}
+	if cursor != "" {
+		apiRequest = apiRequest.Cursor(cursor)
+	}
+
+	resp, httpResp, err := apiRequest.Execute()
+	if err != nil {
+		return nil, s.handleAPIError(ctx, err, httpResp, "failed to list webhook events")
+	}
+
+	list := &WebhookEventList{
+		Events: make([]WebhookEvent, 0, len(resp.Data)),
+	}
+	reachedSince := false
+	for i := range resp.Data {
+		event := convertWebhookEvent(&resp.Data[i])
+		if !filter.Since.IsZero() && event.CreatedAt.Before(filter.Since) {
+			reachedSince = true
+		}
+		if filter.matchesTime(event.CreatedAt) {
+			list.Events = append(list.Events, event)
+		}
+	}
+	if resp.NextCursor != nil && !reachedSince {
+		list.NextCursor = *resp.NextCursor
+	}
+	if resp.HasMore != nil && !reachedSince {
+		list.HasMore = *resp.HasMore
+	}
+
+	return list, nil
+}
+
+// WebhookEvents iterates over every delivery log entry matching filter, fetching pages as needed.
+// Iteration stops after the first error.
+func (s *SDK) WebhookEvents(ctx context.Context, filter WebhookEventFilter) iter.Seq2[WebhookEvent, error] {
+	return func(yield func(WebhookEvent, error) bool) {
+		cursor := ""
+		for {
+			page, err := s.ListWebhookEvents(ctx, filter, cursor)
+			if err != nil {
+				yield(WebhookEvent{}, err)
+				return
+			}
+
+			for _, event := range page.Events {
+				if !yield(event, nil) {
+					return
+				}
+			}
+
+			if !page.HasMore || page.NextCursor == "" {
+				return
+			}
+			cursor = page.NextCursor
+		}
+	}
+}
+
+// GetWebhookEventPayload returns the payload of a delivery log entry by its ID
+func (s *SDK) GetWebhookEventPayload(ctx context.Context, id string) (*WebhookEventPayload, error) {
+	orgID, teamID, err := s.teamScope()
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		return nil, NewSDKError(ErrorTypeValidationError, "webhook event ID is required", nil)
+	}
+
+	resp, httpResp, err := s.client.WebhooksAPI.GetWebhookEventPayload(ctx, orgID, teamID, id).Execute()
+	if err != nil {
+		return nil, s.handleAPIError(ctx, err, httpResp, "failed to get webhook event payload")
+	}
+
+	payload := &WebhookEventPayload{
+		Payload: resp.Payload,
+	}
+	if resp.EventId != nil {
+		payload.EventID = *resp.EventId
+	}
+	if resp.EventType != nil {
+		payload.EventType = *resp.EventType
+	}
+	if resp.Enriched != nil {
+		payload.Enriched = *resp.Enriched
+	}
+	if resp.Note != nil {
+		payload.Note = *resp.Note
+	}
+
+	return payload, nil
+}
+
+// matchesTime reports whether t falls within the filter's time bounds
+func (f WebhookEventFilter) matchesTime(t time.Time) bool {
+	if !f.Since.IsZero() && t.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && t.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+func convertWebhookEvent(resp *generated.EventsWebhookEventResponse) WebhookEvent {
+	event := WebhookEvent{
+		Payload: resp.Payload,
+	}
+	if resp.Id != nil {
+		event.ID = *resp.Id
+	}
+	if resp.EventId != nil {
+		event.EventID = *resp.EventId
+	}
+	if resp.EventType != nil {
+		event.EventType = *resp.EventType
+	}
+	if resp.ProcessingStatus != nil {
+		event.Status = *resp.ProcessingStatus
+	}
+	if resp.HttpStatus != nil {
+		event.HTTPStatus = int(*resp.HttpStatus)
+	}
+	if resp.ProcessingAttempts != nil {
+		event.Attempts = int(*resp.ProcessingAttempts)
+	}
+	if resp.ProcessingError != nil {
+		event.Error = *resp.ProcessingError
+	}
+	if resp.WebhookUrl != nil {
+		event.WebhookURL = *resp.WebhookUrl
+	}
+	event.CreatedAt = parseTimestamp(resp.CreatedAt)
+	event.UpdatedAt = parseTimestamp(resp.UpdatedAt)
+	return event
+}
@@ -0,0 +1,347 @@
+package ocr
+
+import (
+	"context"
+	"time"
+
+	"github.com/leapocr/leapocr-go/internal/generated"
+)
+
+// WebhookSubscription represents a webhook subscription of a team
+type WebhookSubscription struct {
+	ID              string    `json:"id"`
+	URL             string    `json:"url"`
+	Description     string    `json:"description,omitempty"`
+	Events          []string  `json:"events"`
+	Enabled         bool      `json:"enabled"`
+	Secret          string    `json:"secret,omitempty"`
+	FailureCount    int       `json:"failure_count"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	LastTriggeredAt time.Time `json:"last_triggered_at"`
+}
+
+// WebhookParams describes a webhook subscription to create or update
+type WebhookParams struct {
+	// URL receives the deliveries (required on create)
+	URL string
+	// Events lists the event types to subscribe to (required)
+	Events []string
+	// Description is an optional human-readable description; updates replace
+	// it, so an empty description clears it
+	Description string
+	// Enabled toggles deliveries; nil leaves the server default (create) or current value (update)
+	Enabled *bool
+}
+
+// WebhookListOptions configures ListWebhooks
+type WebhookListOptions struct {
+	Cursor string
+	Limit  int
+	// Enabled filters subscriptions by enabled state when set
+	Enabled *bool
+}
+
+// WebhookSubscriptionList is a page of webhook subscriptions
+type WebhookSubscriptionList struct {
+	Subscriptions []WebhookSubscription
+	NextCursor    string
+	HasMore       bool
+}
+
+// WebhookSecretRotation is the outcome of RotateWebhookSecret
+type WebhookSecretRotation struct {
+	Subscription   *WebhookSubscription `json:"subscription"`
+	Secret         string               `json:"secret"`
+	PreviousSecret string               `json:"previous_secret,omitempty"`
+}
+
+// Secrets returns the new and previous secrets, in that order. Receivers should
+// accept both until every delivery signed with the previous secret has arrived.
+func (r *WebhookSecretRotation) Secrets() []string {
+	if r.PreviousSecret == "" {
+		return []string{r.Secret}
+	}
+	return []string{r.Secret, r.PreviousSecret}
+}
+
+// GetWebhookEventTypes returns the event types that can be subscribed to
+func (s *SDK) GetWebhookEventTypes(ctx context.Context) ([]string, error) {
+	resp, httpResp, err := s.client.WebhooksAPI.GetWebhookEventTypes(ctx).Execute()
+	if err != nil {
+		return nil, s.handleAPIError(ctx, err, httpResp, "failed to get webhook event types")
+	}
+	return resp.EventTypes, nil
+}
+
+// CreateWebhook creates a webhook subscription. The returned subscription
+// contains the secret used to sign deliveries.
+func (s *SDK) CreateWebhook(ctx context.Context, params WebhookParams) (*WebhookSubscription, error) {
+	orgID, teamID, err := s.teamScope()
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateWebhookParams(params, true); err != nil {
+		return nil, NewSDKError(ErrorTypeValidationError, "invalid webhook parameters", err)
+	}
+
+	payload := generated.SubscriptionsCreateWebhookSubscriptionRequest{
+		Url:     params.URL,
+		Events:  params.Events,
+		Enabled: params.Enabled,
+	}
+	if params.Description != "" {
+		payload.Description = &params.Description
+	}
+
+	resp, httpResp, err := s.client.WebhooksAPI.CreateWebhook(ctx, orgID, teamID).
+		CreateWebhookRequest(generated.SubscriptionsCreateWebhookSubscriptionRequestAsCreateWebhookRequest(&payload)).
+		Execute()
+	if err != nil {
+		return nil, s.handleAPIError(ctx, err, httpResp, "failed to create webhook")
+	}
+
+	return convertWebhookSubscription(resp), nil
+}
+
+// GetWebhook returns a webhook subscription by ID
+func (s *SDK) GetWebhook(ctx context.Context, webhookID string) (*WebhookSubscription, error) {
+	orgID, teamID, err := s.teamScope()
+	if err != nil {
+		return nil, err
+	}
+	if webhookID == "" {
+		return nil, NewSDKError(ErrorTypeValidationError, "webhook ID is required", nil)
+	}
+
+	resp, httpResp, err := s.client.WebhooksAPI.GetWebhook(ctx, orgID, teamID, webhookID).Execute()
+	if err != nil {
+		return nil, s.handleAPIError(ctx, err, httpResp, "failed to get webhook")
+	}
+
+	return convertWebhookSubscription(resp), nil
+}
+
+// UpdateWebhook updates a webhook subscription. The events and description
+// are replaced; an empty URL keeps the current one.
+func (s *SDK) UpdateWebhook(ctx context.Context, webhookID string, params WebhookParams) (*WebhookSubscription, error) {
+	orgID, teamID, err := s.teamScope()
+	if err != nil {
+		return nil, err
+	}
+	if webhookID == "" {
+		return nil, NewSDKError(ErrorTypeValidationError, "webhook ID is required", nil)
+	}
+	if err := ValidateWebhookParams(params, false); err != nil {
+		return nil, NewSDKError(ErrorTypeValidationError, "invalid webhook parameters", err)
+	}
+
+	payload := generated.SubscriptionsUpdateWebhookSubscriptionRequest{
+		Events:      params.Events,
+		Description: &params.Description,
+		Enabled:     params.Enabled,
+	}
+	if params.URL != "" {
+		payload.Url = &params.URL
+	}
+
+	resp, httpResp, err := s.client.WebhooksAPI.UpdateWebhook(ctx, orgID, teamID, webhookID).
+		UpdateWebhookRequest(generated.SubscriptionsUpdateWebhookSubscriptionRequestAsUpdateWebhookRequest(&payload)).
+		Execute()
+	if err != nil {
+		return nil, s.handleAPIError(ctx, err, httpResp, "failed to update webhook")
+	}
+
+	return convertWebhookSubscription(resp), nil
+}
+
+// DeleteWebhook permanently deletes a webhook subscription, stopping all deliveries
+func (s *SDK) DeleteWebhook(ctx context.Context, webhookID string) error {
+	orgID, teamID, err := s.teamScope()
+	if err != nil {
+		return err
+	}
+	if webhookID == "" {
+		return NewSDKError(ErrorTypeValidationError, "webhook ID is required", nil)
+	}
+
+	httpResp, err := s.client.WebhooksAPI.DeleteWebhook(ctx, orgID, teamID, webhookID).Execute()
+	if err != nil {
+		return s.handleAPIError(ctx, err, httpResp, "failed to delete webhook")
+	}
+
+	return nil
+}
+
+// ListWebhooks returns a page of the team's webhook subscriptions
+func (s *SDK) ListWebhooks(ctx context.Context, opts WebhookListOptions) (*WebhookSubscriptionList, error) {
+	orgID, teamID, err := s.teamScope()
+	if err != nil {
+		return nil, err
+	}
+
+	apiRequest := s.client.WebhooksAPI.ListWebhooksCursor(ctx, orgID, teamID)
+	if opts.Cursor != "" {
+		apiRequest = apiRequest.Cursor(opts.Cursor)
+	}
+	if opts.Limit > 0 {
+		apiRequest = apiRequest.Limit(int32(min(opts.Limit, maxPageLimit))) // #nosec G115 - bounded above
+	}
+	if opts.Enabled != nil {
+		apiRequest = apiRequest.Enabled(*opts.Enabled)
+	}
+
+	resp, httpResp, err := apiRequest.Execute()
+	if err != nil {
+		return nil, s.handleAPIError(ctx, err, httpResp, "failed to list webhooks")
+	}
+
+	list := &WebhookSubscriptionList{
+		Subscriptions: make([]WebhookSubscription, 0, len(resp.Data)),
+	}
+	for i := range resp.Data {
+		list.Subscriptions = append(list.Subscriptions, *convertWebhookSubscription(&resp.Data[i]))
+	}
+	if resp.NextCursor != nil {
+		list.NextCursor = *resp.NextCursor
+	}
+	if resp.HasMore != nil {
+		list.HasMore = *resp.HasMore
+	}
+
+	return list, nil
+}
+
+// TestWebhook asks the server to send a test delivery to the subscription
+func (s *SDK) TestWebhook(ctx context.Context, webhookID string) (map[string]any, error) {
+	orgID, teamID, err := s.teamScope()
+	if err != nil {
+		return nil, err
+	}
+	if webhookID == "" {
+		return nil, NewSDKError(ErrorTypeValidationError, "webhook ID is required", nil)
+	}
+
+	resp, httpResp, err := s.client.WebhooksAPI.TestWebhook(ctx, orgID, teamID, webhookID).Execute()
+	if err != nil {
+		return nil, s.handleAPIError(ctx, err, httpResp, "failed to test webhook")
+	}
+
+	return resp, nil
+}
+
+// CheckWebhookHealth returns the server's health report for the subscription endpoint
+func (s *SDK) CheckWebhookHealth(ctx context.Context, webhookID string) (map[string]any, error) {
+	orgID, teamID, err := s.teamScope()
+	if err != nil {
+		return nil, err
+	}
+	if webhookID == "" {
+		return nil, NewSDKError(ErrorTypeValidationError, "webhook ID is required", nil)
+	}
+
+	resp, httpResp, err := s.client.WebhooksAPI.HealthCheckWebhook(ctx, orgID, teamID, webhookID).Execute()
+	if err != nil {
+		return nil, s.handleAPIError(ctx, err, httpResp, "failed to check webhook health")
+	}
+
+	return resp, nil
+}
+
+// RotateWebhookSecret generates a new signing secret for the subscription.
+// The previous secret is returned alongside the new one so that receivers can
+// accept deliveries signed with either during a grace window.
+func (s *SDK) RotateWebhookSecret(ctx context.Context, webhookID string) (*WebhookSecretRotation, error) {
+	current, err := s.GetWebhook(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	orgID, teamID, err := s.teamScope()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, httpResp, err := s.client.WebhooksAPI.RegenerateWebhookSecret(ctx, orgID, teamID, webhookID).Execute()
+	if err != nil {
+		return nil, s.handleAPIError(ctx, err, httpResp, "failed to regenerate webhook secret")
+	}
+
+	subscription := convertWebhookSubscription(resp)
+	return &WebhookSecretRotation{
+		Subscription:   subscription,
+		Secret:         subscription.Secret,
+		PreviousSecret: current.Secret,
+	}, nil
+}
+
+// maxPageLimit caps page sizes requested from cursor-paginated endpoints
+const maxPageLimit = 100
+
+// teamScope returns the organization and team configured for team-level operations
+func (s *SDK) teamScope() (string, string, error) {
+	if s.config.OrganizationID == "" || s.config.TeamID == "" {
+		return "", "", NewSDKError(ErrorTypeInvalidConfig, "OrganizationID and TeamID are required for team operations", nil)
+	}
+	return s.config.OrganizationID, s.config.TeamID, nil
+}
+
+// ValidateWebhookParams checks webhook parameters like CreateWebhook (create)
+// and UpdateWebhook do
+func ValidateWebhookParams(params WebhookParams, create bool) error {
+	if create && params.URL == "" {
+		return NewValidationError("url", "webhook URL cannot be empty")
+	}
+	if params.URL != "" {
+		if err := ValidateWebhookURL(params.URL); err != nil {
+			return err
+		}
+	}
+	if len(params.Events) == 0 {
+		return NewValidationError("events", "at least one event type is required")
+	}
+	if len(params.Description) > 500 {
+		return NewValidationError("description", "description too long. Maximum allowed is 500 characters")
+	}
+	return nil
+}
+
+func convertWebhookSubscription(resp *generated.SubscriptionsWebhookSubscriptionResponse) *WebhookSubscription {
+	subscription := &WebhookSubscription{
+		Events: resp.Events,
+	}
+	if resp.Id != nil {
+		subscription.ID = *resp.Id
+	}
+	if resp.Url != nil {
+		subscription.URL = *resp.Url
+	}
+	if resp.Description != nil {
+		subscription.Description = *resp.Description
+	}
+	if resp.Enabled != nil {
+		subscription.Enabled = *resp.Enabled
+	}
+	if resp.Secret != nil {
+		subscription.Secret = *resp.Secret
+	}
+	if resp.FailureCount != nil {
+		subscription.FailureCount = int(*resp.FailureCount)
+	}
+	subscription.CreatedAt = parseTimestamp(resp.CreatedAt)
+	subscription.UpdatedAt = parseTimestamp(resp.UpdatedAt)
+	subscription.LastTriggeredAt = parseTimestamp(resp.LastTriggeredAt)
+	return subscription
+}
+
+// parseTimestamp parses an optional RFC 3339 timestamp, returning the zero time when absent or malformed
+func parseTimestamp(value *string) time.Time {
+	if value == nil || *value == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, *value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
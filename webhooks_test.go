@@ -0,0 +1,185 @@
+package ocr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func newTestTeamSDK(t *testing.T, handler http.Handler) *SDK {
+	t.Helper()
+	return newTestTeamSDKWithConfig(t, handler, nil)
+}
+
+// newTestTeamSDKWithConfig is newTestTeamSDK with a hook adjusting the config before the SDK is created
+func newTestTeamSDKWithConfig(t *testing.T, handler http.Handler, configure func(*Config)) *SDK {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	config := DefaultConfig("test-key")
+	config.BaseURL = server.URL
+	config.OrganizationID = "org_1"
+	config.TeamID = "team_1"
+	if configure != nil {
+		configure(config)
+	}
+
+	sdk, err := NewSDK(config)
+	if err != nil {
+		t.Fatalf("failed to create SDK: %v", err)
+	}
+	return sdk
+}
+
+func TestWebhookSubscriptionLifecycle(t *testing.T) {
+	secret := "whsec_old"
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /organizations/org_1/teams/team_1/webhooks", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-KEY") != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id": "wh_1", "url": body["url"], "events": body["events"], "enabled": true, "secret": secret,
+			"created_at": "2026-01-02T03:04:05Z",
+		})
+	})
+	mux.HandleFunc("GET /organizations/org_1/teams/team_1/webhooks/wh_1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "wh_1", "secret": secret})
+	})
+	mux.HandleFunc("POST /organizations/org_1/teams/team_1/webhooks/wh_1/regenerate-secret", func(w http.ResponseWriter, r *http.Request) {
+		secret = "whsec_new"
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "wh_1", "secret": secret})
+	})
+
+	sdk := newTestTeamSDK(t, mux)
+	ctx := context.Background()
+
+	created, err := sdk.CreateWebhook(ctx, WebhookParams{
+		URL:    "https://example.com/hooks",
+		Events: []string{"job.completed"},
+	})
+	if err != nil {
+		t.Fatalf("CreateWebhook failed: %v", err)
+	}
+	if created.ID != "wh_1" || created.Secret != "whsec_old" || !created.Enabled || created.CreatedAt.IsZero() {
+		t.Errorf("unexpected subscription: %+v", created)
+	}
+
+	rotation, err := sdk.RotateWebhookSecret(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("RotateWebhookSecret failed: %v", err)
+	}
+	secrets := rotation.Secrets()
+	if len(secrets) != 2 || secrets[0] != "whsec_new" || secrets[1] != "whsec_old" {
+		t.Errorf("expected new and previous secrets, got %v", secrets)
+	}
+}
+
+func TestUpdateWebhookClearsDescription(t *testing.T) {
+	var body map[string]any
+	sdk := newTestTeamSDK(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/organizations/org_1/teams/team_1/webhooks/wh_1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body) //nolint:errcheck
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "wh_1", "events": body["events"]}) //nolint:errcheck
+	}))
+
+	if _, err := sdk.UpdateWebhook(context.Background(), "wh_1", WebhookParams{Events: []string{"job.completed"}}); err != nil {
+		t.Fatalf("UpdateWebhook failed: %v", err)
+	}
+	if description, ok := body["description"]; !ok || description != "" {
+		t.Errorf("expected an empty description to be sent, got %v", body)
+	}
+	if _, ok := body["url"]; ok {
+		t.Errorf("expected an empty URL to be left out, got %v", body)
+	}
+}
+
+func TestWebhookValidation(t *testing.T) {
+	sdk := &SDK{config: &Config{APIKey: "test-key"}}
+
+	_, err := sdk.CreateWebhook(context.Background(), WebhookParams{URL: "https://example.com", Events: []string{"job.completed"}})
+	if sdkErr, ok := err.(*SDKError); !ok || sdkErr.Type != ErrorTypeInvalidConfig {
+		t.Errorf("expected invalid config error without team scope, got %v", err)
+	}
+
+	sdk.config.OrganizationID = "org_1"
+	sdk.config.TeamID = "team_1"
+
+	tests := []struct {
+		name   string
+		params WebhookParams
+	}{
+		{"missing URL", WebhookParams{Events: []string{"job.completed"}}},
+		{"invalid scheme", WebhookParams{URL: "ftp://example.com", Events: []string{"job.completed"}}},
+		{"no events", WebhookParams{URL: "https://example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := sdk.CreateWebhook(context.Background(), tt.params)
+			sdkErr, ok := err.(*SDKError)
+			if !ok || !sdkErr.IsValidationError() {
+				t.Errorf("expected validation error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestWebhookEventsStopAtSince(t *testing.T) {
+	since := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	// The log is listed newest first, one entry per page
+	log := []time.Time{since.Add(2 * time.Hour), since.Add(time.Hour), since.Add(-time.Hour), since.Add(-2 * time.Hour)}
+
+	var requests int
+	sdk := newTestTeamSDK(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		i := 0
+		if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+			i, _ = strconv.Atoi(cursor) //nolint:errcheck
+		}
+		page := map[string]any{
+			"data":     []map[string]any{{"id": fmt.Sprintf("evt_%d", i), "created_at": log[i].Format(time.RFC3339)}},
+			"has_more": i+1 < len(log),
+		}
+		if i+1 < len(log) {
+			page["next_cursor"] = strconv.Itoa(i + 1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(page) //nolint:errcheck
+	}))
+
+	var ids []string
+	for event, err := range sdk.WebhookEvents(context.Background(), WebhookEventFilter{SubscriptionID: "wh_1", Since: since}) {
+		if err != nil {
+			t.Fatalf("WebhookEvents failed: %v", err)
+		}
+		ids = append(ids, event.ID)
+	}
+
+	if !equalStrings(ids, []string{"evt_0", "evt_1"}) {
+		t.Errorf("expected the entries after since, got %v", ids)
+	}
+	if requests != 3 {
+		t.Errorf("expected paging to stop at the first page older than since, got %d requests", requests)
+	}
+}